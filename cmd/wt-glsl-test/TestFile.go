@@ -0,0 +1,348 @@
+package main
+
+import (
+  "strconv"
+
+	"github.com/wtsuite/wtsuite/pkg/directives"
+	"github.com/wtsuite/wtsuite/pkg/files"
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+const (
+  DEFAULT_TOLERANCE = 1e-6
+)
+
+type TestCase struct {
+  function *tokens.String
+  args []tokens.Token
+  expect tokens.Token
+  tolerance float64
+  ctx context.Context
+}
+
+// test files are template files exporting main, eg.:
+//  export const main = {
+//    module: "./noise.tglsl",
+//    target: "fragment",
+//    tolerance: 1e-5,
+//    tests: [
+//      {function: "hash", args: [[0.0, 1.0]], expect: 0.25},
+//      {function: "transpose2", args: [[[1.0, 2.0], [3.0, 4.0]]], expect: [[1.0, 3.0], [2.0, 4.0]]}, // matrices are lists of columns
+//    ],
+//  }
+type TestFile struct {
+  module string // absolute path
+  target string
+  tests []TestCase
+}
+
+func readTolerance(t tokens.Token) (float64, error) {
+  tol, err := tokens.AssertIntOrFloat(t)
+  if err != nil {
+    return 0.0, err
+  }
+
+  if tol.Value() < 0.0 {
+    errCtx := t.Context()
+    return 0.0, errCtx.NewError("Error: tolerance can't be negative")
+  }
+
+  return tol.Value(), nil
+}
+
+func readTestCase(t_ tokens.Token, tolerance float64) (TestCase, error) {
+  t, err := tokens.AssertStringDict(t_)
+  if err != nil {
+    return TestCase{}, err
+  }
+
+  if err := t.AssertOnlyValidKeys([]string{"function", "args", "expect", "tolerance"}); err != nil {
+    return TestCase{}, err
+  }
+
+  fn_, ok := t.Get("function")
+  if !ok {
+    errCtx := t.Context()
+    return TestCase{}, errCtx.NewError("Error: function not found in test")
+  }
+
+  fn, err := tokens.AssertString(fn_)
+  if err != nil {
+    return TestCase{}, err
+  }
+
+  args := make([]tokens.Token, 0)
+  if args_, ok := t.Get("args"); ok {
+    argsList, err := tokens.AssertList(args_)
+    if err != nil {
+      return TestCase{}, err
+    }
+
+    args = argsList.GetTokens()
+  }
+
+  expect, ok := t.Get("expect")
+  if !ok {
+    errCtx := t.Context()
+    return TestCase{}, errCtx.NewError("Error: expect not found in test")
+  }
+
+  if tol_, ok := t.Get("tolerance"); ok {
+    tolerance, err = readTolerance(tol_)
+    if err != nil {
+      return TestCase{}, err
+    }
+  }
+
+  return TestCase{fn, args, expect, tolerance, t.Context()}, nil
+}
+
+func ReadTestFile(fname string) (*TestFile, error) {
+  if err := files.ResolvePackages(fname); err != nil {
+    return nil, err
+  }
+
+  t_, err := directives.BuildJSON(fname, context.NewDummyContext())
+  if err != nil {
+    return nil, err
+  }
+
+  t, err := tokens.AssertStringDict(t_)
+  if err != nil {
+    return nil, err
+  }
+
+  if err := t.AssertOnlyValidKeys([]string{"module", "target", "tolerance", "tests"}); err != nil {
+    return nil, err
+  }
+
+  tf := &TestFile{
+    module: "",
+    target: "fragment",
+    tests: make([]TestCase, 0),
+  }
+
+  module_, ok := t.Get("module")
+  if !ok {
+    errCtx := t.Context()
+    return nil, errCtx.NewError("Error: module not found in dict")
+  }
+
+  module, err := tokens.AssertString(module_)
+  if err != nil {
+    return nil, err
+  }
+
+  tf.module, err = files.Search(fname, module.Value())
+  if err != nil {
+    errCtx := module.Context()
+    return nil, errCtx.NewError("Error: shader file \"" + module.Value() + "\" not found")
+  }
+
+  if target_, ok := t.Get("target"); ok {
+    target, err := tokens.AssertString(target_)
+    if err != nil {
+      return nil, err
+    }
+
+    switch target.Value() {
+    case "vertex", "fragment":
+      tf.target = target.Value()
+    default:
+      errCtx := target.Context()
+      return nil, errCtx.NewError("Error: expected \"vertex\" or \"fragment\"")
+    }
+  }
+
+  tolerance := DEFAULT_TOLERANCE
+  if tol_, ok := t.Get("tolerance"); ok {
+    tolerance, err = readTolerance(tol_)
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  tests_, ok := t.Get("tests")
+  if !ok {
+    errCtx := t.Context()
+    return nil, errCtx.NewError("Error: tests not found in dict")
+  }
+
+  tests, err := tokens.AssertList(tests_)
+  if err != nil {
+    return nil, err
+  }
+
+  if err := tests.Loop(func(i int, test_ tokens.Token, last bool) error {
+    test, err := readTestCase(test_, tolerance)
+    if err != nil {
+      return err
+    }
+
+    tf.tests = append(tf.tests, test)
+
+    return nil
+  }); err != nil {
+    return nil, err
+  }
+
+  return tf, nil
+}
+
+// the template determines the glsl type of the result
+func tokenToData(t tokens.Token, template data.Data) (data.Data, error) {
+  switch tmpl := template.(type) {
+  case *data.Simple:
+    if tmpl.IsScalar() {
+      x, err := tokenToComp(t, tmpl.CompType())
+      if err != nil {
+        return nil, err
+      }
+
+      return data.NewScalar(tmpl.CompType(), x), nil
+    }
+
+    lst, err := assertListLength(t, tmpl.Length())
+    if err != nil {
+      return nil, err
+    }
+
+    comps := make([]float64, tmpl.Length())
+    for i, item := range lst.GetTokens() {
+      comps[i], err = tokenToComp(item, tmpl.CompType())
+      if err != nil {
+        return nil, err
+      }
+    }
+
+    return data.NewVec(tmpl.CompType(), comps), nil
+  case *data.Mat:
+    // list of columns
+    lst, err := assertListLength(t, tmpl.Length())
+    if err != nil {
+      return nil, err
+    }
+
+    n := tmpl.Length()
+    comps := make([]float64, 0, n*n)
+    for _, col_ := range lst.GetTokens() {
+      col, err := assertListLength(col_, n)
+      if err != nil {
+        return nil, err
+      }
+
+      for _, item := range col.GetTokens() {
+        x, err := tokenToComp(item, "float")
+        if err != nil {
+          return nil, err
+        }
+
+        comps = append(comps, x)
+      }
+    }
+
+    return data.NewMat(n, comps), nil
+  case *data.Array:
+    lst, err := assertListLength(t, tmpl.Length())
+    if err != nil {
+      return nil, err
+    }
+
+    items := make([]data.Data, tmpl.Length())
+    for i, item := range lst.GetTokens() {
+      itemTmpl, err := tmpl.GetIndex(i, t.Context())
+      if err != nil {
+        return nil, err
+      }
+
+      items[i], err = tokenToData(item, itemTmpl)
+      if err != nil {
+        return nil, err
+      }
+    }
+
+    return data.NewArray(items), nil
+  case *data.Struct:
+    dict, err := tokens.AssertStringDict(t)
+    if err != nil {
+      return nil, err
+    }
+
+    if err := dict.AssertOnlyValidKeys(tmpl.Keys()); err != nil {
+      return nil, err
+    }
+
+    res := tmpl.Copy().(*data.Struct)
+    for _, key := range tmpl.Keys() {
+      member_, ok := dict.Get(key)
+      if !ok {
+        errCtx := t.Context()
+        return nil, errCtx.NewError("Error: member " + key + " not found")
+      }
+
+      memberTmpl, err := tmpl.GetMember(key, t.Context())
+      if err != nil {
+        return nil, err
+      }
+
+      member, err := tokenToData(member_, memberTmpl)
+      if err != nil {
+        return nil, err
+      }
+
+      if err := res.SetMember(key, member, member_.Context()); err != nil {
+        return nil, err
+      }
+    }
+
+    return res, nil
+  default:
+    panic("unhandled data type")
+  }
+}
+
+func tokenToComp(t tokens.Token, compType string) (float64, error) {
+  switch compType {
+  case "bool":
+    b, err := tokens.AssertBool(t)
+    if err != nil {
+      return 0.0, err
+    }
+
+    if b.Value() {
+      return 1.0, nil
+    } else {
+      return 0.0, nil
+    }
+  case "int":
+    i, err := tokens.AssertInt(t)
+    if err != nil {
+      return 0.0, err
+    }
+
+    return float64(i.Value()), nil
+  default:
+    x, err := tokens.AssertIntOrFloat(t)
+    if err != nil {
+      return 0.0, err
+    }
+
+    return x.Value(), nil
+  }
+}
+
+func assertListLength(t tokens.Token, n int) (*tokens.List, error) {
+  lst, err := tokens.AssertList(t)
+  if err != nil {
+    return nil, err
+  }
+
+  if lst.Len() != n {
+    errCtx := t.Context()
+    return nil, errCtx.NewError("Error: expected " + strconv.Itoa(n) + " items, got " + strconv.Itoa(lst.Len()))
+  }
+
+  return lst, nil
+}
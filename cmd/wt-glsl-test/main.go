@@ -0,0 +1,202 @@
+package main
+
+import (
+  "fmt"
+  "os"
+
+	"github.com/wtsuite/wtsuite/pkg/files"
+	"github.com/wtsuite/wtsuite/pkg/git"
+	"github.com/wtsuite/wtsuite/pkg/parsers"
+	"github.com/wtsuite/wtsuite/pkg/tree/shaders"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
+)
+
+var (
+  VERSION string
+  VERBOSITY = 0
+  cmdParser *parsers.CLIParser = nil
+)
+
+type CmdArgs struct {
+  inputFile string // template file exporting the test cases
+  autoDownload bool
+
+  verbosity int
+}
+
+func printMessageAndExit(msg string) {
+	fmt.Fprintf(os.Stderr, "\u001b[1m"+msg+"\u001b[0m\n\n")
+  os.Exit(1)
+}
+
+func printSyntaxErrorAndExit(err error) {
+	os.Stderr.WriteString(err.Error())
+	os.Exit(1)
+}
+
+func parseArgs() CmdArgs {
+	cmdArgs := CmdArgs{
+		inputFile:     "",
+    autoDownload:  false,
+		verbosity:     0,
+	}
+
+  cmdParser = parsers.NewCLIParser(
+    fmt.Sprintf("Usage: %s <test-file> [options]", os.Args[0]),
+    "Runs exported shader functions on the cpu, and compares the results with the expected values",
+    []parsers.CLIOption{
+      parsers.NewCLIVersion("", "version",   "--version    Show version", VERSION),
+      parsers.NewCLIUniqueFlag("", "auto-download"         , "--auto-download                   Automatically download missing packages (use wt-pkg-sync if you want to do this manually). Doesn't update packages!", &(cmdArgs.autoDownload)),
+      parsers.NewCLICountFlag("v", ""        , "-v[v[v..]]      Verbosity", &(cmdArgs.verbosity)),
+      parsers.NewCLIUniqueFlag("l", "latest" , "-l, --latest    Ignore max semver, use latest tagged versions of dependencies", &(files.LATEST)),
+    },
+    parsers.NewCLIFile("", "", "", true, &(cmdArgs.inputFile)),
+  )
+
+  if err := cmdParser.Parse(os.Args[1:]); err != nil {
+    printMessageAndExit(err.Error())
+  }
+
+	return cmdArgs
+}
+
+func setUpEnv(cmdArgs CmdArgs) error {
+  if cmdArgs.autoDownload {
+    git.RegisterFetchPublicOrPrivate()
+  }
+
+	VERBOSITY = cmdArgs.verbosity
+	files.VERBOSITY = cmdArgs.verbosity
+	shaders.VERBOSITY = cmdArgs.verbosity
+
+  return nil
+}
+
+func prepareBundle(tf *TestFile) (*shaders.ShaderBundle, shaders.ShaderFile, error) {
+  glsl.TARGET = tf.target
+
+  if err := files.ResolvePackages(tf.module); err != nil {
+    return nil, nil, err
+  }
+
+  entryShader, err := shaders.NewShaderFile(tf.module)
+  if err != nil {
+    return nil, nil, err
+  }
+
+  bundle := shaders.NewShaderBundle()
+
+  bundle.Append(entryShader)
+
+  if err := bundle.FinalizeForInterpreter(); err != nil {
+    return nil, nil, err
+  }
+
+  return bundle, entryShader, nil
+}
+
+func runTestCase(bundle *shaders.ShaderBundle, entryShader shaders.ShaderFile, test TestCase) error {
+  fn := entryShader.FindExportedFunction(test.function.Value())
+  if fn == nil {
+    errCtx := test.function.Context()
+    return errCtx.NewError("Error: exported function \"" + test.function.Value() + "\" not found in shader")
+  }
+
+  argTypes := fn.ArgTypes()
+  if len(argTypes) != len(test.args) {
+    errCtx := test.ctx
+    return errCtx.NewError(fmt.Sprintf("Error: expected %d args, got %d", len(argTypes), len(test.args)))
+  }
+
+  args := make([]data.Data, len(argTypes))
+  for i, argType := range argTypes {
+    tmpl, err := glsl.NewZeroData(argType, test.args[i].Context())
+    if err != nil {
+      return err
+    }
+
+    args[i], err = tokenToData(test.args[i], tmpl)
+    if err != nil {
+      return err
+    }
+  }
+
+  retType, err := fn.ReturnType()
+  if err != nil {
+    return err
+  } else if retType == nil {
+    errCtx := test.function.Context()
+    return errCtx.NewError("Error: function doesn't return a value")
+  }
+
+  retTmpl, err := glsl.NewZeroData(retType, test.expect.Context())
+  if err != nil {
+    return err
+  }
+
+  expect, err := tokenToData(test.expect, retTmpl)
+  if err != nil {
+    return err
+  }
+
+  // each test starts from freshly initialized globals
+  stack, err := bundle.NewStack()
+  if err != nil {
+    return err
+  }
+
+  res, err := fn.InterpretCall(stack, args, test.ctx)
+  if err != nil {
+    return err
+  }
+
+  if !res.ApproxEqual(expect, test.tolerance) {
+    errCtx := test.ctx
+    return errCtx.NewError("Error: expected " + expect.Write() + ", got " + res.Write())
+  }
+
+  return nil
+}
+
+func runTests(cmdArgs CmdArgs) error {
+  tf, err := ReadTestFile(cmdArgs.inputFile)
+  if err != nil {
+    return err
+  }
+
+  bundle, entryShader, err := prepareBundle(tf)
+  if err != nil {
+    return err
+  }
+
+  nFailed := 0
+  for _, test := range tf.tests {
+    if err := runTestCase(bundle, entryShader, test); err != nil {
+      os.Stderr.WriteString(err.Error())
+      nFailed += 1
+    } else if VERBOSITY >= 1 {
+      fmt.Printf("PASS %s\n", test.function.Value())
+    }
+  }
+
+  fmt.Printf("%d passed, %d failed\n", len(tf.tests) - nFailed, nFailed)
+
+  if nFailed > 0 {
+    os.Exit(1)
+  }
+
+  return nil
+}
+
+func main() {
+  cmdArgs := parseArgs()
+
+  if err := setUpEnv(cmdArgs); err != nil {
+    printSyntaxErrorAndExit(err)
+  }
+
+  if err := runTests(cmdArgs); err != nil {
+    printSyntaxErrorAndExit(err)
+  }
+}
@@ -29,6 +29,7 @@ type CmdArgs struct {
   outputFile string // defaults to a.shader in current dir
 
   target string
+  linkFile string // fragment shader to link-check against, no output is written
   compactOutput bool
   autoDownload bool
 
@@ -51,6 +52,7 @@ func parseArgs() CmdArgs {
 		inputFile:     "",
 		outputFile:    DEFAULT_OUTPUTFILE,
     target:        "vertex",
+    linkFile:      "",
 		compactOutput: false,
     autoDownload:  false,
 		verbosity:     0,
@@ -64,6 +66,7 @@ func parseArgs() CmdArgs {
       parsers.NewCLIUniqueFile("o", "output" , "-o, --output    <output-file> Defaults to \"" + DEFAULT_OUTPUTFILE + "\" if not set", false, &(cmdArgs.outputFile)),
       parsers.NewCLIUniqueFlag("c", "compact", "-c, --compact   Compact output with minimal whitespace and short names", &(cmdArgs.compactOutput)),
       parsers.NewCLIUniqueFlag("", "auto-download"         , "--auto-download                   Automatically download missing packages (use wt-pkg-sync if you want to do this manually). Doesn't update packages!", &(cmdArgs.autoDownload)), 
+      parsers.NewCLIUniqueFile("L", "link"   , "-L, --link      <fragment-file> Link-check the input vertex shader against a fragment shader, no output is written", true, &(cmdArgs.linkFile)),
       parsers.NewCLIUniqueEnum("t", "target" , "-t, --target    \"vertex\" or \"fragment\", defaults to \"vertex\"", []string{"vertex", "fragment"}, &(cmdArgs.target)),
       parsers.NewCLICountFlag("v", ""        , "-v[v[v..]]      Verbosity", &(cmdArgs.verbosity)),
       parsers.NewCLIUniqueFlag("l", "latest" , "-l, --latest    Ignore max semver, use latest tagged versions of dependencies", &(files.LATEST)),
@@ -97,14 +100,23 @@ func setUpEnv(cmdArgs CmdArgs) error {
 	files.VERBOSITY = cmdArgs.verbosity
 	shaders.VERBOSITY = cmdArgs.verbosity
 
-  return files.ResolvePackages(cmdArgs.inputFile)
+  if err := files.ResolvePackages(cmdArgs.inputFile); err != nil {
+    return err
+  }
+
+  if cmdArgs.linkFile != "" {
+    return files.ResolvePackages(cmdArgs.linkFile)
+  }
+
+  return nil
 }
 
-func buildShader(cmdArgs CmdArgs) error {
-  // dont bother caching, because shaders are expected to be relatively small
-  entryShader, err := shaders.NewInitShaderFile(cmdArgs.inputFile)
+func finalizeShader(path string, target string) (*shaders.ShaderBundle, error) {
+  glsl.TARGET = target
+
+  entryShader, err := shaders.NewInitShaderFile(path)
   if err != nil {
-    return err
+    return nil, err
   }
 
   bundle := shaders.NewShaderBundle()
@@ -112,6 +124,30 @@ func buildShader(cmdArgs CmdArgs) error {
   bundle.Append(entryShader)
 
   if err := bundle.Finalize(); err != nil {
+    return nil, err
+  }
+
+  return bundle, nil
+}
+
+func linkShaders(cmdArgs CmdArgs) error {
+  vertexBundle, err := finalizeShader(cmdArgs.inputFile, "vertex")
+  if err != nil {
+    return err
+  }
+
+  fragmentBundle, err := finalizeShader(cmdArgs.linkFile, "fragment")
+  if err != nil {
+    return err
+  }
+
+  return shaders.CheckLink(vertexBundle, fragmentBundle)
+}
+
+func buildShader(cmdArgs CmdArgs) error {
+  // dont bother caching, because shaders are expected to be relatively small
+  bundle, err := finalizeShader(cmdArgs.inputFile, glsl.TARGET)
+  if err != nil {
     return err
   }
 
@@ -134,7 +170,11 @@ func main() {
     printSyntaxErrorAndExit(err)
   }
 
-  if err := buildShader(cmdArgs); err != nil {
+  if cmdArgs.linkFile != "" {
+    if err := linkShaders(cmdArgs); err != nil {
+      printSyntaxErrorAndExit(err)
+    }
+  } else if err := buildShader(cmdArgs); err != nil {
     printSyntaxErrorAndExit(err)
   }
 }
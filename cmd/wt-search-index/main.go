@@ -192,29 +192,6 @@ func extractTagText(tags []tree.Tag) []string {
   return str
 }
 
-func extractHeadTitle(root *tree.Root) string {
-  for _, rootChild := range root.Children() {
-    if rootChild.Name() == "html" {
-      for _, htmlChild := range rootChild.Children() {
-        if htmlChild.Name() == "head" {
-          for _, headChild := range htmlChild.Children() {
-            if headChild.Name() == "title" {
-              titleTag, ok := headChild.(*tree.Title)
-              if !ok {
-                panic("unexpeced")
-              }
-
-              return titleTag.Content()
-            }
-          }
-        }
-      }
-    }
-  }
-
-  return ""
-}
-
 // this function can only add pending
 func parseHTMLFile(cmdArgs CmdArgs, cfg *SearchConfig, path string, si *SearchIndex) error {
   url := path[len(cmdArgs.root):]
@@ -269,14 +246,10 @@ func parseHTMLFile(cmdArgs CmdArgs, cfg *SearchConfig, path string, si *SearchIn
 
   if title == "" {
     // html -> head -> title
-    title = extractHeadTitle(root)
-  }
-
-  if strings.Contains(title, "|") {
-    title = strings.Split(title, "|")[0]
+    title = tree.HeadTitle(root)
   }
 
-  title = strings.TrimSpace(title)
+  title = tree.ShortTitle(title)
 
   contentTags := make([]tree.Tag, 0)
 
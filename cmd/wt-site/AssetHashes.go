@@ -0,0 +1,99 @@
+package main
+
+import (
+  "crypto/sha256"
+  "encoding/hex"
+  "errors"
+  "io/ioutil"
+  "path"
+  "path/filepath"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/files"
+	"github.com/wtsuite/wtsuite/pkg/styles"
+)
+
+// with --hash-assets the copied files, the script bundle, the style sheets and the math font are named by content hash
+// (eg. style.3f79bb7b435b.css), so they can be cached indefinitely
+// the bundle and the style sheets are still built under their plain names (these serve as build cache), and then copied
+const ASSET_HASH_LENGTH = 12
+
+func hashedURL(url string, content []byte) string {
+  sum := sha256.Sum256(content)
+  ext := path.Ext(url)
+
+  return strings.TrimSuffix(url, ext) + "." + hex.EncodeToString(sum[:])[0:ASSET_HASH_LENGTH] + ext
+}
+
+func (cfg *SiteConfig) EnableAssetHashes() error {
+  cfg.assetURLs = make(map[string]string)
+
+  for i, f := range cfg.Files {
+    content, err := ioutil.ReadFile(f.src)
+    if err != nil {
+      return errors.New("Error: " + err.Error())
+    }
+
+    f.url = hashedURL(f.url, content)
+    f.dst = filepath.Join(cfg.outputDir, strings.TrimPrefix(f.url, "/"))
+    cfg.Files[i] = f
+  }
+
+  // the plain outputs of a previous build give the current hashes, so unchanged pages aren't rebuilt
+  plainURLs := []string{cfg.JSURL()}
+  for _, s := range cfg.Styles {
+    plainURLs = append(plainURLs, s.url)
+  }
+
+  for _, url := range plainURLs {
+    if content, err := ioutil.ReadFile(filepath.Join(cfg.outputDir, url)); err == nil {
+      cfg.assetURLs[url] = hashedURL(url, content)
+    }
+  }
+
+  mathFont, err := styles.MathFontData()
+  if err != nil {
+    return err
+  }
+
+  cfg.assetURLs[cfg.MathFontURL()] = hashedURL(cfg.MathFontURL(), mathFont)
+
+  return nil
+}
+
+// url of the plain bundle, style sheet or math font as it is referenced by the pages
+func (cfg *SiteConfig) AssetURL(url string) string {
+  if hashed, ok := cfg.assetURLs[url]; ok {
+    return hashed
+  }
+
+  return url
+}
+
+func (cfg *SiteConfig) AssetDst(url string) string {
+  return filepath.Join(cfg.outputDir, cfg.AssetURL(url))
+}
+
+// copies a freshly built plain output to its content hashed name
+func (cfg *SiteConfig) hashAsset(url string) error {
+  if cfg.assetURLs == nil {
+    return nil
+  }
+
+  plainDst := filepath.Join(cfg.outputDir, url)
+  content, err := ioutil.ReadFile(plainDst)
+  if err != nil {
+    return errors.New("Error: " + err.Error())
+  }
+
+  cfg.assetURLs[url] = hashedURL(url, content)
+
+  dst := cfg.AssetDst(url)
+  if !files.IsFile(dst) {
+    if err := ioutil.WriteFile(dst, content, 0644); err != nil {
+      return errors.New("Error: " + err.Error())
+    }
+  }
+
+  return nil
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+  "fmt"
+  "path/filepath"
+  "sort"
+  "strconv"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/directives"
+	"github.com/wtsuite/wtsuite/pkg/files"
+	"github.com/wtsuite/wtsuite/pkg/parsers"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// collections glob a directory of markdown sources, every item is built through a shared layout, eg.:
+//  collections: {
+//    "./posts/*.md": {
+//      dst: "blog/{slug}.html",
+//      layout: "./layouts/post.thtml",
+//      sort: "date",
+//      order: "descending",
+//      index: {src: "./layouts/blog.thtml", dst: "blog/index.html", perPage: 10},
+//    },
+//  }
+// index templates get the following variables:
+//  collection: all items, as front-matter dicts with extra url and slug entries
+//  items: the items of the current index page
+//  pagination: {page: 1, pages: 3, prev: null, next: "index-2.html"}
+type CollectionItem struct {
+  src         string
+  url         string
+  slug        string
+  frontMatter map[string]interface{}
+}
+
+type CollectionConfig struct {
+  items     []CollectionItem // sorted
+  perPage   int              // 0 for a single index page
+  indexURLs []string
+}
+
+func readCollections(configFile string, outputDir string, collections *tokens.StringDict, pages []PageConfig) ([]PageConfig, error) {
+  res := make([]PageConfig, 0)
+
+  urlExists := func(url string) bool {
+    for _, p := range pages {
+      if p.url == url {
+        return true
+      }
+    }
+
+    for _, p := range res {
+      if p.url == url {
+        return true
+      }
+    }
+
+    return false
+  }
+
+  if err := collections.Loop(func(key *tokens.String, value_ tokens.Token, last bool) error {
+    value, err := tokens.AssertStringDict(value_)
+    if err != nil {
+      return err
+    }
+
+    if err := value.AssertOnlyValidKeys([]string{"dst", "layout", "sort", "order", "index"}); err != nil {
+      return err
+    }
+
+    layoutToken, err := tokens.DictString(value, "layout")
+    if err != nil {
+      return err
+    }
+
+    layout, err := files.Search(configFile, layoutToken.Value())
+    if err != nil {
+      errCtx := layoutToken.Context()
+      return errCtx.NewError(err.Error())
+    }
+
+    dstToken, err := tokens.DictString(value, "dst")
+    if err != nil {
+      return err
+    }
+
+    if !strings.Contains(dstToken.Value(), "{slug}") {
+      errCtx := dstToken.Context()
+      return errCtx.NewError("Error: dst doesn't contain {slug}")
+    }
+
+    pattern := key.Value()
+    if !filepath.IsAbs(pattern) {
+      pattern = filepath.Join(filepath.Dir(configFile), pattern)
+    }
+
+    srcs, err := filepath.Glob(pattern)
+    if err != nil {
+      errCtx := key.Context()
+      return errCtx.NewError("Error: bad glob pattern (" + err.Error() + ")")
+    }
+
+    coll := &CollectionConfig{
+      items: make([]CollectionItem, 0),
+      perPage: 0,
+      indexURLs: make([]string, 0),
+    }
+
+    for _, src_ := range srcs {
+      src, err := filepath.Abs(src_)
+      if err != nil {
+        return err
+      }
+
+      if !directives.IsMarkdownFile(src) {
+        continue
+      }
+
+      p, err := parsers.NewMarkdownParser(src)
+      if err != nil {
+        return err
+      }
+
+      fm := p.FrontMatter()
+
+      slug := strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))
+      if slug_, ok := fm["slug"]; ok {
+        if s, ok := slug_.(string); !ok || s == "" {
+          errCtx := p.FrontMatterContext()
+          return errCtx.NewError("Error: slug isn't a string")
+        } else if strings.ContainsAny(s, "/\\") || strings.Contains(s, "..") {
+          // the slug is part of the output path, which must stay in the output directory
+          errCtx := p.FrontMatterContext()
+          return errCtx.NewError("Error: slug can't contain \"/\", \"\\\" or \"..\"")
+        } else {
+          slug = s
+        }
+      }
+
+      dst, url, err := parseDstURL(outputDir, tokens.NewValueString(
+        strings.ReplaceAll(dstToken.Value(), "{slug}", slug), dstToken.Context()))
+      if err != nil {
+        return err
+      }
+
+      if urlExists(url) {
+        errCtx := key.Context()
+        return errCtx.NewError("Error: duplicate page url " + url + " (from " + src + ")")
+      }
+
+      res = append(res, PageConfig{dst: dst, url: url, src: src, layout: layout, params: []string{}})
+      coll.items = append(coll.items, CollectionItem{src, url, slug, fm})
+    }
+
+    // an empty collection with an index still gets an (empty) index page
+    if _, hasIndex := value.Get("index"); len(coll.items) == 0 && !hasIndex {
+      errCtx := key.Context()
+      return errCtx.NewError("Error: no markdown files found")
+    }
+
+    if err := coll.sortItems(value); err != nil {
+      return err
+    }
+
+    if _, ok := value.Get("index"); ok {
+      indexPages, err := coll.readIndex(configFile, outputDir, value, urlExists)
+      if err != nil {
+        return err
+      }
+
+      res = append(res, indexPages...)
+    }
+
+    return nil
+  }); err != nil {
+    return nil, err
+  }
+
+  return res, nil
+}
+
+func (coll *CollectionConfig) sortItems(value *tokens.StringDict) error {
+  sortKey := ""
+  if _, ok := value.Get("sort"); ok {
+    sortToken, err := tokens.DictString(value, "sort")
+    if err != nil {
+      return err
+    }
+
+    sortKey = sortToken.Value()
+  }
+
+  descending := false
+  if _, ok := value.Get("order"); ok {
+    orderToken, err := tokens.DictString(value, "order")
+    if err != nil {
+      return err
+    }
+
+    switch orderToken.Value() {
+    case "ascending":
+      descending = false
+    case "descending":
+      descending = true
+    default:
+      errCtx := orderToken.Context()
+      return errCtx.NewError("Error: expected \"ascending\" or \"descending\"")
+    }
+  }
+
+  sort.SliceStable(coll.items, func(i, j int) bool {
+    a, b := coll.items[i], coll.items[j]
+
+    if sortKey != "" {
+      // items without the key always come last
+      va, okA := a.frontMatter[sortKey]
+      vb, okB := b.frontMatter[sortKey]
+      okA = okA && va != nil
+      okB = okB && vb != nil
+
+      switch {
+      case okA && !okB:
+        return true
+      case !okA && okB:
+        return false
+      case okA && okB:
+        if c := compareFrontMatterValues(va, vb); c != 0 {
+          if descending {
+            return c > 0
+          } else {
+            return c < 0
+          }
+        }
+      }
+    }
+
+    if descending && sortKey == "" {
+      return a.slug > b.slug
+    } else {
+      return a.slug < b.slug
+    }
+  })
+
+  return nil
+}
+
+// numbers are compared numerically, everything else as strings (eg. ISO dates)
+func compareFrontMatterValues(a interface{}, b interface{}) int {
+  toFloat := func(x interface{}) (float64, bool) {
+    switch v := x.(type) {
+    case int:
+      return float64(v), true
+    case float64:
+      return v, true
+    default:
+      return 0.0, false
+    }
+  }
+
+  fa, okA := toFloat(a)
+  fb, okB := toFloat(b)
+
+  if okA && okB {
+    switch {
+    case fa < fb:
+      return -1
+    case fa > fb:
+      return 1
+    default:
+      return 0
+    }
+  }
+
+  return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// {src: "./layouts/blog.thtml", dst: "blog/index.html", perPage: 10}
+// subsequent index pages get a number suffix: blog/index-2.html, blog/index-3.html, ...
+func (coll *CollectionConfig) readIndex(configFile string, outputDir string, value *tokens.StringDict,
+  urlExists func(string) bool) ([]PageConfig, error) {
+  index, err := tokens.DictStringDict(value, "index")
+  if err != nil {
+    return nil, err
+  }
+
+  if err := index.AssertOnlyValidKeys([]string{"src", "dst", "perPage"}); err != nil {
+    return nil, err
+  }
+
+  srcToken, err := tokens.DictString(index, "src")
+  if err != nil {
+    return nil, err
+  }
+
+  src, err := files.Search(configFile, srcToken.Value())
+  if err != nil {
+    errCtx := srcToken.Context()
+    return nil, errCtx.NewError(err.Error())
+  }
+
+  dstToken, err := tokens.DictString(index, "dst")
+  if err != nil {
+    return nil, err
+  }
+
+  nPages := 1
+  if _, ok := index.Get("perPage"); ok {
+    perPage, err := tokens.DictInt(index, "perPage")
+    if err != nil {
+      return nil, err
+    }
+
+    if perPage.Value() < 1 {
+      errCtx := perPage.Context()
+      return nil, errCtx.NewError("Error: expected a positive integer")
+    }
+
+    coll.perPage = perPage.Value()
+    nPages = (len(coll.items) + coll.perPage - 1)/coll.perPage
+
+    if nPages < 1 {
+      nPages = 1
+    }
+  }
+
+  // the index pages must be rebuilt if any of the items change
+  deps := make([]string, len(coll.items))
+  for i, item := range coll.items {
+    deps[i] = item.src
+  }
+
+  res := make([]PageConfig, 0)
+
+  ext := filepath.Ext(dstToken.Value())
+  base := strings.TrimSuffix(dstToken.Value(), ext)
+  for i := 0; i < nPages; i++ {
+    dst_ := dstToken.Value()
+    if i > 0 {
+      dst_ = base + "-" + strconv.Itoa(i+1) + ext
+    }
+
+    dst, url, err := parseDstURL(outputDir, tokens.NewValueString(dst_, dstToken.Context()))
+    if err != nil {
+      return nil, err
+    }
+
+    if urlExists(url) {
+      errCtx := dstToken.Context()
+      return nil, errCtx.NewError("Error: duplicate page url " + url)
+    }
+
+    coll.indexURLs = append(coll.indexURLs, url)
+    res = append(res, PageConfig{dst: dst, url: url, src: src, params: []string{}, deps: deps, collection: coll, index: i})
+  }
+
+  return res, nil
+}
+
+// variables of the index page, links are relative to that page
+// for multilingual sites all urls are prefixed by the locale
+func (coll *CollectionConfig) IndexVars(i int, locale string) map[string]interface{} {
+  localize := func(url string) string {
+    if locale == "" {
+      return url
+    } else {
+      return localeURL(locale, url)
+    }
+  }
+
+  pageURL := localize(coll.indexURLs[i])
+
+  collection := make([]interface{}, len(coll.items))
+  for j, item := range coll.items {
+    d := make(map[string]interface{})
+    for k, v := range item.frontMatter {
+      d[k] = v
+    }
+
+    d["url"] = cleanLink(pageURL, localize(item.url))
+    d["slug"] = item.slug
+
+    collection[j] = d
+  }
+
+  items := collection
+  if coll.perPage > 0 {
+    start := i*coll.perPage
+    stop := start + coll.perPage
+    if stop > len(collection) {
+      stop = len(collection)
+    }
+
+    items = collection[start:stop]
+  }
+
+  var prev interface{} = nil
+  if i > 0 {
+    prev = cleanLink(pageURL, localize(coll.indexURLs[i-1]))
+  }
+
+  var next interface{} = nil
+  if i < len(coll.indexURLs) - 1 {
+    next = cleanLink(pageURL, localize(coll.indexURLs[i+1]))
+  }
+
+  return map[string]interface{}{
+    "collection": collection,
+    "items": items,
+    "pagination": map[string]interface{}{
+      "page": i + 1,
+      "pages": len(coll.indexURLs),
+      "prev": prev,
+      "next": next,
+    },
+  }
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+  "path"
+  "regexp"
+  "strconv"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/directives"
+	"github.com/wtsuite/wtsuite/pkg/styles"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tokens/patterns"
+	"github.com/wtsuite/wtsuite/pkg/tree"
+)
+
+// the rules of the linked sheets that match the critical elements of a page are inlined in the head, and the complete
+// sheets are loaded asynchronously, eg.:
+//  critical: {
+//    fold: "main > section:nth-child(2)", // elements from the first match onwards aren't critical
+//    depth: 4, // elements nested deeper in the body aren't critical
+//  }
+// without fold and depth every element of the page is critical
+type CriticalConfig struct {
+  fold  styles.Selector // nil for no fold
+  depth int             // 0 for unlimited depth, children of body are at depth 1
+}
+
+func readCritical(critical *tokens.StringDict) (*CriticalConfig, error) {
+  if err := critical.AssertOnlyValidKeys([]string{"fold", "depth"}); err != nil {
+    return nil, err
+  }
+
+  fold, err := readFeedSelector(critical, "fold")
+  if err != nil {
+    return nil, err
+  }
+
+  res := &CriticalConfig{fold, 0}
+
+  if _, ok := critical.Get("depth"); ok {
+    depth, err := tokens.DictInt(critical, "depth")
+    if err != nil {
+      return nil, err
+    }
+
+    if depth.Value() < 1 {
+      errCtx := depth.Context()
+      return nil, errCtx.NewError("Error: expected a positive integer")
+    }
+
+    res.depth = depth.Value()
+  }
+
+  return res, nil
+}
+
+// for the page parameter string
+func (cc *CriticalConfig) String() string {
+  var b strings.Builder
+
+  b.WriteString("fold:")
+  if cc.fold != nil {
+    b.WriteString(cc.fold.Write())
+  }
+
+  b.WriteString(",depth:")
+  b.WriteString(strconv.Itoa(cc.depth))
+
+  return b.String()
+}
+
+// the body elements before the first fold element (in document order), up to the max depth
+func (cc *CriticalConfig) criticalTags(r *tree.Root) (map[tree.Tag]bool, error) {
+  _, htmlTag, err := r.GetDocTypeAndHTML()
+  if err != nil {
+    return nil, err
+  }
+
+  foldTags := make(map[tree.Tag]bool)
+  if cc.fold != nil {
+    for _, t := range cc.fold.Match(htmlTag) {
+      foldTags[t] = true
+    }
+  }
+
+  res := make(map[tree.Tag]bool)
+  res[htmlTag] = true
+
+  // returns false once the fold is reached
+  var walk func(t tree.Tag, depth int) bool
+  walk = func(t tree.Tag, depth int) bool {
+    if foldTags[t] {
+      return false
+    }
+
+    if cc.depth == 0 || depth <= cc.depth {
+      res[t] = true
+    }
+
+    for _, child := range t.Children() {
+      if !walk(child, depth + 1) {
+        return false
+      }
+    }
+
+    return true
+  }
+
+  for _, child := range htmlTag.Children() {
+    if child.Name() == "body" {
+      res[child] = true
+
+      for _, bodyChild := range child.Children() {
+        if !walk(bodyChild, 1) {
+          break
+        }
+      }
+    }
+  }
+
+  return res, nil
+}
+
+var CSS_URL_REGEXP = regexp.MustCompile(`url\(\s*(['"]?)([^'")\s]+)(['"]?)\s*\)`)
+
+// relative urls of a sheet (eg. fonts and images) are relative to the sheet, so must be rebased when the rules are
+// inlined in the page
+func rebaseCSSURLs(css string, sheetURL string, pageURL string) string {
+  dir := path.Dir(cleanURL(sheetURL))
+
+  return CSS_URL_REGEXP.ReplaceAllStringFunc(css, func(s string) string {
+    m := CSS_URL_REGEXP.FindStringSubmatch(s)
+    url := m[2]
+    if strings.HasPrefix(url, "/") || strings.HasPrefix(url, "#") || EXTERNAL_LINK_REGEXP.MatchString(url) {
+      return s
+    }
+
+    return "url(" + m[1] + cleanLink(pageURL, path.Join(dir, url)) + m[3] + ")"
+  })
+}
+
+// returns an empty string if no rules match, sheetURLs are the urls of the sheets
+func (cfg *SiteConfig) criticalCSS(r *tree.Root, page PageConfig, sheets []styles.Sheet, sheetURLs []string) (string, error) {
+  critical, err := cfg.Critical.criticalTags(r)
+  if err != nil {
+    return "", err
+  }
+
+  isCritical := func(t tree.Tag) bool {
+    return critical[t]
+  }
+
+  // the math font is only declared once
+  prevMathFontURL := directives.MATH_FONT_URL
+  defer func() {
+    directives.MATH_FONT_URL = prevMathFontURL
+  }()
+
+  var b strings.Builder
+  for i, sheet := range sheets {
+    criticalSheet, err := sheet.Critical(r, isCritical)
+    if err != nil {
+      return "", err
+    }
+
+    if criticalSheet.IsEmpty() {
+      continue
+    }
+
+    css, err := criticalSheet.Write(true, patterns.NL, patterns.TAB)
+    if err != nil {
+      return "", err
+    }
+
+    b.WriteString(rebaseCSSURLs(css, sheetURLs[i], page.url))
+    directives.MATH_FONT_URL = ""
+  }
+
+  return b.String(), nil
+}
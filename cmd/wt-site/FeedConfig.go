@@ -0,0 +1,468 @@
+package main
+
+import (
+  "io/ioutil"
+  "path"
+  "sort"
+  "strings"
+  "time"
+
+	"github.com/wtsuite/wtsuite/pkg/files"
+	"github.com/wtsuite/wtsuite/pkg/parsers"
+	"github.com/wtsuite/wtsuite/pkg/styles"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tree"
+)
+
+// feeds are keyed by dst file, eg.:
+//  feeds: {
+//    "blog/feed.xml": {
+//      format: "atom", // or "rss"
+//      title: "My blog",
+//      pages: "blog/*.html",
+//      select: {title: "h1", summary: "article > p", date: "time"},
+//      limit: 20,
+//      lastmod: "git",
+//    },
+//  }
+// the entries are extracted from the built pages, like the search indexer does
+// the title defaults to the head title, the summary to the description meta tag, and the date to the lastmod of the page
+// dates are read from the datetime or content attribute of the selected tag, or from its text
+type FeedConfig struct {
+  dst     string
+  url     string
+  format  string
+  title   string
+  pages   []string // globs of page urls, without leading slash
+  limit   int      // 0 for no limit
+  lastmod string
+
+  titleQuery   styles.Selector
+  summaryQuery styles.Selector
+  dateQuery    styles.Selector
+}
+
+type FeedEntry struct {
+  url     string
+  title   string
+  summary string
+  date    time.Time
+}
+
+var FEED_DATE_FORMATS = []string{
+  time.RFC3339,
+  "2006-01-02T15:04:05",
+  "2006-01-02 15:04:05",
+  "2006-01-02",
+  time.RFC1123Z,
+  time.RFC1123,
+  "January 2, 2006",
+  "2 January 2006",
+}
+
+func readFeedSelector(d *tokens.StringDict, key string) (styles.Selector, error) {
+  if _, ok := d.Get(key); !ok {
+    return nil, nil
+  }
+
+  t, err := tokens.DictString(d, key)
+  if err != nil {
+    return nil, err
+  }
+
+  sels, err := styles.ParseSelectorList(t)
+  if err != nil {
+    return nil, err
+  }
+
+  if len(sels) != 1 {
+    errCtx := t.Context()
+    return nil, errCtx.NewError("Error: expected a single selector")
+  }
+
+  return sels[0], nil
+}
+
+func readFeeds(outputDir string, feeds *tokens.StringDict, pages []PageConfig) ([]FeedConfig, error) {
+  res := make([]FeedConfig, 0)
+
+  if err := feeds.Loop(func(key *tokens.String, value_ tokens.Token, last bool) error {
+    value, err := tokens.AssertStringDict(value_)
+    if err != nil {
+      return err
+    }
+
+    if err := value.AssertOnlyValidKeys([]string{"format", "title", "pages", "select", "limit", "lastmod"}); err != nil {
+      return err
+    }
+
+    dst, url, err := parseDstURL(outputDir, key)
+    if err != nil {
+      return err
+    }
+
+    for _, p := range pages {
+      if p.url == url {
+        errCtx := key.Context()
+        return errCtx.NewError("Error: feed dst is also a page")
+      }
+    }
+
+    feed := FeedConfig{dst: dst, url: url, limit: 0}
+
+    feed.format, err = readOptionalString(value, "format", "atom")
+    if err != nil {
+      return err
+    }
+
+    if feed.format != "atom" && feed.format != "rss" {
+      t, _ := value.Get("format")
+      errCtx := t.Context()
+      return errCtx.NewError("Error: expected \"atom\" or \"rss\"")
+    }
+
+    titleToken, err := tokens.DictString(value, "title")
+    if err != nil {
+      return err
+    }
+
+    feed.title = titleToken.Value()
+
+    if _, ok := value.Get("pages"); !ok {
+      errCtx := value.Context()
+      return errCtx.NewError("Error: pages not found in feed")
+    }
+
+    feed.pages, err = readOptionalStringList(value, "pages")
+    if err != nil {
+      return err
+    }
+
+    for i, pattern := range feed.pages {
+      feed.pages[i] = strings.TrimPrefix(pattern, "/")
+      if _, err := path.Match(feed.pages[i], ""); err != nil {
+        t, _ := value.Get("pages")
+        errCtx := t.Context()
+        return errCtx.NewError("Error: bad pattern \"" + pattern + "\"")
+      }
+    }
+
+    if _, ok := value.Get("limit"); ok {
+      limit, err := tokens.DictInt(value, "limit")
+      if err != nil {
+        return err
+      }
+
+      if limit.Value() < 1 {
+        errCtx := limit.Context()
+        return errCtx.NewError("Error: expected a positive integer")
+      }
+
+      feed.limit = limit.Value()
+    }
+
+    feed.lastmod, err = readLastModMode(value)
+    if err != nil {
+      return err
+    }
+
+    if _, ok := value.Get("select"); ok {
+      sel, err := tokens.DictStringDict(value, "select")
+      if err != nil {
+        return err
+      }
+
+      if err := sel.AssertOnlyValidKeys([]string{"title", "summary", "date"}); err != nil {
+        return err
+      }
+
+      if feed.titleQuery, err = readFeedSelector(sel, "title"); err != nil {
+        return err
+      }
+
+      if feed.summaryQuery, err = readFeedSelector(sel, "summary"); err != nil {
+        return err
+      }
+
+      if feed.dateQuery, err = readFeedSelector(sel, "date"); err != nil {
+        return err
+      }
+    }
+
+    res = append(res, feed)
+
+    return nil
+  }); err != nil {
+    return nil, err
+  }
+
+  return res, nil
+}
+
+// collection index pages are never feed entries
+func (feed FeedConfig) matchesPage(page PageConfig) bool {
+  if page.collection != nil {
+    return false
+  }
+
+  for _, pattern := range feed.pages {
+    if ok, _ := path.Match(pattern, strings.TrimPrefix(page.url, "/")); ok {
+      return true
+    }
+  }
+
+  return false
+}
+
+func selectText(sel styles.Selector, root *tree.Root) string {
+  if sel == nil {
+    return ""
+  }
+
+  tags := sel.Match(root)
+  if len(tags) == 0 {
+    return ""
+  }
+
+  parts := make([]string, 0)
+  if err := tree.WalkText(tags[0], []tree.Tag{}, func(_ []tree.Tag, s string) error {
+    parts = append(parts, s)
+    return nil
+  }); err != nil {
+    panic(err)
+  }
+
+  return strings.Join(strings.Fields(strings.Join(parts, "")), " ")
+}
+
+func selectAttribute(sel styles.Selector, root *tree.Root, names ...string) string {
+  if sel == nil {
+    return ""
+  }
+
+  tags := sel.Match(root)
+  if len(tags) == 0 {
+    return ""
+  }
+
+  for _, name := range names {
+    if v_, ok := tags[0].Attributes().Get(name); ok {
+      if v, err := tokens.AssertString(v_); err == nil {
+        return v.Value()
+      }
+    }
+  }
+
+  return ""
+}
+
+func parseFeedDate(s string) (time.Time, bool) {
+  s = strings.TrimSpace(s)
+  for _, format := range FEED_DATE_FORMATS {
+    if t, err := time.Parse(format, s); err == nil {
+      return t, true
+    }
+  }
+
+  return time.Time{}, false
+}
+
+func (cfg *SiteConfig) readFeedEntry(feed FeedConfig, page PageConfig) (FeedEntry, error) {
+  rawBytes, err := ioutil.ReadFile(page.dst)
+  if err != nil {
+    return FeedEntry{}, err
+  }
+
+  p, err := parsers.NewXMLParserFromBytes(rawBytes, page.dst)
+  if err != nil {
+    return FeedEntry{}, err
+  }
+
+  rawTags, err := p.BuildTags()
+  if err != nil {
+    return FeedEntry{}, err
+  }
+
+  root, err := tree.BuildPermissive(rawTags)
+  if err != nil {
+    return FeedEntry{}, err
+  }
+
+  entry := FeedEntry{url: absoluteURL(cfg.URL, page.url)}
+
+  entry.title = selectText(feed.titleQuery, root)
+  if entry.title == "" {
+    entry.title = tree.ShortTitle(tree.HeadTitle(root))
+  }
+
+  entry.summary = selectText(feed.summaryQuery, root)
+  if entry.summary == "" {
+    descQuery, err := styles.ParseSelectorList(tokens.NewValueString("head > meta[name=\"description\"]", p.NewContext(0, 1)))
+    if err != nil {
+      panic(err)
+    }
+
+    entry.summary = selectAttribute(descQuery[0], root, "content")
+  }
+
+  dateStr := selectAttribute(feed.dateQuery, root, "datetime", "content")
+  if dateStr == "" {
+    dateStr = selectText(feed.dateQuery, root)
+  }
+
+  if dateStr != "" {
+    ok := false
+    entry.date, ok = parseFeedDate(dateStr)
+    if !ok {
+      errCtx := p.NewContext(0, 1)
+      return FeedEntry{}, errCtx.NewError("Error: unrecognized date format \"" + dateStr + "\"")
+    }
+  } else {
+    entry.date = cfg.PageLastMod(page, feed.lastmod)
+  }
+
+  return entry, nil
+}
+
+func (cfg *SiteConfig) buildFeed(feed FeedConfig) error {
+  entries := make([]FeedEntry, 0)
+
+  for _, page := range cfg.Pages {
+    if !feed.matchesPage(page) {
+      continue
+    }
+
+    entry, err := cfg.readFeedEntry(feed, page)
+    if err != nil {
+      return err
+    }
+
+    entries = append(entries, entry)
+  }
+
+  // newest first
+  sort.SliceStable(entries, func(i, j int) bool {
+    if entries[i].date.Equal(entries[j].date) {
+      return entries[i].url < entries[j].url
+    }
+
+    return entries[i].date.After(entries[j].date)
+  })
+
+  if feed.limit > 0 && len(entries) > feed.limit {
+    entries = entries[0:feed.limit]
+  }
+
+  var output string
+  if feed.format == "rss" {
+    output = cfg.writeRSSFeed(feed, entries)
+  } else {
+    output = cfg.writeAtomFeed(feed, entries)
+  }
+
+  return files.WriteFile(cfg.configFile, feed.dst, []byte(output))
+}
+
+func (cfg *SiteConfig) writeAtomFeed(feed FeedConfig, entries []FeedEntry) string {
+  var b strings.Builder
+
+  writeElement := func(indent string, name string, content string) {
+    b.WriteString(indent + "<" + name + ">")
+    writeXMLText(&b, content)
+    b.WriteString("</" + name + ">\n")
+  }
+
+  writeLink := func(indent string, href string, rel string) {
+    b.WriteString(indent + "<link href=\"")
+    writeXMLText(&b, href)
+    b.WriteString("\"")
+    if rel != "" {
+      b.WriteString(" rel=\"" + rel + "\"")
+    }
+    b.WriteString("/>\n")
+  }
+
+  var updated time.Time
+  for _, entry := range entries {
+    if entry.date.After(updated) {
+      updated = entry.date
+    }
+  }
+
+  feedURL := absoluteURL(cfg.URL, feed.url)
+
+  b.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n")
+  b.WriteString("<feed xmlns=\"http://www.w3.org/2005/Atom\">\n")
+  writeElement("  ", "title", feed.title)
+  writeLink("  ", feedURL, "self")
+  writeLink("  ", cfg.URL + "/", "")
+  writeElement("  ", "id", feedURL)
+  writeElement("  ", "updated", updated.UTC().Format(time.RFC3339))
+
+  for _, entry := range entries {
+    b.WriteString("  <entry>\n")
+    writeElement("    ", "title", entry.title)
+    writeLink("    ", entry.url, "")
+    writeElement("    ", "id", entry.url)
+    writeElement("    ", "updated", entry.date.UTC().Format(time.RFC3339))
+    if entry.summary != "" {
+      writeElement("    ", "summary", entry.summary)
+    }
+    b.WriteString("  </entry>\n")
+  }
+
+  b.WriteString("</feed>\n")
+
+  return b.String()
+}
+
+func (cfg *SiteConfig) writeRSSFeed(feed FeedConfig, entries []FeedEntry) string {
+  var b strings.Builder
+
+  writeElement := func(indent string, name string, content string) {
+    b.WriteString(indent + "<" + name + ">")
+    writeXMLText(&b, content)
+    b.WriteString("</" + name + ">\n")
+  }
+
+  b.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n")
+  b.WriteString("<rss version=\"2.0\" xmlns:atom=\"http://www.w3.org/2005/Atom\">\n")
+  b.WriteString("  <channel>\n")
+  writeElement("    ", "title", feed.title)
+  writeElement("    ", "link", cfg.URL + "/")
+  writeElement("    ", "description", feed.title)
+  b.WriteString("    <atom:link href=\"")
+  writeXMLText(&b, absoluteURL(cfg.URL, feed.url))
+  b.WriteString("\" rel=\"self\" type=\"application/rss+xml\"/>\n")
+
+  if len(entries) > 0 {
+    writeElement("    ", "lastBuildDate", entries[0].date.UTC().Format(time.RFC1123Z))
+  }
+
+  for _, entry := range entries {
+    b.WriteString("    <item>\n")
+    writeElement("      ", "title", entry.title)
+    writeElement("      ", "link", entry.url)
+    writeElement("      ", "guid", entry.url)
+    writeElement("      ", "pubDate", entry.date.UTC().Format(time.RFC1123Z))
+    if entry.summary != "" {
+      writeElement("      ", "description", entry.summary)
+    }
+    b.WriteString("    </item>\n")
+  }
+
+  b.WriteString("  </channel>\n")
+  b.WriteString("</rss>\n")
+
+  return b.String()
+}
+
+func (cfg *SiteConfig) buildFeeds() error {
+  for _, feed := range cfg.Feeds {
+    if err := cfg.buildFeed(feed); err != nil {
+      return err
+    }
+  }
+
+  return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+  "errors"
+  "io/ioutil"
+  "path"
+  "path/filepath"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/files"
+)
+
+// local font files referenced by the src of @font-face rules are copied to fonts/ (named by content hash with
+// --hash-assets), and the urls in the style sheets are rewritten
+type FontConfig struct {
+  url string
+  dst string
+  src string
+}
+
+func (cfg *SiteConfig) uniqueFontURL(src string) string {
+  urlExists := func(url string) bool {
+    for _, p := range cfg.Pages {
+      if p.url == url {
+        return true
+      }
+    }
+
+    for _, f := range cfg.Files {
+      if f.url == url {
+        return true
+      }
+    }
+
+    for _, f := range cfg.Fonts {
+      if f.url == url {
+        return true
+      }
+    }
+
+    return false
+  }
+
+  ext := path.Ext(src)
+
+  return uniqueURL("fonts/" + strings.TrimSuffix(filepath.Base(src), ext), ext, urlExists)
+}
+
+// styles.FONT_URL
+func (cfg *SiteConfig) fontURL(src string) (string, error) {
+  if cfg.activeFonts != nil {
+    cfg.activeFonts = append(cfg.activeFonts, src)
+  }
+
+  for _, f := range cfg.Fonts {
+    if f.src == src {
+      return f.url, nil
+    }
+  }
+
+  url := cfg.uniqueFontURL(src)
+
+  if cfg.assetURLs != nil {
+    content, err := ioutil.ReadFile(src)
+    if err != nil {
+      return "", errors.New("Error: " + err.Error())
+    }
+
+    url = hashedURL(url, content)
+  }
+
+  cfg.Fonts = append(cfg.Fonts, FontConfig{url: url, dst: filepath.Join(cfg.outputDir, url), src: src})
+
+  return url, nil
+}
+
+func buildSiteFonts(cfg *SiteConfig) error {
+  for _, f := range cfg.Fonts {
+    if files.RequiresDepUpdate(f.dst, "") {
+      files.StartDstUpdate(f.dst, "")
+      files.AddDep(f.dst, f.src)
+
+      if err := copyFile(f.src, f.dst); err != nil {
+        return err
+      }
+    }
+  }
+
+  return nil
+}
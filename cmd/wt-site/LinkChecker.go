@@ -0,0 +1,205 @@
+package main
+
+import (
+  "fmt"
+  "os"
+  "path"
+  "path/filepath"
+  "regexp"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tree"
+)
+
+// links with a scheme (http:, mailto:, data:, ...) or protocol-relative links are external
+var EXTERNAL_LINK_REGEXP = regexp.MustCompile(`^(?:[a-zA-Z][a-zA-Z0-9+.\-]*:|//)`)
+
+type PageLink struct {
+  page string // url of the page containing the link
+  href string
+  ctx  context.Context
+}
+
+// collects the links and ids of every built page, and checks them once all the pages are built
+type LinkChecker struct {
+  ids   map[string]map[string]bool // page url -> set of ids
+  links []PageLink
+}
+
+func NewLinkChecker() *LinkChecker {
+  return &LinkChecker{make(map[string]map[string]bool), make([]PageLink, 0)}
+}
+
+func (lc *LinkChecker) AddPage(url string, root *tree.Root) {
+  ids := make(map[string]bool)
+
+  var walk func(t tree.Tag)
+  walk = func(t tree.Tag) {
+    if id := t.GetID(); id != "" {
+      ids[id] = true
+    }
+
+    if attr := t.Attributes(); attr != nil {
+      for _, key := range []string{"href", "src"} {
+        if v_, ok := attr.Get(key); ok && tokens.IsString(v_) {
+          v, err := tokens.AssertString(v_)
+          if err != nil {
+            panic(err)
+          }
+
+          lc.links = append(lc.links, PageLink{url, v.Value(), v.Context()})
+        }
+      }
+
+      // legacy anchors
+      if v_, ok := attr.Get("name"); ok && t.Name() == "a" && tokens.IsString(v_) {
+        if v, err := tokens.AssertString(v_); err == nil {
+          ids[v.Value()] = true
+        }
+      }
+    }
+
+    for _, child := range t.Children() {
+      walk(child)
+    }
+  }
+
+  for _, child := range root.Children() {
+    walk(child)
+  }
+
+  lc.ids[url] = ids
+}
+
+// the urls of all the generated files
+func (cfg *SiteConfig) outputURLs() map[string]bool {
+  res := make(map[string]bool)
+
+  for _, p := range cfg.Pages {
+    res[cleanURL(p.url)] = true
+  }
+
+  for _, f := range cfg.Files {
+    res[cleanURL(f.url)] = true
+  }
+
+  for _, s := range cfg.Styles {
+    res[cleanURL(s.url)] = true
+    res[cleanURL(cfg.AssetURL(s.url))] = true
+  }
+
+  if cfg.Sitemap != nil {
+    res[cleanURL(cfg.Sitemap.url)] = true
+  }
+
+  if cfg.Robots != nil {
+    res["/" + filepath.Base(cfg.Robots.dst)] = true
+  }
+
+  for _, feed := range cfg.Feeds {
+    res[cleanURL(feed.url)] = true
+  }
+
+  res[cleanURL(cfg.JSURL())] = true
+  res[cleanURL(cfg.AssetURL(cfg.JSURL()))] = true
+  res[cleanURL(cfg.AssetURL(cfg.MathFontURL()))] = true
+
+  return res
+}
+
+// returns the absolute url (without query and fragment) and the fragment
+func resolveLink(pageURL string, href string) (string, string) {
+  fragment := ""
+  if i := strings.Index(href, "#"); i != -1 {
+    fragment = href[i+1:]
+    href = href[0:i]
+  }
+
+  if i := strings.Index(href, "?"); i != -1 {
+    href = href[0:i]
+  }
+
+  if href == "" {
+    return cleanURL(pageURL), fragment
+  }
+
+  var url string
+  if strings.HasPrefix(href, "/") {
+    url = path.Clean(href)
+  } else {
+    url = path.Join(path.Dir(cleanURL(pageURL)), href)
+  }
+
+  if strings.HasSuffix(href, "/") && url != "/" {
+    url += "/"
+  }
+
+  return url, fragment
+}
+
+func (lc *LinkChecker) Check(cfg *SiteConfig) error {
+  outputs := cfg.outputURLs()
+
+  exists := func(url string) (string, bool) {
+    candidates := []string{url}
+    if strings.HasSuffix(url, "/") {
+      candidates = []string{url + "index.html"}
+    } else if path.Ext(url) == "" {
+      candidates = append(candidates, url + "/index.html", url + ".html")
+    }
+
+    for _, c := range candidates {
+      if outputs[c] {
+        return c, true
+      }
+
+      // also accept files that were put in the output dir by other means
+      if info, err := os.Stat(filepath.Join(cfg.outputDir, filepath.FromSlash(c))); err == nil && !info.IsDir() {
+        return c, true
+      }
+    }
+
+    return "", false
+  }
+
+  nBroken := 0
+  report := func(link PageLink, msg string) {
+    err := link.ctx.NewError(msg)
+    fmt.Fprintln(os.Stderr, err.Error())
+    nBroken += 1
+  }
+
+  for _, link := range lc.links {
+    if EXTERNAL_LINK_REGEXP.MatchString(link.href) {
+      continue
+    }
+
+    url, fragment := resolveLink(link.page, link.href)
+
+    target, ok := exists(url)
+    if !ok {
+      report(link, "Error: broken link \"" + link.href + "\" (" + url + " not found)")
+      continue
+    }
+
+    if fragment != "" {
+      ids, ok := lc.ids[target]
+      if !ok {
+        // not a page, so the fragment can't be checked
+        continue
+      }
+
+      if !ids[fragment] {
+        report(link, "Error: broken link \"" + link.href + "\" (#" + fragment + " not found in " + target + ")")
+      }
+    }
+  }
+
+  if nBroken > 0 {
+    return fmt.Errorf("Error: found %d broken links", nBroken)
+  }
+
+  return nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+  "path/filepath"
+  "regexp"
+  "sort"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/directives"
+	"github.com/wtsuite/wtsuite/pkg/files"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tree"
+)
+
+// every page is built once per locale, into /<lang>/..., eg.:
+//  i18n: {
+//    default: "en",
+//    locales: {en: "./i18n/en.json", fr: "./i18n/fr.json", de: "./i18n/de.json"},
+//  }
+// values are translation catalogs (see pkg/directives/globals_locale.go)
+type LocaleConfig struct {
+  lang    string
+  catalog string
+}
+
+var LOCALE_REGEXP = regexp.MustCompile(`^[a-z]{2,3}(?:-[A-Za-z0-9]+)*$`)
+
+func readLocales(configFile string, i18n *tokens.StringDict) ([]LocaleConfig, string, error) {
+  if err := i18n.AssertOnlyValidKeys([]string{"default", "locales"}); err != nil {
+    return nil, "", err
+  }
+
+  locales, err := tokens.DictStringDict(i18n, "locales")
+  if err != nil {
+    return nil, "", err
+  }
+
+  res := make([]LocaleConfig, 0)
+  if err := locales.Loop(func(key *tokens.String, value_ tokens.Token, last bool) error {
+    if !LOCALE_REGEXP.MatchString(key.Value()) {
+      errCtx := key.Context()
+      return errCtx.NewError("Error: invalid locale (expected eg. \"en\" or \"pt-BR\")")
+    }
+
+    value, err := tokens.AssertString(value_)
+    if err != nil {
+      return err
+    }
+
+    catalog, err := files.Search(configFile, value.Value())
+    if err != nil {
+      errCtx := value.Context()
+      return errCtx.NewError(err.Error())
+    }
+
+    res = append(res, LocaleConfig{key.Value(), catalog})
+
+    return nil
+  }); err != nil {
+    return nil, "", err
+  }
+
+  if len(res) == 0 {
+    errCtx := locales.Context()
+    return nil, "", errCtx.NewError("Error: should have at least one entry")
+  }
+
+  sort.Slice(res, func(i, j int) bool {
+    return res[i].lang < res[j].lang
+  })
+
+  defaultToken, err := tokens.DictString(i18n, "default")
+  if err != nil {
+    return nil, "", err
+  }
+
+  found := false
+  for _, l := range res {
+    if l.lang == defaultToken.Value() {
+      found = true
+    }
+  }
+
+  if !found {
+    errCtx := defaultToken.Context()
+    return nil, "", errCtx.NewError("Error: default locale not in locales")
+  }
+
+  return res, defaultToken.Value(), nil
+}
+
+func localeURL(lang string, url string) string {
+  return "/" + lang + cleanURL(url)
+}
+
+// replaces every page by one page per locale, the pages of scripts and styles are updated accordingly
+func (cfg *SiteConfig) expandLocales() {
+  pages := make([]PageConfig, 0)
+
+  for _, page := range cfg.Pages {
+    urls := make(map[string]string)
+    for _, l := range cfg.Locales {
+      urls[l.lang] = localeURL(l.lang, page.url)
+    }
+
+    for _, l := range cfg.Locales {
+      localPage := page
+      localPage.url = urls[l.lang]
+      localPage.dst = filepath.Join(cfg.outputDir, strings.TrimPrefix(localPage.url, "/"))
+      localPage.locale = l.lang
+      localPage.localeURLs = urls
+
+      pages = append(pages, localPage)
+    }
+  }
+
+  cfg.Pages = pages
+
+  expandURLs := func(urls []string) []string {
+    res := make([]string, 0)
+    for _, url := range urls {
+      for _, l := range cfg.Locales {
+        res = append(res, localeURL(l.lang, url))
+      }
+    }
+
+    return res
+  }
+
+  for i, s := range cfg.Scripts {
+    s.pages = expandURLs(s.pages)
+    cfg.Scripts[i] = s
+  }
+
+  for i, s := range cfg.Styles {
+    s.pages = expandURLs(s.pages)
+    cfg.Styles[i] = s
+  }
+}
+
+func (cfg *SiteConfig) registerCatalogs() error {
+  for _, l := range cfg.Locales {
+    if err := directives.RegisterCatalog(l.lang, l.catalog); err != nil {
+      return err
+    }
+  }
+
+  directives.SetDefaultLocale(cfg.DefaultLocale)
+
+  return nil
+}
+
+func (cfg *SiteConfig) LocaleNames() []string {
+  res := make([]string, len(cfg.Locales))
+  for i, l := range cfg.Locales {
+    res[i] = l.lang
+  }
+
+  return res
+}
+
+// hreflang links to the same page in every locale, plus x-default
+func (cfg *SiteConfig) linkAlternates(r *tree.Root, page PageConfig) error {
+  href := func(url string) string {
+    if cfg.URL != "" {
+      return absoluteURL(cfg.URL, url)
+    } else {
+      return cleanLink(page.url, url)
+    }
+  }
+
+  for _, l := range cfg.Locales {
+    if err := r.LinkAlternate(href(page.localeURLs[l.lang]), l.lang); err != nil {
+      return err
+    }
+  }
+
+  return r.LinkAlternate(href(page.localeURLs[cfg.DefaultLocale]), "x-default")
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+  "fmt"
+  "io/ioutil"
+  "os"
+  "path"
+  "regexp"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/styles"
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tree"
+)
+
+// with --purge-css the rules that don't match any element of the pages linking the sheet are dropped
+// classes that are only added by scripts must be safelisted (glob patterns are allowed), eg.:
+//  purge: {
+//    safelist: ["is-open", "js-*"],
+//  }
+// the string literals of the script bundle are also scanned for class names
+type PurgeConfig struct {
+  enabled       bool
+  safelist      []*tokens.String
+  scriptClasses tree.ClassMap
+}
+
+var (
+  PURGE_JS_STRING_REGEXP = regexp.MustCompile("\"(?:[^\"\\\\\\n]|\\\\.)*\"|'(?:[^'\\\\\\n]|\\\\.)*'|`(?:[^`\\\\]|\\\\.)*`")
+  PURGE_CLASS_REGEXP     = regexp.MustCompile(`-?[_a-zA-Z][_a-zA-Z0-9\-]*`)
+)
+
+func readPurge(purge *tokens.StringDict) (*PurgeConfig, error) {
+  if err := purge.AssertOnlyValidKeys([]string{"safelist"}); err != nil {
+    return nil, err
+  }
+
+  res := &PurgeConfig{false, make([]*tokens.String, 0), nil}
+
+  if safelist_, ok := purge.Get("safelist"); ok {
+    safelist, err := stringList(safelist_)
+    if err != nil {
+      return nil, err
+    }
+
+    for _, class := range safelist {
+      if _, err := path.Match(class.Value(), ""); err != nil {
+        errCtx := class.Context()
+        return nil, errCtx.NewError("Error: bad pattern")
+      }
+    }
+
+    res.safelist = safelist
+  }
+
+  return res, nil
+}
+
+// the classes of the script bundle are collected, and the safelist is cross-checked against them
+func (pc *PurgeConfig) registerScriptClasses(bundleDst string) {
+  pc.scriptClasses = tree.NewClassMap()
+
+  content, err := ioutil.ReadFile(bundleDst)
+  if err != nil {
+    // no scripts
+    return
+  }
+
+  ctx := context.NewDummyContext()
+  for _, literal := range PURGE_JS_STRING_REGEXP.FindAllString(string(content), -1) {
+    for _, class := range PURGE_CLASS_REGEXP.FindAllString(literal[1:len(literal)-1], -1) {
+      if !pc.scriptClasses.HasScriptClass(class) {
+        pc.scriptClasses.AppendScriptClass(tokens.NewValueString(class, ctx))
+      }
+    }
+  }
+
+  for _, class := range pc.safelist {
+    if !strings.Contains(class.Value(), "*") && !pc.scriptClasses.HasScriptClass(class.Value()) {
+      errCtx := class.Context()
+      fmt.Fprintln(os.Stderr, errCtx.NewError("Warning: safelisted class not found in the scripts").Error())
+    }
+  }
+}
+
+func (pc *PurgeConfig) keepClass(class string) bool {
+  if pc.scriptClasses != nil && pc.scriptClasses.HasScriptClass(class) {
+    return true
+  }
+
+  for _, pattern := range pc.safelist {
+    if ok, _ := path.Match(pattern.Value(), class); ok {
+      return true
+    }
+  }
+
+  return false
+}
+
+// parameter of the style sheet dst, so switching between purged and complete sheets triggers a rebuild
+func (pc *PurgeConfig) String() string {
+  if !pc.enabled {
+    return ""
+  }
+
+  names := make([]string, len(pc.safelist))
+  for i, class := range pc.safelist {
+    names[i] = class.Value()
+  }
+
+  return "purge:[" + strings.Join(names, ",") + "]"
+}
+
+// purges and writes the sheets, roots are the trees of the pages, by page url
+func (cfg *SiteConfig) purgeStyles(roots map[string]*tree.Root) error {
+  cfg.Purge.registerScriptClasses(cfg.JSDst())
+
+  for _, style := range cfg.Styles {
+    pageRoots := make([]*tree.Root, 0)
+    for _, pURL := range style.pages {
+      if r, ok := roots[pURL]; ok {
+        pageRoots = append(pageRoots, r)
+      }
+    }
+
+    sheet, err := cfg.styleSheet(style.url)
+    if err != nil {
+      return err
+    }
+
+    purged, err := sheet.Purge(pageRoots, cfg.Purge.keepClass)
+    if err != nil {
+      return err
+    }
+
+    if VERBOSITY > 0 {
+      fmt.Fprintf(os.Stdout, "%s: kept %d of %d rules\n", style.url, purged.Len(), sheet.Len())
+    }
+
+    if err := styles.WriteSheetToFile(purged, style.dst); err != nil {
+      return err
+    }
+
+    if err := cfg.hashAsset(style.url); err != nil {
+      return err
+    }
+  }
+
+  return nil
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+  "crypto/sha256"
+  "crypto/sha512"
+  "encoding/base64"
+  "errors"
+  "html"
+  "io/ioutil"
+  "path/filepath"
+  "regexp"
+  "sort"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/files"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// subresource integrity of the linked bundle and style sheets, and a content security policy that allows the inline
+// scripts and styles of each page by hash, eg.:
+//  security: {
+//    integrity: true,
+//    csp: "meta", // or "headers", for a _headers file (Netlify/Cloudflare Pages format)
+//    headers: "_headers",
+//    policy: {"img-src": ["'self'", "data:"]}, // overrides the default directives, hashes are appended to script-src and style-src
+//  }
+type SecurityConfig struct {
+  integrity  bool
+  csp        string // "", "meta" or "headers"
+  headersDst string
+  policy     map[string][]string
+}
+
+var CSP_DEFAULT_POLICY = map[string][]string{
+  "default-src": []string{"'self'"},
+  "script-src":  []string{"'self'"},
+  "style-src":   []string{"'self'"},
+  "object-src":  []string{"'none'"},
+  "base-uri":    []string{"'self'"},
+}
+
+var (
+  CSP_SCRIPT_REGEXP     = regexp.MustCompile(`(?s)<script([^>]*)>(.*?)</script>`)
+  CSP_STYLE_REGEXP      = regexp.MustCompile(`(?s)<style[^>]*>(.*?)</style>`)
+  CSP_STYLE_ATTR_REGEXP = regexp.MustCompile(`\sstyle="([^"]*)"`)
+  CSP_EVENT_ATTR_REGEXP = regexp.MustCompile(`\son[a-z]+="([^"]*)"`)
+  CSP_SRC_ATTR_REGEXP   = regexp.MustCompile(`\ssrc=`)
+)
+
+func readSecurity(outputDir string, security *tokens.StringDict) (*SecurityConfig, error) {
+  if err := security.AssertOnlyValidKeys([]string{"integrity", "csp", "headers", "policy"}); err != nil {
+    return nil, err
+  }
+
+  res := &SecurityConfig{false, "", "", make(map[string][]string)}
+
+  if _, ok := security.Get("integrity"); ok {
+    integrity, err := tokens.DictBool(security, "integrity")
+    if err != nil {
+      return nil, err
+    }
+
+    res.integrity = integrity.Value()
+  }
+
+  if _, ok := security.Get("csp"); ok {
+    csp, err := tokens.DictString(security, "csp")
+    if err != nil {
+      return nil, err
+    }
+
+    switch csp.Value() {
+    case "meta", "headers":
+      res.csp = csp.Value()
+    default:
+      errCtx := csp.Context()
+      return nil, errCtx.NewError("Error: expected \"meta\" or \"headers\"")
+    }
+  }
+
+  headersToken := tokens.NewValueString("_headers", security.Context())
+  if _, ok := security.Get("headers"); ok {
+    var err error
+    headersToken, err = tokens.DictString(security, "headers")
+    if err != nil {
+      return nil, err
+    }
+
+    if res.csp != "headers" {
+      errCtx := headersToken.Context()
+      return nil, errCtx.NewError("Error: only used with csp: \"headers\"")
+    }
+  }
+
+  var err error
+  res.headersDst, _, err = parseDstURL(outputDir, headersToken)
+  if err != nil {
+    return nil, err
+  }
+
+  for k, v := range CSP_DEFAULT_POLICY {
+    res.policy[k] = v
+  }
+
+  if policy_, ok := security.Get("policy"); ok {
+    policy, err := tokens.AssertStringDict(policy_)
+    if err != nil {
+      return nil, err
+    }
+
+    if err := policy.Loop(func(key *tokens.String, value_ tokens.Token, last bool) error {
+      lst, err := stringList(value_)
+      if err != nil {
+        return err
+      }
+
+      sources := make([]string, len(lst))
+      for i, s := range lst {
+        sources[i] = s.Value()
+      }
+
+      res.policy[key.Value()] = sources
+      return nil
+    }); err != nil {
+      return nil, err
+    }
+  }
+
+  return res, nil
+}
+
+// for the page parameter string
+func (sc *SecurityConfig) String() string {
+  var b strings.Builder
+
+  b.WriteString("integrity:")
+  if sc.integrity {
+    b.WriteString("true")
+  } else {
+    b.WriteString("false")
+  }
+
+  b.WriteString(",csp:")
+  b.WriteString(sc.csp)
+  b.WriteString(",policy:")
+  b.WriteString(sc.writePolicy(nil, nil))
+
+  return b.String()
+}
+
+func cspHash(content string) string {
+  sum := sha256.Sum256([]byte(content))
+  return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
+
+func appendUnique(lst []string, s string) []string {
+  for _, x := range lst {
+    if x == s {
+      return lst
+    }
+  }
+
+  return append(lst, s)
+}
+
+func (sc *SecurityConfig) writePolicy(scriptHashes []string, styleHashes []string) string {
+  keys := make([]string, 0)
+  for k, _ := range sc.policy {
+    keys = append(keys, k)
+  }
+
+  sort.Strings(keys)
+
+  directives := make([]string, 0)
+  for _, k := range keys {
+    sources := sc.policy[k]
+
+    switch k {
+    case "script-src":
+      sources = append(append([]string{}, sources...), scriptHashes...)
+    case "style-src":
+      sources = append(append([]string{}, sources...), styleHashes...)
+    }
+
+    directives = append(directives, k + " " + strings.Join(sources, " "))
+  }
+
+  return strings.Join(directives, "; ")
+}
+
+// the policy for a written page, with the hashes of its inline scripts, styles, and event handler and style attributes
+func (sc *SecurityConfig) PagePolicy(content string) string {
+  scriptHashes := make([]string, 0)
+  styleHashes := make([]string, 0)
+
+  for _, m := range CSP_SCRIPT_REGEXP.FindAllStringSubmatch(content, -1) {
+    if !CSP_SRC_ATTR_REGEXP.MatchString(m[1]) {
+      scriptHashes = appendUnique(scriptHashes, cspHash(m[2]))
+    }
+  }
+
+  for _, m := range CSP_STYLE_REGEXP.FindAllStringSubmatch(content, -1) {
+    styleHashes = appendUnique(styleHashes, cspHash(m[1]))
+  }
+
+  // attributes require 'unsafe-hashes'
+  attrHashes := func(re *regexp.Regexp, hashes []string) []string {
+    attrs := re.FindAllStringSubmatch(content, -1)
+    if len(attrs) == 0 {
+      return hashes
+    }
+
+    hashes = appendUnique(hashes, "'unsafe-hashes'")
+    for _, m := range attrs {
+      hashes = appendUnique(hashes, cspHash(html.UnescapeString(m[1])))
+    }
+
+    return hashes
+  }
+
+  scriptHashes = attrHashes(CSP_EVENT_ATTR_REGEXP, scriptHashes)
+  styleHashes = attrHashes(CSP_STYLE_ATTR_REGEXP, styleHashes)
+
+  return sc.writePolicy(scriptHashes, styleHashes)
+}
+
+// subresource integrity of a generated asset (plain url)
+func (cfg *SiteConfig) AssetIntegrity(url string) (string, error) {
+  if integrity, ok := cfg.integrities[url]; ok {
+    return integrity, nil
+  }
+
+  content, err := ioutil.ReadFile(cfg.AssetDst(url))
+  if err != nil {
+    return "", errors.New("Error: " + err.Error())
+  }
+
+  sum := sha512.Sum384(content)
+  integrity := "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+
+  cfg.integrities[url] = integrity
+
+  return integrity, nil
+}
+
+// one entry per page, also for the directory url of index pages, eg.:
+//  /blog/index.html
+//    Content-Security-Policy: ...
+func (cfg *SiteConfig) buildHeaders() error {
+  var b strings.Builder
+
+  for _, page := range cfg.Pages {
+    content, err := ioutil.ReadFile(page.dst)
+    if err != nil {
+      return errors.New("Error: " + err.Error())
+    }
+
+    policy := cfg.Security.PagePolicy(string(content))
+
+    url := cleanURL(page.url)
+    urls := []string{url}
+    if filepath.Base(url) == "index.html" {
+      urls = append([]string{strings.TrimSuffix(url, "index.html")}, urls...)
+    }
+
+    for _, u := range urls {
+      b.WriteString(u)
+      b.WriteString("\n  Content-Security-Policy: ")
+      b.WriteString(policy)
+      b.WriteString("\n")
+    }
+  }
+
+  return files.WriteFile(cfg.configFile, cfg.Security.headersDst, []byte(b.String()))
+}
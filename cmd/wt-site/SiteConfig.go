@@ -10,6 +10,7 @@ import (
 
 	"github.com/wtsuite/wtsuite/pkg/directives"
 	"github.com/wtsuite/wtsuite/pkg/files"
+	"github.com/wtsuite/wtsuite/pkg/git"
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
 	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
 	"github.com/wtsuite/wtsuite/pkg/tokens/raw"
@@ -22,7 +23,13 @@ type PageConfig struct {
   url    string
   dst    string
   src    string
+  layout string // only for markdown pages, can be overridden in the front-matter
   params []string
+  deps   []string // extra dependencies (eg. the items of a collection index)
+  collection *CollectionConfig // only for collection index pages
+  index  int
+  locale string // only for multilingual sites
+  localeURLs map[string]string // urls of the same page in every locale
 }
 
 type ScriptConfig struct {
@@ -48,10 +55,25 @@ type FileConfig struct {
 
 type SiteConfig struct {
   outputDir string
+  configFile string
+  URL     string // absolute base url, required by sitemap and feeds
   Pages   []PageConfig
   Scripts []ScriptConfig 
   Styles  []StyleConfig 
   Files   []FileConfig
+  Sitemap *SitemapConfig
+  Robots  *RobotsConfig
+  Feeds   []FeedConfig
+  Locales []LocaleConfig // sorted, empty for single language sites
+  DefaultLocale string
+  Security *SecurityConfig
+  Purge   *PurgeConfig // not nil, only enabled by --purge-css
+  Critical *CriticalConfig
+  Fonts   []FontConfig // registered while building the styles
+  activeFonts []string // srcs of the fonts referenced by the sheet that is being built
+  assetURLs map[string]string // plain url -> content hashed url, nil unless --hash-assets
+  integrities map[string]string // plain url -> subresource integrity
+  commitTimes *git.CommitTimes // opened by the first git lastmod lookup of the build
   //Search  []search.SearchIndexConfig
 }
 
@@ -122,10 +144,24 @@ func ReadConfigFile(fname string, outputDir string) (*SiteConfig, error) {
 
   cfg := &SiteConfig {
     outputDir: outputDir,
+    configFile: fname,
+    URL: "",
     Pages: nil,
     Scripts: make([]ScriptConfig, 0),
     Styles: make([]StyleConfig, 0),
     Files: make([]FileConfig, 0),
+    Sitemap: nil,
+    Robots: nil,
+    Feeds: make([]FeedConfig, 0),
+    Locales: make([]LocaleConfig, 0),
+    DefaultLocale: "",
+    Security: nil,
+    Purge: &PurgeConfig{false, make([]*tokens.String, 0), nil},
+    Critical: nil,
+    Fonts: make([]FontConfig, 0),
+    activeFonts: nil,
+    assetURLs: nil,
+    integrities: make(map[string]string),
     //Search: make([]search.SearchIndexConfig),
   }
 
@@ -145,6 +181,20 @@ func ReadConfigFile(fname string, outputDir string) (*SiteConfig, error) {
     return nil, err
   }
 
+  if collections_, ok := t.Get("collections"); ok {
+    collections, err := tokens.AssertStringDict(collections_)
+    if err != nil {
+      return nil, err
+    }
+
+    collectionPages, err := readCollections(fname, outputDir, collections, cfg.Pages)
+    if err != nil {
+      return nil, err
+    }
+
+    cfg.Pages = append(cfg.Pages, collectionPages...)
+  }
+
   if files_, ok := t.Get("files"); ok {
     files__, err := tokens.AssertStringDict(files_)
     if err != nil {
@@ -181,9 +231,112 @@ func ReadConfigFile(fname string, outputDir string) (*SiteConfig, error) {
     }
   }
 
+  if i18n_, ok := t.Get("i18n"); ok {
+    i18n, err := tokens.AssertStringDict(i18n_)
+    if err != nil {
+      return nil, err
+    }
+
+    cfg.Locales, cfg.DefaultLocale, err = readLocales(fname, i18n)
+    if err != nil {
+      return nil, err
+    }
+
+    cfg.expandLocales()
+  }
+
+  if _, ok := t.Get("url"); ok {
+    cfg.URL, err = readSiteURL(t)
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  if sitemap_, ok := t.Get("sitemap"); ok {
+    sitemap, err := tokens.AssertStringDict(sitemap_)
+    if err != nil {
+      return nil, err
+    }
+
+    if cfg.URL == "" {
+      errCtx := sitemap.Context()
+      return nil, errCtx.NewError("Error: sitemap requires the site url")
+    }
+
+    cfg.Sitemap, err = readSitemap(outputDir, sitemap)
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  if robots_, ok := t.Get("robots"); ok {
+    robots, err := tokens.AssertStringDict(robots_)
+    if err != nil {
+      return nil, err
+    }
+
+    cfg.Robots, err = readRobots(outputDir, robots)
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  if feeds_, ok := t.Get("feeds"); ok {
+    feeds, err := tokens.AssertStringDict(feeds_)
+    if err != nil {
+      return nil, err
+    }
+
+    if cfg.URL == "" {
+      errCtx := feeds.Context()
+      return nil, errCtx.NewError("Error: feeds require the site url")
+    }
+
+    cfg.Feeds, err = readFeeds(outputDir, feeds, cfg.Pages)
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  if security_, ok := t.Get("security"); ok {
+    security, err := tokens.AssertStringDict(security_)
+    if err != nil {
+      return nil, err
+    }
+
+    cfg.Security, err = readSecurity(outputDir, security)
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  if purge_, ok := t.Get("purge"); ok {
+    purge, err := tokens.AssertStringDict(purge_)
+    if err != nil {
+      return nil, err
+    }
+
+    cfg.Purge, err = readPurge(purge)
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  if critical_, ok := t.Get("critical"); ok {
+    critical, err := tokens.AssertStringDict(critical_)
+    if err != nil {
+      return nil, err
+    }
+
+    cfg.Critical, err = readCritical(critical)
+    if err != nil {
+      return nil, err
+    }
+  }
+
   if err := t.Loop(func(key *tokens.String, _ tokens.Token, last bool) error {
     switch key.Value(){
-    case "pages", "files", "search", "styles", "scripts":
+    case "url", "pages", "collections", "files", "search", "styles", "scripts", "i18n", "sitemap", "robots", "feeds", "security", "purge", "critical":
       return nil
     default:
       errCtx := key.Context()
@@ -210,6 +363,18 @@ func readPages(configFile string, outputDir string, pages *tokens.StringDict) ([
     var src string
     params := make([]string, 0)
 
+    if tokens.IsStringDict(value_) {
+      page, err := readMarkdownPage(configFile, value_)
+      if err != nil {
+        return err
+      }
+
+      page.dst = dst
+      page.url = url
+      res = append(res, page)
+      return nil
+    }
+
     args, err := stringList(value_)
     if err != nil {
       return err
@@ -245,6 +410,50 @@ func readPages(configFile string, outputDir string, pages *tokens.StringDict) ([
   return res, nil
 }
 
+// {src: "./post.md", layout: "./layout.thtml"}
+func readMarkdownPage(configFile string, value_ tokens.Token) (PageConfig, error) {
+  value, err := tokens.AssertStringDict(value_)
+  if err != nil {
+    return PageConfig{}, err
+  }
+
+  if err := value.AssertOnlyValidKeys([]string{"src", "layout"}); err != nil {
+    return PageConfig{}, err
+  }
+
+  srcToken, err := tokens.DictString(value, "src")
+  if err != nil {
+    return PageConfig{}, err
+  }
+
+  src, err := files.Search(configFile, srcToken.Value())
+  if err != nil {
+    errCtx := srcToken.Context()
+    return PageConfig{}, errCtx.NewError(err.Error())
+  }
+
+  if !directives.IsMarkdownFile(src) {
+    errCtx := srcToken.Context()
+    return PageConfig{}, errCtx.NewError("Error: layouts can only be applied to markdown sources")
+  }
+
+  layout := ""
+  if _, ok := value.Get("layout"); ok {
+    layoutToken, err := tokens.DictString(value, "layout")
+    if err != nil {
+      return PageConfig{}, err
+    }
+
+    layout, err = files.Search(configFile, layoutToken.Value())
+    if err != nil {
+      errCtx := layoutToken.Context()
+      return PageConfig{}, errCtx.NewError(err.Error())
+    }
+  }
+
+  return PageConfig{src: src, layout: layout, params: []string{}}, nil
+}
+
 func readScripts(configFile string, outputDir string, scripts *tokens.StringDict, pages []PageConfig) ([]ScriptConfig, error) {
   res := make([]ScriptConfig, 0)
 
@@ -538,6 +747,12 @@ func (cfg *SiteConfig) PageScripts(url string) []string {
   return res2
 }
 
+// the bundle and the style sheets must be built before the pages if the pages refer to them by content
+// the purge needs the classes of the bundle
+func (cfg *SiteConfig) AssetsBeforePages() bool {
+  return cfg.assetURLs != nil || (cfg.Security != nil && cfg.Security.integrity) || cfg.Purge.enabled
+}
+
 func (cfg *SiteConfig) FindStyle(cssURL string) StyleConfig {
   for _, s := range cfg.Styles {
     if s.url == cssURL {
@@ -559,8 +774,12 @@ func (cfg *SiteConfig) FindPage(pgURL string) PageConfig {
 }
 
 func (cfg *SiteConfig) PageParameterString(pgURL string) string {
-  styleURLs := cfg.PageStyles(pgURL)
-  scriptBundleURL := cfg.JSURL()
+  styleURLs := make([]string, 0)
+  for _, styleURL := range cfg.PageStyles(pgURL) {
+    styleURLs = append(styleURLs, cfg.AssetURL(styleURL))
+  }
+
+  scriptBundleURL := cfg.AssetURL(cfg.JSURL())
   scriptHashes := cfg.PageScripts(pgURL)
 
   page := cfg.FindPage(pgURL)
@@ -581,10 +800,38 @@ func (cfg *SiteConfig) PageParameterString(pgURL string) string {
 
   b.WriteString("{")
   writeList("parameters", page.params)
+  b.WriteString(",layout:")
+  b.WriteString(page.layout)
+  writeList(",deps", page.deps)
+  b.WriteString(",locale:")
+  b.WriteString(page.locale)
+  writeList(",locales", cfg.LocaleNames())
   writeList(",styles", styleURLs)
   writeList(",scripts", scriptHashes)
   b.WriteString(",scriptBundle:")
   b.WriteString(scriptBundleURL)
+
+  if cfg.Security != nil {
+    b.WriteString(",security:{")
+    b.WriteString(cfg.Security.String())
+    b.WriteString("}")
+  }
+
+  if cfg.Critical != nil {
+    b.WriteString(",critical:{")
+    b.WriteString(cfg.Critical.String())
+    b.WriteString("}")
+  }
+
+  // pages referring to a changed file must be rebuilt
+  if cfg.assetURLs != nil {
+    fileURLs := make([]string, len(cfg.Files))
+    for i, f := range cfg.Files {
+      fileURLs[i] = f.url
+    }
+
+    writeList(",files", fileURLs)
+  }
   b.WriteString("}")
 
   return b.String()
@@ -618,10 +865,32 @@ func (cfg *SiteConfig) CleanOutput() error {
 
   for _, s := range cfg.Styles {
     keep(s.dst)
+    keep(cfg.AssetDst(s.url))
+  }
+
+  for _, f := range cfg.Fonts {
+    keep(f.dst)
+  }
+
+  if cfg.Sitemap != nil {
+    keep(cfg.Sitemap.dst)
+  }
+
+  if cfg.Robots != nil {
+    keep(cfg.Robots.dst)
+  }
+
+  for _, feed := range cfg.Feeds {
+    keep(feed.dst)
+  }
+
+  if cfg.Security != nil && cfg.Security.csp == "headers" {
+    keep(cfg.Security.headersDst)
   }
 
   keep(cfg.JSDst())
-  keep(cfg.MathFontDst())
+  keep(cfg.AssetDst(cfg.JSURL()))
+  keep(cfg.AssetDst(cfg.MathFontURL()))
 
   toRemove := make([]string, 0)
 
@@ -0,0 +1,302 @@
+package main
+
+import (
+  "encoding/xml"
+  "os"
+  "path"
+  "sort"
+  "strings"
+  "time"
+
+	"github.com/wtsuite/wtsuite/pkg/files"
+	"github.com/wtsuite/wtsuite/pkg/git"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// sitemap: {dst: "sitemap.xml", lastmod: "git", exclude: ["404.html", "drafts/*"]}
+// lastmod is "mtime" (default) or "git", the latter falls back to mtime for uncommitted sources
+type SitemapConfig struct {
+  dst     string
+  url     string
+  lastmod string
+  exclude []string // globs of page urls, without leading slash
+}
+
+// robots: {dst: "robots.txt", userAgent: "*", disallow: ["/private/"], allow: []}
+type RobotsConfig struct {
+  dst       string
+  userAgent string
+  disallow  []string
+  allow     []string
+}
+
+// absolute url that is used in sitemaps and feeds, index.html is implied
+func absoluteURL(base string, url string) string {
+  url = cleanURL(url)
+  if strings.HasSuffix(url, "/index.html") {
+    url = strings.TrimSuffix(url, "index.html")
+  }
+
+  return strings.TrimSuffix(base, "/") + url
+}
+
+func readSiteURL(t *tokens.StringDict) (string, error) {
+  urlToken, err := tokens.DictString(t, "url")
+  if err != nil {
+    return "", err
+  }
+
+  if !(strings.HasPrefix(urlToken.Value(), "http://") || strings.HasPrefix(urlToken.Value(), "https://")) {
+    errCtx := urlToken.Context()
+    return "", errCtx.NewError("Error: expected absolute http(s) url")
+  }
+
+  return strings.TrimSuffix(urlToken.Value(), "/"), nil
+}
+
+func readOptionalString(d *tokens.StringDict, key string, def string) (string, error) {
+  if _, ok := d.Get(key); !ok {
+    return def, nil
+  }
+
+  t, err := tokens.DictString(d, key)
+  if err != nil {
+    return "", err
+  }
+
+  return t.Value(), nil
+}
+
+func readOptionalStringList(d *tokens.StringDict, key string) ([]string, error) {
+  res := make([]string, 0)
+
+  t, ok := d.Get(key)
+  if !ok {
+    return res, nil
+  }
+
+  lst, err := stringList(t)
+  if err != nil {
+    return nil, err
+  }
+
+  for _, s := range lst {
+    res = append(res, s.Value())
+  }
+
+  return res, nil
+}
+
+func readLastModMode(d *tokens.StringDict) (string, error) {
+  mode, err := readOptionalString(d, "lastmod", "mtime")
+  if err != nil {
+    return "", err
+  }
+
+  switch mode {
+  case "mtime", "git":
+    return mode, nil
+  default:
+    t, _ := d.Get("lastmod")
+    errCtx := t.Context()
+    return "", errCtx.NewError("Error: expected \"mtime\" or \"git\"")
+  }
+}
+
+func readSitemap(outputDir string, sitemap *tokens.StringDict) (*SitemapConfig, error) {
+  if err := sitemap.AssertOnlyValidKeys([]string{"dst", "lastmod", "exclude"}); err != nil {
+    return nil, err
+  }
+
+  dstToken := tokens.NewValueString("sitemap.xml", sitemap.Context())
+  if _, ok := sitemap.Get("dst"); ok {
+    var err error
+    dstToken, err = tokens.DictString(sitemap, "dst")
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  dst, url, err := parseDstURL(outputDir, dstToken)
+  if err != nil {
+    return nil, err
+  }
+
+  lastmod, err := readLastModMode(sitemap)
+  if err != nil {
+    return nil, err
+  }
+
+  exclude, err := readOptionalStringList(sitemap, "exclude")
+  if err != nil {
+    return nil, err
+  }
+
+  for i, pattern := range exclude {
+    exclude[i] = strings.TrimPrefix(pattern, "/")
+
+    if _, err := path.Match(exclude[i], ""); err != nil {
+      errCtx := sitemap.Context()
+      return nil, errCtx.NewError("Error: bad exclude pattern \"" + pattern + "\"")
+    }
+  }
+
+  return &SitemapConfig{dst, url, lastmod, exclude}, nil
+}
+
+func readRobots(outputDir string, robots *tokens.StringDict) (*RobotsConfig, error) {
+  if err := robots.AssertOnlyValidKeys([]string{"dst", "userAgent", "disallow", "allow"}); err != nil {
+    return nil, err
+  }
+
+  dstToken := tokens.NewValueString("robots.txt", robots.Context())
+  if _, ok := robots.Get("dst"); ok {
+    var err error
+    dstToken, err = tokens.DictString(robots, "dst")
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  dst, _, err := parseDstURL(outputDir, dstToken)
+  if err != nil {
+    return nil, err
+  }
+
+  userAgent, err := readOptionalString(robots, "userAgent", "*")
+  if err != nil {
+    return nil, err
+  }
+
+  disallow, err := readOptionalStringList(robots, "disallow")
+  if err != nil {
+    return nil, err
+  }
+
+  allow, err := readOptionalStringList(robots, "allow")
+  if err != nil {
+    return nil, err
+  }
+
+  return &RobotsConfig{dst, userAgent, disallow, allow}, nil
+}
+
+// most recent modification of any of the page sources
+func (cfg *SiteConfig) PageLastMod(page PageConfig, mode string) time.Time {
+  srcs := []string{page.src}
+  if page.layout != "" {
+    srcs = append(srcs, page.layout)
+  }
+
+  srcs = append(srcs, page.deps...)
+
+  var res time.Time
+  for _, src := range srcs {
+    var t time.Time
+    if mode == "git" {
+      if cfg.commitTimes == nil {
+        cfg.commitTimes = git.NewCommitTimes()
+      }
+
+      if t_, err := cfg.commitTimes.LastCommitTime(src); err == nil {
+        t = t_
+      }
+    }
+
+    if t.IsZero() {
+      if info, err := os.Stat(src); err == nil {
+        t = info.ModTime()
+      }
+    }
+
+    if t.After(res) {
+      res = t
+    }
+  }
+
+  return res
+}
+
+func writeXMLText(b *strings.Builder, s string) {
+  if err := xml.EscapeText(b, []byte(s)); err != nil {
+    panic(err)
+  }
+}
+
+func (cfg *SiteConfig) buildSitemap() error {
+  sm := cfg.Sitemap
+
+  pages := make([]PageConfig, 0)
+  for _, page := range cfg.Pages {
+    excluded := false
+    for _, pattern := range sm.exclude {
+      if ok, _ := path.Match(pattern, strings.TrimPrefix(page.url, "/")); ok {
+        excluded = true
+        break
+      }
+    }
+
+    if !excluded {
+      pages = append(pages, page)
+    }
+  }
+
+  sort.SliceStable(pages, func(i, j int) bool {
+    return pages[i].url < pages[j].url
+  })
+
+  var b strings.Builder
+  b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+  b.WriteString("<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n")
+
+  for _, page := range pages {
+    b.WriteString("  <url>\n    <loc>")
+    writeXMLText(&b, absoluteURL(cfg.URL, page.url))
+    b.WriteString("</loc>\n")
+
+    if t := cfg.PageLastMod(page, sm.lastmod); !t.IsZero() {
+      b.WriteString("    <lastmod>")
+      b.WriteString(t.UTC().Format("2006-01-02"))
+      b.WriteString("</lastmod>\n")
+    }
+
+    b.WriteString("  </url>\n")
+  }
+
+  b.WriteString("</urlset>\n")
+
+  return files.WriteFile(cfg.configFile, sm.dst, []byte(b.String()))
+}
+
+func (cfg *SiteConfig) buildRobots() error {
+  r := cfg.Robots
+
+  var b strings.Builder
+  b.WriteString("User-agent: ")
+  b.WriteString(r.userAgent)
+  b.WriteString("\n")
+
+  if len(r.disallow) == 0 {
+    b.WriteString("Disallow:\n")
+  }
+
+  for _, p := range r.disallow {
+    b.WriteString("Disallow: ")
+    b.WriteString(p)
+    b.WriteString("\n")
+  }
+
+  for _, p := range r.allow {
+    b.WriteString("Allow: ")
+    b.WriteString(p)
+    b.WriteString("\n")
+  }
+
+  if cfg.Sitemap != nil {
+    b.WriteString("\nSitemap: ")
+    b.WriteString(absoluteURL(cfg.URL, cfg.Sitemap.url))
+    b.WriteString("\n")
+  }
+
+  return files.WriteFile(cfg.configFile, r.dst, []byte(b.String()))
+}
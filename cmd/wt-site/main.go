@@ -35,12 +35,19 @@ var (
 type CmdArgs struct {
   configFile     string
   outputDir      string
+  browsers       string
   globals        map[string]string
 
   compactOutput  bool
   forceRebuild   bool
   autoDownload   bool
   clean          bool
+  checkLinks     bool
+  a11y           bool
+  hashAssets     bool
+  purgeCSS       bool
+  minifyCSS      bool
+  minifyCSSUnsafe bool
 
   profFile       string
   verbosity      int
@@ -60,11 +67,18 @@ func parseArgs() CmdArgs {
   cmdArgs := CmdArgs{
     configFile:    "site-config.thtml",
     outputDir:     "./www",
+    browsers:      "",
     globals:       make(map[string]string),
     compactOutput: false,
     forceRebuild:  false,
     autoDownload:  false,
     clean:         false,
+    checkLinks:    false,
+    a11y:          false,
+    hashAssets:    false,
+    purgeCSS:      false,
+    minifyCSS:     false,
+    minifyCSSUnsafe: false,
     profFile:      "",
     verbosity:     0,
   }
@@ -82,6 +96,14 @@ func parseArgs() CmdArgs {
       parsers.NewCLIUniqueFlag("f", "force",        "-f, --force      Force a complete build", &(cmdArgs.forceRebuild)),
       parsers.NewCLIUniqueFlag("", "auto-download", "--auto-download  Automatically download missing packages. Doesnt update!", &(cmdArgs.autoDownload)),
       parsers.NewCLIUniqueFlag("", "clean", "--clean  Delete files in dst directory that are not a result of this build", &(cmdArgs.clean)),
+      parsers.NewCLIUniqueFlag("", "check-links", "--check-links  Check internal links and #fragments (all pages are rebuilt)", &(cmdArgs.checkLinks)),
+      parsers.NewCLIUniqueFlag("", "a11y", "--a11y  Lint the generated pages for accessibility problems (all pages are rebuilt)", &(cmdArgs.a11y)),
+      parsers.NewCLIUniqueFlag("", "hash-assets", "--hash-assets  Name the script bundle, style sheets, math font and copied files by content hash", &(cmdArgs.hashAssets)),
+      parsers.NewCLIUniqueFlag("", "purge-css", "--purge-css  Drop the style rules that don't match any element of the pages linking the sheet (all pages are rebuilt)", &(cmdArgs.purgeCSS)),
+      parsers.NewCLIUniqueFlag("", "minify-css", "--minify-css  Merge rules, collapse shorthands, drop overridden declarations, and shorten colors and numbers in the styles", &(cmdArgs.minifyCSS)),
+      parsers.NewCLIUniqueFlag("", "minify-css-unsafe", "--minify-css-unsafe  Like --minify-css, but also moves rules past rules with a different specificity", &(cmdArgs.minifyCSSUnsafe)),
+      parsers.NewCLIUniqueString("", "browsers", "--browsers <query>  Targeted browsers, eg. \"last 2 versions, safari 12\" (adds vendor prefixes and lowers modern properties)", &(cmdArgs.browsers)),
+      parsers.NewCLIUniqueFlag("", "html-warnings", "--html-warnings  Report HTML content model violations as warnings instead of errors", &(tree.CONTENT_MODEL_WARNINGS)),
       parsers.NewCLIUniqueFlag("l", "latest"           , "-l, --latest                  Ignore max semver, use latest tagged versions of dependencies", &(files.LATEST)),
       parsers.NewCLICountFlag("v" , ""                 , "-v[v[v..]]                    Verbosity", &(cmdArgs.verbosity)),
       parsers.NewCLIUniqueKeyValue("D"                 , "-D<name> <value>              Define a global variable with a string value", cmdArgs.globals),
@@ -171,6 +193,30 @@ func setUpEnv(cmdArgs CmdArgs) error {
   b.WriteString(",version:")
   b.WriteString(VERSION)
 
+  // changing the targeted browsers rebuilds everything
+  if cmdArgs.browsers != "" {
+    browsers, err := styles.ParseBrowsers(cmdArgs.browsers)
+    if err != nil {
+      return err
+    }
+
+    styles.BROWSERS = browsers
+
+    b.WriteString(",browsers:")
+    b.WriteString(browsers.String())
+  }
+
+  if cmdArgs.minifyCSS || cmdArgs.minifyCSSUnsafe {
+    styles.MINIFY = true
+    styles.MINIFY_UNSAFE = cmdArgs.minifyCSSUnsafe
+
+    if styles.MINIFY_UNSAFE {
+      b.WriteString(",minify-css:unsafe")
+    } else {
+      b.WriteString(",minify-css:safe")
+    }
+  }
+
 	directives.ForceNewViewFileScriptRegistration(directives.NewFileCache())
 
 	VERBOSITY = cmdArgs.verbosity
@@ -295,82 +341,338 @@ func buildSiteStyles(cfg *SiteConfig, cmdArgs CmdArgs) error {
       }
     }
 
-    if files.RequiresDepUpdate(style.dst, "") || somePagesNeedUpdate {
-      files.StartDstUpdate(style.dst, "")
+    // pages that are rebuilt anyway need the sheet
+    parameters := cfg.Purge.String()
+    // --clean needs all the fonts
+    if files.RequiresDepUpdate(style.dst, parameters) || somePagesNeedUpdate || cmdArgs.checkLinks || cmdArgs.a11y || cfg.Purge.enabled || cmdArgs.clean {
+      files.StartDstUpdate(style.dst, parameters)
       files.AddDep(style.dst, style.src)
 
+      cfg.activeFonts = make([]string, 0)
       sheet, err := styles.Build(style.src, context.NewDummyContext())
       if err != nil {
         return err
       }
 
-      if err = styles.WriteSheetToFile(sheet, style.dst); err != nil {
-        return err
+      // font urls depend on the content with --hash-assets
+      for _, fontSrc := range cfg.activeFonts {
+        files.AddDep(style.dst, fontSrc)
       }
 
       // save back in config for use by pages
       style.sheet = sheet
       cfg.Styles[i] = style
+
+      // purged sheets are written once all the pages are built
+      if cfg.Purge.enabled {
+        continue
+      }
+
+      if err = styles.WriteSheetToFile(sheet, style.dst); err != nil {
+        return err
+      }
+    }
+
+    if err := cfg.hashAsset(style.url); err != nil {
+      return err
     }
   }
 
   return nil
 }
 
-func buildSitePages(cfg *SiteConfig, cmdArgs CmdArgs) error {
-  cache := directives.NewFileCache()
+// the sheet is built here if the style itself didn't need an update
+func (cfg *SiteConfig) styleSheet(cssURL string) (styles.Sheet, error) {
+  for i, s := range cfg.Styles {
+    if s.url == cssURL {
+      if s.sheet == nil {
+        sheet, err := styles.Build(s.src, context.NewDummyContext())
+        if err != nil {
+          return nil, err
+        }
+
+        cfg.Styles[i].sheet = sheet
+      }
 
+      return cfg.Styles[i].sheet, nil
+    }
+  }
+
+  panic("style " + cssURL + " not found")
+}
+
+// the page is rebuilt when the plain output of the asset changes
+func setIntegrity(cfg *SiteConfig, r *tree.Root, page PageConfig, url string, link string, plainDst string) error {
+  integrity, err := cfg.AssetIntegrity(url)
+  if err != nil {
+    return err
+  }
+
+  files.AddDep(page.dst, plainDst)
+
+  return r.SetIntegrity(link, integrity)
+}
+
+func registerSiteURLs(cfg *SiteConfig) {
   for _, file := range cfg.Files {
     directives.RegisterURL(file.src, file.url)
   }
 
   for _, page := range cfg.Pages {
-    if len(page.params) == 0 {
-      directives.RegisterURL(page.src, page.url)
+    if len(page.params) == 0 && page.collection == nil {
+      if page.locale == "" || page.locale == cfg.DefaultLocale {
+        directives.RegisterURL(page.src, page.url)
+      }
+
+      if page.locale != "" {
+        directives.RegisterLocaleURL(page.locale, page.src, page.url)
+      }
+    }
+  }
+}
+
+// builds the tree of a page, and applies the extensions of its style sheets
+func buildPageRoot(cfg *SiteConfig, cache *directives.FileCache, page PageConfig, parameters string) (*tree.Root, error) {
+  files.StartDstUpdate(page.dst, parameters)
+  files.AddDep(page.dst, page.src)
+  for _, dep := range page.deps {
+    files.AddDep(page.dst, dep)
+  }
+
+  directives.SetActiveURL(cleanURL(page.url))
+  if page.locale != "" {
+    directives.SetActiveLocale(page.locale)
+    directives.SetActiveLocaleURLs(page.localeURLs)
+
+    for _, catalog := range directives.ActiveCatalogPaths() {
+      files.AddDep(page.dst, catalog)
+    }
+  }
+
+  var r *tree.Root
+  var err error
+  if directives.IsMarkdownFile(page.src) {
+    if page.layout != "" {
+      files.AddDep(page.dst, page.layout)
+    }
+
+    r, err = directives.NewMarkdownRoot(cache, page.src, page.layout)
+  } else if page.collection != nil {
+    r, err = directives.NewRootWithVars(cache, page.src, page.collection.IndexVars(page.index, page.locale))
+  } else {
+    r, err = directives.NewRoot(cache, page.src)
+  }
+  if err != nil {
+    return nil, err
+  }
+  directives.UnsetActiveURL()
+  directives.UnsetActiveLocale()
+
+  if page.locale != "" {
+    if err := cfg.linkAlternates(r, page); err != nil {
+      return nil, err
+    }
+  }
+
+  for _, styleURL := range cfg.PageStyles(page.url) {
+    s := cfg.FindStyle(styleURL)
+    files.AddDep(page.dst, s.src)
+
+    sheet, err := cfg.styleSheet(styleURL)
+    if err != nil {
+      return nil, err
+    }
+
+    r, err = sheet.ApplyExtensions(r)
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  return r, nil
+}
+
+// links the assets and writes the page, returns the number of a11y problems
+func writePage(cfg *SiteConfig, cmdArgs CmdArgs, r *tree.Root, page PageConfig, linkChecker *LinkChecker) (int, error) {
+  sheets := make([]styles.Sheet, 0)
+  for _, styleURL := range cfg.PageStyles(page.url) {
+    styleLink := cleanLink(page.url, cfg.AssetURL(styleURL))
+    if cfg.Critical != nil {
+      if err := r.LinkStyleAsync(styleLink); err != nil {
+        return 0, err
+      }
+    } else {
+      r.LinkStyle(styleLink)
+    }
+
+    if cfg.Security != nil && cfg.Security.integrity {
+      s := cfg.FindStyle(styleURL)
+      if err := setIntegrity(cfg, r, page, styleURL, styleLink, s.dst); err != nil {
+        return 0, err
+      }
+    }
+
+    sheet, err := cfg.styleSheet(styleURL)
+    if err != nil {
+      return 0, err
+    }
+
+    sheets = append(sheets, sheet)
+  }
+
+  if cfg.Critical != nil {
+    css, err := cfg.criticalCSS(r, page, sheets, cfg.PageStyles(page.url))
+    if err != nil {
+      return 0, err
+    }
+
+    if css != "" {
+      if err := r.IncludeCriticalStyle(css); err != nil {
+        return 0, err
+      }
+    }
+  }
+
+  nA11y := 0
+  if cmdArgs.a11y {
+    var err error
+    nA11y, err = lintA11y(r, sheets)
+    if err != nil {
+      return 0, err
+    }
+  }
+
+  scriptHashes := cfg.PageScripts(page.url)
+  if len(scriptHashes) > 0 {
+    bundleLink := cleanLink(page.url, cfg.AssetURL(cfg.JSURL()))
+    r.LinkScriptBundle(bundleLink, scriptHashes)
+
+    if cfg.Security != nil && cfg.Security.integrity {
+      if err := setIntegrity(cfg, r, page, cfg.JSURL(), bundleLink, cfg.JSDst()); err != nil {
+        return 0, err
+      }
     }
   }
 
+  if linkChecker != nil {
+    linkChecker.AddPage(cleanURL(page.url), r)
+  }
+
+  output := r.Write("", patterns.NL, patterns.TAB)
+
+  // the policy doesn't change the inline scripts and styles, so the page is simply written a second time
+  if cfg.Security != nil && cfg.Security.csp == "meta" {
+    if err := r.SetContentSecurityPolicy(cfg.Security.PagePolicy(output)); err != nil {
+      return 0, err
+    }
+
+    output = r.Write("", patterns.NL, patterns.TAB)
+  }
+
+  if err := files.WriteFile(page.src, page.dst, []byte(output)); err != nil {
+    return 0, err
+  }
+
+  return nA11y, nil
+}
+
+func buildSitePages(cfg *SiteConfig, cmdArgs CmdArgs) error {
+  cache := directives.NewFileCache()
+
+  if err := cfg.registerCatalogs(); err != nil {
+    return err
+  }
+
+  var linkChecker *LinkChecker = nil
+  if cmdArgs.checkLinks {
+    linkChecker = NewLinkChecker()
+  }
+
+  nA11y := 0
+
+  // with --purge-css the sheets are purged against all the page trees, before the pages can link them
+  roots := make(map[string]*tree.Root)
+
   for _, page := range cfg.Pages {
     parameters := cfg.PageParameterString(page.url)
 
-    if files.RequiresDepUpdate(page.dst, parameters) {
-      files.StartDstUpdate(page.dst, parameters)
-      files.AddDep(page.dst, page.src)
+    // the link checker, the a11y lint and the purge need the trees of all the pages
+    if files.RequiresDepUpdate(page.dst, parameters) || linkChecker != nil || cmdArgs.a11y || cfg.Purge.enabled {
+      r, err := buildPageRoot(cfg, cache, page, parameters)
+      if err != nil {
+        return err
+      }
 
-      directives.SetActiveURL(cleanURL(page.url))
+      if cfg.Purge.enabled {
+        roots[page.url] = r
+        continue
+      }
 
-      r, err := directives.NewRoot(cache, page.src)
+      n, err := writePage(cfg, cmdArgs, r, page, linkChecker)
       if err != nil {
         return err
       }
-      directives.UnsetActiveURL()
 
-      for _, styleURL := range cfg.PageStyles(page.url) {
-        r.LinkStyle(cleanLink(page.url, styleURL))
+      nA11y += n
+    }
+  }
 
-        s := cfg.FindStyle(styleURL)
-        files.AddDep(page.dst, s.src)
+  if cfg.Purge.enabled {
+    if err := cfg.purgeStyles(roots); err != nil {
+      return err
+    }
 
-        r, err = s.sheet.ApplyExtensions(r)
-        if err != nil {
-          return err
-        }
+    for _, page := range cfg.Pages {
+      n, err := writePage(cfg, cmdArgs, roots[page.url], page, linkChecker)
+      if err != nil {
+        return err
       }
 
-      scriptHashes := cfg.PageScripts(page.url)
-      if len(scriptHashes) > 0 {
-        r.LinkScriptBundle(cleanLink(page.url, cfg.JSURL()), scriptHashes)
-      }
+      nA11y += n
+    }
+  }
 
-      output := r.Write("", patterns.NL, patterns.TAB)
+  if linkChecker != nil {
+    if err := linkChecker.Check(cfg); err != nil {
+      return err
+    }
+  }
 
-      if err := files.WriteFile(page.src, page.dst, []byte(output)); err != nil {
-        return err
+  if nA11y > 0 {
+    return fmt.Errorf("Error: found %d accessibility issues", nA11y)
+  }
+
+  return nil
+}
+
+// prints the problems to stderr, and returns their number
+func lintA11y(r *tree.Root, sheets []styles.Sheet) (int, error) {
+  decls := make(map[tree.Tag]map[string]tokens.Token)
+
+  // later sheets override earlier sheets
+  for _, sheet := range sheets {
+    sheetDecls, err := sheet.MatchDeclarations(r)
+    if err != nil {
+      return 0, err
+    }
+
+    for tag, d := range sheetDecls {
+      if _, ok := decls[tag]; !ok {
+        decls[tag] = make(map[string]tokens.Token)
+      }
+
+      for k, v := range d {
+        decls[tag][k] = v
       }
     }
   }
 
-  return nil
+  errs := tree.LintA11y(r, decls)
+  for _, err := range errs {
+    fmt.Fprintln(os.Stderr, err.Error())
+  }
+
+  return len(errs), nil
 }
 
 func buildSiteScripts(cfg *SiteConfig, cmdArgs CmdArgs) error {
@@ -379,7 +681,11 @@ func buildSiteScripts(cfg *SiteConfig, cmdArgs CmdArgs) error {
   if files.RequiresDepUpdate(dst, "") {
     files.StartDstUpdate(dst, "")
 
+    prevTarget := js.TARGET
     js.TARGET = "browser"
+    defer func() {
+      js.TARGET = prevTarget
+    }()
 
     bundle := scripts.NewFileBundle(cmdArgs.globals)
 
@@ -408,7 +714,7 @@ func buildSiteScripts(cfg *SiteConfig, cmdArgs CmdArgs) error {
 		}
   }
 
-  return nil
+  return cfg.hashAsset(cfg.JSURL())
 }
 
 func buildSite(cmdArgs CmdArgs, cfg *SiteConfig) error {
@@ -418,6 +724,14 @@ func buildSite(cmdArgs CmdArgs, cfg *SiteConfig) error {
 		return err
 	}
 
+  registerSiteURLs(cfg)
+
+  if cfg.AssetsBeforePages() {
+    if err := buildSiteScripts(cfg, cmdArgs); err != nil {
+      return err
+    }
+  }
+
 	if err := buildSiteStyles(cfg, cmdArgs); err != nil {
 		return err
 	}
@@ -426,9 +740,38 @@ func buildSite(cmdArgs CmdArgs, cfg *SiteConfig) error {
 		return err
 	}
 
-	if err := buildSiteScripts(cfg, cmdArgs); err != nil {
-		return err
-	}
+  if err := buildSiteFonts(cfg); err != nil {
+    return err
+  }
+
+  if !cfg.AssetsBeforePages() {
+    if err := buildSiteScripts(cfg, cmdArgs); err != nil {
+      return err
+    }
+  }
+
+  // sitemap, robots.txt and feeds are cheap, so they are always rebuilt
+  if cfg.Sitemap != nil {
+    if err := cfg.buildSitemap(); err != nil {
+      return err
+    }
+  }
+
+  if cfg.Robots != nil {
+    if err := cfg.buildRobots(); err != nil {
+      return err
+    }
+  }
+
+  if err := cfg.buildFeeds(); err != nil {
+    return err
+  }
+
+  if cfg.Security != nil && cfg.Security.csp == "headers" {
+    if err := cfg.buildHeaders(); err != nil {
+      return err
+    }
+  }
 
   if cmdArgs.clean {
     if err := cfg.CleanOutput(); err != nil {
@@ -452,9 +795,18 @@ func main() {
     printMessageAndExit(err.Error())
   }
 
+  cfg.Purge.enabled = cmdArgs.purgeCSS
+
+  if cmdArgs.hashAssets {
+    if err := cfg.EnableAssetHashes(); err != nil {
+      printMessageAndExit(err.Error())
+    }
+  }
+
   // remainder of enb
-  directives.MATH_FONT_URL = cfg.MathFontURL()
-  styles.SaveMathFont(cfg.MathFontDst())
+  directives.MATH_FONT_URL = cfg.AssetURL(cfg.MathFontURL())
+  styles.FONT_URL = cfg.fontURL
+  styles.SaveMathFont(cfg.AssetDst(cfg.MathFontURL()))
 
 	if cmdArgs.profFile != "" {
     startProfiling(cmdArgs.profFile)
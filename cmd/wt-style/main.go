@@ -25,8 +25,11 @@ var (
 type CmdArgs struct {
   inputFile string
   outputFile string
+  browsers string
 
   compactOutput bool
+  minifyCSS bool
+  minifyCSSUnsafe bool
   autoDownload bool
   verbosity int
 }
@@ -45,19 +48,25 @@ func parseArgs() CmdArgs {
 	cmdArgs := CmdArgs{
 		inputFile:     "",
 		outputFile:    DEFAULT_OUTPUTFILE,
+    browsers:      "",
 		compactOutput: false,
+    minifyCSS: false,
+    minifyCSSUnsafe: false,
     autoDownload: false,
 		verbosity:     0,
 	}
 
   cmdParser = parsers.NewCLIParser(
     fmt.Sprintf("Usage: %s <input-file> [-o <output-file>] [options]\n", os.Args[0]),
-    "",
+    "<input-file>   a style file exporting main, or a plain .css file",
     []parsers.CLIOption{
       parsers.NewCLIVersion("", "version",   "--version    Show version", VERSION),
       parsers.NewCLIUniqueFlag("c", "compact"       , "-c, --compact          Compact output with minimal whitespace and short names", &(cmdArgs.compactOutput)),
   
       parsers.NewCLIUniqueFile("o", "output"        , "-o, --output <file>    Defaults to \"" + DEFAULT_OUTPUTFILE + "\" if not set", false, &(cmdArgs.outputFile)),
+      parsers.NewCLIUniqueString("", "browsers"     , "--browsers <query>     Targeted browsers, eg. \"last 2 versions, safari 12\" (adds vendor prefixes and lowers modern properties)", &(cmdArgs.browsers)),
+      parsers.NewCLIUniqueFlag("", "minify-css"     , "--minify-css           Merge rules, collapse shorthands, drop overridden declarations, and shorten colors and numbers", &(cmdArgs.minifyCSS)),
+      parsers.NewCLIUniqueFlag("", "minify-css-unsafe", "--minify-css-unsafe    Like --minify-css, but also moves rules past rules with a different specificity", &(cmdArgs.minifyCSSUnsafe)),
       parsers.NewCLIUniqueFlag("", "auto-download"         , "--auto-download                   Automatically download missing packages (use wt-pkg-sync if you want to do this manually). Doesn't update packages!", &(cmdArgs.autoDownload)), 
       parsers.NewCLIUniqueFlag("l", "latest"        , "-l, --latest           Ignore max semver, use latest tagged versions of dependencies", &(files.LATEST)),
       parsers.NewCLICountFlag("v", ""               , "-v[v[v..]]             Verbosity", &(cmdArgs.verbosity)),
@@ -85,6 +94,20 @@ func setUpEnv(cmdArgs CmdArgs) error {
     git.RegisterFetchPublicOrPrivate()
   }
 
+  if cmdArgs.browsers != "" {
+    browsers, err := styles.ParseBrowsers(cmdArgs.browsers)
+    if err != nil {
+      return err
+    }
+
+    styles.BROWSERS = browsers
+  }
+
+  if cmdArgs.minifyCSS || cmdArgs.minifyCSSUnsafe {
+    styles.MINIFY = true
+    styles.MINIFY_UNSAFE = cmdArgs.minifyCSSUnsafe
+  }
+
 	VERBOSITY = cmdArgs.verbosity
 	files.VERBOSITY = cmdArgs.verbosity
 	parsers.VERBOSITY = cmdArgs.verbosity
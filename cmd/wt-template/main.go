@@ -68,6 +68,7 @@ func parseArgs() CmdArgs {
       parsers.NewCLIUniqueFlag("", "auto-download"         , "--auto-download                   Automatically download missing packages (use wt-pkg-sync if you want to do this manually). Doesn't update packages!", &(cmdArgs.autoDownload)), 
       parsers.NewCLIUniqueFile("o", "output"        , "-o, --output <file>    Defaults to \"" + DEFAULT_OUTPUTFILE + "\" if not set", false, &(cmdArgs.outputFile)),
       parsers.NewCLIUniqueFile("", "control"        , "--control <file>       Optional control file", true, &(cmdArgs.control)),
+      parsers.NewCLIUniqueFlag("", "html-warnings" , "--html-warnings        Report HTML content model violations as warnings instead of errors", &(tree.CONTENT_MODEL_WARNINGS)),
       parsers.NewCLIUniqueFlag("l", "latest"        , "-l, --latest           Ignore max semver, use latest tagged versions of dependencies", &(files.LATEST)),
       parsers.NewCLICountFlag("v", ""               , "-v[v[v..]]             Verbosity", &(cmdArgs.verbosity)),
     },
@@ -107,6 +107,12 @@ func BuildFile(cache *FileCache, path string, isRoot bool, parameters *tokens.Pa
 
 		// TODO: should we refactor these into the Node structure?
 		SetFile(fileScope, path, autoCtx)
+
+    if isRoot {
+      if err := setActivePageVars(fileScope, autoCtx); err != nil {
+        return nil, nil, err
+      }
+    }
 		//SetURL(fileScope, path, autoCtx) // this is file local url, only valid in the root scope if the path is effectively also used as a html document
 
     if len(tags) > 0 && tags[0].Name() == "parameters" {
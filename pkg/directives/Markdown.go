@@ -0,0 +1,148 @@
+package directives
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/parsers"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tree"
+)
+
+// whitespace inside these markdown tags is significant, so they are written on a single line
+var _markdownInlineTags = map[string]bool{
+	"li":  true,
+	"pre": true,
+}
+
+// markdown output only contains plain html tags, so the whole tree can be built here
+func buildMarkdownTag(scope Scope, node Node, tag *tokens.Tag) error {
+	key := tag.Name()
+
+	switch {
+	case tag.IsText():
+		return buildText(node, tag)
+	case node.Type() != HTML || !tree.IsTag(key):
+		return BuildTag(scope, node, tag)
+	}
+
+	attr, err := buildAttributes(scope, tag, []string{})
+	if err != nil {
+		return err
+	}
+
+	var t tree.Tag
+	if _markdownInlineTags[key] {
+		t, err = tree.NewGeneric(key, attr, true, tag.Context())
+	} else {
+		t, err = tree.BuildTag(key, attr, tag.Context())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := node.AppendChild(t); err != nil {
+		return err
+	}
+
+	newNode := NewNode(t, node)
+	for _, child := range tag.Children() {
+		if err := buildMarkdownTag(scope, newNode, child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// converts markdown into regular tags, eg.:
+//  markdown("./intro.md")
+//  markdown(text=someString)
+// without arguments the content of the markdown page wrapped by the current layout is inserted
+func Markdown(scope Scope, node Node, tag *tokens.Tag) error {
+	ctx := tag.Context()
+
+	subScope := NewSubScope(scope)
+
+	if err := tag.AssertEmpty(); err != nil {
+		return err
+	}
+
+	attr, err := tag.Attributes([]string{"src"})
+	if err != nil {
+		return err
+	}
+
+	attr, err = attr.EvalStringDict(subScope)
+	if err != nil {
+		return err
+	}
+
+	var content []*tokens.Tag
+	_, hasSrc := attr.Get("src")
+	_, hasText := attr.Get("text")
+
+	switch {
+	case hasSrc && hasText:
+		errCtx := attr.Context()
+		return errCtx.NewError("Error: can't have both src and text")
+	case hasSrc || hasText:
+		var p *parsers.MarkdownParser
+
+		if hasSrc {
+			srcToken, err := tokens.DictString(attr, "src")
+			if err != nil {
+				return err
+			}
+
+			absPath, err := searchFile(srcToken.Value(), srcToken.Context())
+			if err != nil {
+				return err
+			}
+
+			addCacheDependency(false, ctx.Path(), absPath)
+
+			p, err = parsers.NewMarkdownParser(absPath)
+			if err != nil {
+				return err
+			}
+
+			attr.Delete("src")
+		} else {
+			textToken, err := tokens.DictString(attr, "text")
+			if err != nil {
+				return err
+			}
+
+			p, err = parsers.NewMarkdownParser(textToken.Value(), ctx.Path())
+			if err != nil {
+				return err
+			}
+
+			attr.Delete("text")
+		}
+
+		content, err = p.BuildTags()
+		if err != nil {
+			return err
+		}
+	default:
+		content, err = getActiveMarkdownContent(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if attr.Len() != 0 {
+		errCtx := attr.Context()
+		return errCtx.NewError("Error: unexpected attributes")
+	}
+
+	for _, child := range content {
+		if err := buildMarkdownTag(scope, node, child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var _markdownOk = registerDirective("markdown", Markdown)
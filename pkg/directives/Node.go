@@ -35,6 +35,8 @@ type Node interface {
   // register style sheet, which can be used by 
   RegisterStyleSheet(sheet StyleSheet) // the wraps of registered style sheets must be 
   // wraps after the main style sheet
+
+  RegisterScopedStyle(attrName string, css string, sheet StyleSheet, ctx context.Context) error // included once in the head of the page
 }
 
 type NodeData struct {
@@ -162,3 +164,11 @@ func (n *NodeData) RegisterStyleSheet(sheet StyleSheet) {
     n.parent.RegisterStyleSheet(sheet)
   }
 }
+
+func (n *NodeData) RegisterScopedStyle(attrName string, css string, sheet StyleSheet, ctx context.Context) error {
+  if n.parent == nil {
+    return ctx.NewError("Error: scoped style outside page")
+  }
+
+  return n.parent.RegisterScopedStyle(attrName, css, sheet, ctx)
+}
@@ -1,6 +1,11 @@
 package directives
 
 import (
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/files"
+	"github.com/wtsuite/wtsuite/pkg/parsers"
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
 	"github.com/wtsuite/wtsuite/pkg/tree"
 	//"github.com/wtsuite/wtsuite/pkg/tree/scripts"
 )
@@ -14,6 +19,71 @@ func NewRoot(cache *FileCache, path string) (*tree.Root, error) {
   return FinalizeRoot(node)
 }
 
+// the markdown content is wrapped by a layout template, which inserts it with an argumentless markdown directive
+// the front-matter variables are available in the root scope of the layout
+// a "layout" front-matter entry overrides layoutPath (can be empty)
+func NewMarkdownRoot(cache *FileCache, path string, layoutPath string) (*tree.Root, error) {
+  p, err := parsers.NewMarkdownParser(path)
+  if err != nil {
+    return nil, err
+  }
+
+  content, err := p.BuildTags()
+  if err != nil {
+    return nil, err
+  }
+
+  frontMatter := p.FrontMatter()
+  ctx := p.FrontMatterContext()
+
+  if layout_, ok := frontMatter["layout"]; ok {
+    layout, ok := layout_.(string)
+    if !ok {
+      return nil, ctx.NewError("Error: front-matter layout isn't a string")
+    }
+
+    layoutPath, err = files.Search(path, layout)
+    if err != nil {
+      return nil, ctx.NewError("Error: " + err.Error())
+    }
+
+    delete(frontMatter, "layout")
+  } else if layoutPath == "" {
+    return nil, ctx.NewError("Error: no layout specified for markdown page")
+  }
+
+  files.StartDepUpdate(path, "")
+  files.AddDep(path, layoutPath)
+
+  if err := setActivePage(content, frontMatter, ctx); err != nil {
+    return nil, err
+  }
+
+  defer unsetActivePage()
+
+	_, node, err := BuildFile(cache, layoutPath, true, nil)
+	if err != nil {
+		return nil, err
+	}
+
+  return FinalizeRoot(node)
+}
+
+// vars are available in the root scope of the page, eg. for generated index pages
+func NewRootWithVars(cache *FileCache, path string, vars map[string]interface{}) (*tree.Root, error) {
+  if err := setActivePage(nil, vars, context.NewDummyContext()); err != nil {
+    return nil, err
+  }
+
+  defer unsetActivePage()
+
+  return NewRoot(cache, path)
+}
+
+func IsMarkdownFile(path string) bool {
+  return strings.HasSuffix(path, ".md") || strings.HasSuffix(path, ".markdown")
+}
+
 func FinalizeRoot(node *RootNode) (*tree.Root, error) {
 
 	root_ := node.tag
@@ -22,6 +92,13 @@ func FinalizeRoot(node *RootNode) (*tree.Root, error) {
 		panic("expected root")
 	}
 
+  // after the linked style sheets, which are inserted before the first style tag
+  for _, s := range node.scopedStyles {
+    if err := root.IncludeStyle(s.css); err != nil {
+      return nil, err
+    }
+  }
+
 	root.FoldDummy()
 
 	tree.RegisterParents(root)
@@ -40,6 +117,10 @@ func FinalizeRoot(node *RootNode) (*tree.Root, error) {
 		return nil, err
 	}
 
+	if err := root.ValidateContentModel(); err != nil {
+		return nil, err
+	}
+
   // apply @wrap of any inlined stylesheets
   // don't forget this for external stylesheets!
   for _, s := range node.sheets {
@@ -9,15 +9,22 @@ import (
 type RootNode struct {
 	t NodeType
   sheets []StyleSheet
+  scopedStyles []scopedStyle
 	NodeData
 }
 
+type scopedStyle struct {
+  attrName string
+  css      string
+  ctx      context.Context
+}
+
 func NewRootNode(tag tree.Tag, t NodeType) *RootNode {
 	if tag.Name() != "" {
 		panic("expected Root or SVGRoot (tags with empty names)")
 	}
 
-	return &RootNode{t, make([]StyleSheet, 0), newNodeData(tag, nil)}
+	return &RootNode{t, make([]StyleSheet, 0), make([]scopedStyle, 0), newNodeData(tag, nil)}
 }
 
 func (n *RootNode) Type() NodeType {
@@ -50,3 +57,23 @@ func (n *RootNode) getNode() Node {
 func (n *RootNode) RegisterStyleSheet(sheet StyleSheet) {
   n.sheets = append(n.sheets, sheet)
 }
+
+// every instance of a template registers the same style, which must only be included (and its sheet registered) once
+func (n *RootNode) RegisterScopedStyle(attrName string, css string, sheet StyleSheet, ctx context.Context) error {
+  for _, prev := range n.scopedStyles {
+    if prev.attrName == attrName {
+      if prev.css != css {
+        err := ctx.NewError("Error: scoped style differs between template instances (can't depend on the template args)")
+        err.AppendContextString("Info: first instance", prev.ctx)
+        return err
+      }
+
+      return nil
+    }
+  }
+
+  n.scopedStyles = append(n.scopedStyles, scopedStyle{attrName, css, ctx})
+  n.RegisterStyleSheet(sheet)
+
+  return nil
+}
@@ -255,6 +255,12 @@ func eval(scope Scope, key string, args *tokens.Parens, ctx context.Context) (to
 		return evalSVGURI(scope, args, ctx)
 	case key == "url":
 		return evalFileURL(scope, args, ctx)
+	case key == "locale-url":
+		return evalLocaleURL(scope, args, ctx)
+	case key == "translate":
+		return evalTranslate(scope, args, ctx)
+	case key == "lang":
+		return evalLang(scope, args, ctx)
 	case key == "math-uri":
 		return evalMathURI(scope, args, ctx)
 	case key == "new":
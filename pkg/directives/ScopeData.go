@@ -123,6 +123,7 @@ func (scope *ScopeData) Sync(dst Scope, keepAutoVars, keepImports, asImports boo
 				true,
 				c.exported,
         c.final,
+        c.scopeAttr,
 				c.ctx,
 			}
 		}
@@ -177,6 +178,7 @@ func (scope *ScopeData) SyncPackage(dst Scope, keepAutoVars, keepImports, asImpo
           true,
           false,
           c.final,
+          c.scopeAttr,
           c.ctx,
         }
       }
@@ -273,6 +275,7 @@ func (scope *ScopeData) SyncFiltered(dst Scope, keepAutoVars, keepImports, asImp
 				true,
 				false,
         c.final,
+        c.scopeAttr,
 				c.ctx,
 			}
 		}
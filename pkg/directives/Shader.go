@@ -0,0 +1,109 @@
+package directives
+
+import (
+  "encoding/json"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/patterns"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tree"
+	"github.com/wtsuite/wtsuite/pkg/tree/shaders"
+)
+
+// compiles a glsl module into <script type="x-shader/x-vertex"> or <script type="x-shader/x-fragment">,
+// or into a js constant if the const attribute is set, eg.:
+//  shader("./wave.tglsl", "fragment", id="wave-fs")
+//  shader("./wave.tglsl", "fragment", const="WAVE_FS")
+func Shader(scope Scope, node Node, tag *tokens.Tag) error {
+  ctx := tag.Context()
+
+	subScope := NewSubScope(scope)
+
+  if err := tag.AssertEmpty(); err != nil {
+    return err
+  }
+
+	attr, err := tag.Attributes([]string{"src", "target"})
+	if err != nil {
+		return err
+	}
+
+	attr, err = attr.EvalStringDict(subScope)
+	if err != nil {
+		return err
+	}
+
+  srcToken, err := tokens.DictString(attr, "src")
+  if err != nil {
+    return err
+  }
+
+  targetToken, err := tokens.DictString(attr, "target")
+  if err != nil {
+    return err
+  }
+
+  attr.Delete("src")
+  attr.Delete("target")
+
+  target := targetToken.Value()
+  if target != "vertex" && target != "fragment" {
+    errCtx := targetToken.Context()
+    return errCtx.NewError("Error: expected \"vertex\" or \"fragment\"")
+  }
+
+  absPath, err := searchFile(srcToken.Value(), srcToken.Context())
+  if err != nil {
+    return err
+  }
+
+  source, paths, err := shaders.TranspileShader(absPath, target)
+  if err != nil {
+    return err
+  }
+
+  // the page must be rebuilt if any of the imported shader files changes
+  for _, path := range paths {
+    addCacheDependency(false, ctx.Path(), path)
+  }
+
+  content := ""
+  if _, ok := attr.Get("const"); ok {
+    constToken, err := tokens.DictString(attr, "const")
+    if err != nil {
+      return err
+    }
+
+    if !patterns.JS_WORD_REGEXP.MatchString(constToken.Value()) {
+      errCtx := constToken.Context()
+      return errCtx.NewError("Error: invalid const name")
+    }
+
+    attr.Delete("const")
+
+    // json strings are valid js strings, and < is escaped so the source can't close the script tag
+    sourceStr, err := json.Marshal(source)
+    if err != nil {
+      return err
+    }
+
+    content = "const " + constToken.Value() + "=" + string(sourceStr) + ";"
+  } else {
+    if _, ok := attr.Get("type"); ok {
+      errCtx := attr.Context()
+      return errCtx.NewError("Error: type attribute is set automatically")
+    }
+
+    attr.Set("type", tokens.NewValueString("x-shader/x-" + target, ctx))
+
+    content = source
+  }
+
+	script, err := tree.NewScript(attr, content, ctx)
+	if err != nil {
+		return err
+	}
+
+	return node.AppendChild(script)
+}
+
+var _shaderOk = registerDirective("shader", Shader)
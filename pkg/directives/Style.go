@@ -13,6 +13,13 @@ func RegisterBuildStyle(fn BuildStyleFunc) bool {
   return true
 }
 
+type BuildScopedStyleFunc func(d *tokens.StringDict, attrName string) (StyleSheet, string, error)
+var BuildScopedStyle BuildScopedStyleFunc = nil
+func RegisterBuildScopedStyle(fn BuildScopedStyleFunc) bool {
+  BuildScopedStyle = fn
+  return true
+}
+
 func buildInlineStyle(node Node, attr *tokens.StringDict, content string,
 	ctx context.Context) error {
 	if style, err := tree.NewStyle(attr, content, ctx); err != nil {
@@ -35,6 +42,10 @@ func Style(scope Scope, node Node, tag *tokens.Tag) error {
 		return err
 	}
 
+  if scopedToken, ok := attr.Get("scoped"); ok && tokens.IsTrueBool(scopedToken) {
+    return buildScopedStyle(subScope, node, attr, tag)
+  }
+
   contentStr := ""
 	contentToken, ok := attr.Get(".content")
   if !ok {
@@ -65,4 +76,44 @@ func Style(scope Scope, node Node, tag *tokens.Tag) error {
   return buildInlineStyle(node, attr, contentStr, tag.Context())
 }
 
+// the rules only apply to the elements emitted by the instances of the surrounding template
+// the style is included once in the head of the page, instead of once per instance
+func buildScopedStyle(scope Scope, node Node, attr *tokens.StringDict, tag *tokens.Tag) error {
+  ctx := tag.Context()
+
+  if !scope.HasVar(SCOPE_ATTR) {
+    return ctx.NewError("Error: scoped style outside template")
+  }
+
+  attrName, err := tokens.AssertString(scope.GetVar(SCOPE_ATTR).Value)
+  if err != nil {
+    panic(err)
+  }
+
+  contentToken, ok := attr.Get(".content")
+  if !ok {
+    return ctx.NewError("Error: scoped style without style dict")
+  }
+
+  d, err := tokens.AssertStringDict(contentToken)
+  if err != nil {
+    return err
+  }
+
+  attr.Delete(".content")
+  attr.Delete("scoped")
+
+  if attr.Len() != 0 {
+    errCtx := attr.Context()
+    return errCtx.NewError("Error: scoped style can't have attributes")
+  }
+
+  sheet, contentStr, err := BuildScopedStyle(d, attrName.Value())
+  if err != nil {
+    return err
+  }
+
+  return node.RegisterScopedStyle(attrName.Value(), contentStr, sheet, ctx)
+}
+
 var _styleOk = registerDirective("style", Style)
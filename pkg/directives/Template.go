@@ -21,6 +21,7 @@ type Template struct {
 	imported    bool
 	exported    bool
   final       bool
+  scopeAttr   string // empty if the template has no scoped styles
 	ctx         context.Context
 }
 
@@ -32,6 +33,11 @@ func newTemplate(name string, extends string, scope Scope, args *tokens.List, ar
 	// copy the scope, in order to take a snapshot of its state
 	subScope := NewSubScope(scope)
 
+  scopeAttr := ""
+  if hasScopedStyle(children) {
+    scopeAttr = tree.NewUniqueScopeAttr()
+  }
+
 	return Template{
 		name,
 		extends,
@@ -43,6 +49,7 @@ func newTemplate(name string, extends string, scope Scope, args *tokens.List, ar
 		false,
 		exported,
     final,
+    scopeAttr,
 		ctx,
 	}
 }
@@ -282,6 +289,9 @@ func (c Template) instantiate(node *TemplateNode, args *tokens.StringDict,
   setElementCount(subScope, node, c.ctx)
   setLazyTagVars(subScope, c.ctx) // __nchildren__ is only usable in the attributes
   setParentStyle(subScope, node, c.ctx)
+  if c.scopeAttr != "" {
+    setScopeAttr(subScope, c.scopeAttr, c.ctx)
+  }
 
 	// loop incoming attr and check if it is in c.args
 	if err := args.Loop(func(k *tokens.String, v tokens.Token, last bool) error {
@@ -412,6 +422,10 @@ func (c Template) instantiate(node *TemplateNode, args *tokens.StringDict,
     panic(err)
   }
 
+  if c.scopeAttr != "" {
+    applyScopeAttr(child, c.scopeAttr, c.ctx)
+  }
+
 	return nil
 }
 
@@ -65,6 +65,10 @@ func (n *TemplateNode) RegisterStyleSheet(sheet StyleSheet) {
   n.parent.RegisterStyleSheet(sheet)
 }
 
+func (n *TemplateNode) RegisterScopedStyle(attrName string, css string, sheet StyleSheet, ctx context.Context) error {
+  return n.parent.RegisterScopedStyle(attrName, css, sheet, ctx)
+}
+
 func (n *TemplateNode) StartDeferral() {
   n.collectDeferred = true
 }
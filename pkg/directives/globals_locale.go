@@ -0,0 +1,286 @@
+package directives
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wtsuite/wtsuite/pkg/functions"
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// translation catalogs are json files with one catalog per locale, eg. fr.json:
+//  {
+//    "greeting": "Bonjour {name} !",
+//    "nav": {"home": "Accueil", "blog": "Blog"},
+//    "comments": {"one": "{n} commentaire", "other": "{n} commentaires"}
+//  }
+// nested dicts are namespaces (looked up as "nav.home"), dicts with plural categories as keys are plural forms
+// templates use:
+//  translate("nav.home")
+//  translate("greeting", {name: $user})
+//  translate("comments", $count) // {n} is replaced by the count
+//  lang() // the active locale
+type translationCatalog struct {
+	path     string
+	messages map[string]map[string]string // key -> plural category -> message
+}
+
+var _catalogs map[string]*translationCatalog = nil
+var _defaultLocale = ""
+var _activeLocale = ""
+
+var _placeholderRegexp = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+func RegisterCatalog(lang string, path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.NewContext(context.NewSource(string(b)), path)
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return ctx.NewError("Error: bad catalog syntax (" + err.Error() + ")")
+	}
+
+	catalog := &translationCatalog{path, make(map[string]map[string]string)}
+	if err := catalog.add("", raw, ctx); err != nil {
+		return err
+	}
+
+	if _catalogs == nil {
+		_catalogs = make(map[string]*translationCatalog)
+	}
+
+	_catalogs[lang] = catalog
+
+	return nil
+}
+
+func (c *translationCatalog) add(prefix string, raw map[string]interface{}, ctx context.Context) error {
+	for k, v_ := range raw {
+		key := prefix + k
+
+		switch v := v_.(type) {
+		case string:
+			c.messages[key] = map[string]string{"other": v}
+		case map[string]interface{}:
+			if isPluralForms(v) {
+				forms := make(map[string]string)
+				for cat, form_ := range v {
+					form, ok := form_.(string)
+					if !ok {
+						return ctx.NewError("Error: plural form " + key + "." + cat + " isn't a string")
+					}
+
+					forms[cat] = form
+				}
+
+				c.messages[key] = forms
+			} else if err := c.add(key+".", v, ctx); err != nil {
+				return err
+			}
+		default:
+			return ctx.NewError("Error: catalog entry " + key + " isn't a string or a dict")
+		}
+	}
+
+	return nil
+}
+
+func isPluralForms(d map[string]interface{}) bool {
+	if _, ok := d["other"]; !ok {
+		return false
+	}
+
+	for k, _ := range d {
+		if !isPluralCategory(k) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func SetDefaultLocale(lang string) {
+	_defaultLocale = lang
+}
+
+func SetActiveLocale(lang string) {
+	_activeLocale = lang
+}
+
+func UnsetActiveLocale() {
+	_activeLocale = ""
+}
+
+// paths of the catalogs that are used when building in the active locale
+func ActiveCatalogPaths() []string {
+	res := make([]string, 0)
+
+	for _, lang := range []string{_activeLocale, _defaultLocale} {
+		if c, ok := _catalogs[lang]; ok && (len(res) == 0 || res[0] != c.path) {
+			res = append(res, c.path)
+		}
+	}
+
+	return res
+}
+
+// falls back to the default locale
+func lookupTranslation(key string) (string, map[string]string, bool) {
+	for _, lang := range []string{_activeLocale, _defaultLocale} {
+		if c, ok := _catalogs[lang]; ok {
+			if forms, ok := c.messages[key]; ok {
+				return lang, forms, true
+			}
+		}
+	}
+
+	return "", nil, false
+}
+
+func translationVarString(t tokens.Token) (string, error) {
+	switch v := t.(type) {
+	case *tokens.String:
+		return v.Value(), nil
+	case *tokens.Int:
+		return v.Write(), nil
+	case *tokens.Float:
+		return v.Write(), nil
+	default:
+		errCtx := t.Context()
+		return "", errCtx.NewError("Error: expected string, int or float")
+	}
+}
+
+func evalTranslate(scope Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
+	var err error
+	args_, err = args_.EvalAsArgs(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := functions.CompleteArgs(args_, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) < 1 || len(args) > 3 {
+		return nil, ctx.NewError("Error: expected 1, 2 or 3 arguments")
+	}
+
+	if _activeLocale == "" && _defaultLocale == "" {
+		return nil, ctx.NewError("Error: no active locale (hint: set the i18n section of the site config)")
+	}
+
+	keyToken, err := tokens.AssertString(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string)
+
+	count := ""
+	hasCount := false
+	for _, arg := range args[1:] {
+		switch {
+		case tokens.IsInt(arg) && !hasCount:
+			n, err := tokens.AssertInt(arg)
+			if err != nil {
+				return nil, err
+			}
+
+			count = strconv.Itoa(n.Value())
+			hasCount = true
+			vars["n"] = n.Write()
+		case tokens.IsFloat(arg) && !hasCount:
+			n, err := tokens.AssertFloat(arg, "")
+			if err != nil {
+				return nil, err
+			}
+
+			// the fraction digits are plural operands too
+			count = strconv.FormatFloat(n.Value(), 'f', -1, 64)
+			hasCount = true
+			vars["n"] = count
+		case tokens.IsStringDict(arg):
+			d, err := tokens.AssertStringDict(arg)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := d.Loop(func(k *tokens.String, v_ tokens.Token, last bool) error {
+				v, err := translationVarString(v_)
+				if err != nil {
+					return err
+				}
+
+				vars[k.Value()] = v
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		default:
+			errCtx := arg.Context()
+			return nil, errCtx.NewError("Error: expected int or float count, or dict of variables")
+		}
+	}
+
+	lang, forms, ok := lookupTranslation(keyToken.Value())
+	if !ok {
+		errCtx := keyToken.Context()
+		return nil, errCtx.NewError("Error: translation \"" + keyToken.Value() + "\" not found")
+	}
+
+	msg := forms["other"]
+	if hasCount {
+		if form, ok := forms[pluralCategory(lang, count)]; ok {
+			msg = form
+		}
+
+		// explicit zero forms are also used by languages without a zero category
+		if form, ok := forms["zero"]; ok && count == "0" {
+			msg = form
+		}
+	}
+
+	var missing []string = nil
+	res := _placeholderRegexp.ReplaceAllStringFunc(msg, func(m string) string {
+		name := m[1 : len(m)-1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+
+		missing = append(missing, name)
+		return m
+	})
+
+	if missing != nil {
+		sort.Strings(missing)
+		errCtx := keyToken.Context()
+		return nil, errCtx.NewError("Error: missing translation variables " + strings.Join(missing, ", "))
+	}
+
+	return tokens.NewValueString(res, ctx), nil
+}
+
+func evalLang(scope Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
+	if args_.Len() != 0 {
+		return nil, ctx.NewError("Error: expected 0 arguments")
+	}
+
+	if _activeLocale != "" {
+		return tokens.NewValueString(_activeLocale, ctx), nil
+	} else if _defaultLocale != "" {
+		return tokens.NewValueString(_defaultLocale, ctx), nil
+	} else {
+		return nil, ctx.NewError("Error: no active locale")
+	}
+}
@@ -0,0 +1,79 @@
+package directives
+
+import (
+	"sort"
+
+	"github.com/wtsuite/wtsuite/pkg/functions"
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tokens/patterns"
+)
+
+// variables injected into the root scope of the page that is being built (eg. front-matter)
+// content is the markdown that is being wrapped by a layout template (nil for regular pages)
+type activePage struct {
+	content []*tokens.Tag
+	vars    map[string]tokens.Token
+}
+
+var _activePage *activePage = nil
+
+func setActivePage(content []*tokens.Tag, vars_ map[string]interface{}, ctx context.Context) error {
+	vars := make(map[string]tokens.Token)
+
+	for k, v := range vars_ {
+		if !patterns.IsValidVar(k) {
+			return ctx.NewError("Error: \"" + k + "\" is not a valid variable name")
+		}
+
+		t, err := tokens.GolangToToken(v, ctx)
+		if err != nil {
+			return ctx.NewError("Error: variable \"" + k + "\" " + err.Error())
+		}
+
+		vars[k] = t
+	}
+
+	_activePage = &activePage{content, vars}
+
+	return nil
+}
+
+func unsetActivePage() {
+	_activePage = nil
+}
+
+func getActiveMarkdownContent(ctx context.Context) ([]*tokens.Tag, error) {
+	if _activePage == nil || _activePage.content == nil {
+		return nil, ctx.NewError("Error: markdown content not set here (hint: use markdown(src))")
+	}
+
+	return _activePage.content, nil
+}
+
+func setActivePageVars(scope Scope, ctx context.Context) error {
+	if _activePage == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(_activePage.vars))
+	for k, _ := range _activePage.vars {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := scope.SetVar(k, functions.Var{
+			_activePage.vars[k],
+			true,
+			false,
+			false,
+			ctx,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
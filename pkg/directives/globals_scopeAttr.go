@@ -0,0 +1,51 @@
+package directives
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/functions"
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tree"
+)
+
+// only available inside the scope of templates with scoped styles
+const SCOPE_ATTR = "__scope__"
+
+func setScopeAttr(scope Scope, attrName string, ctx context.Context) {
+  v := functions.Var{tokens.NewValueString(attrName, ctx), true, false, false, ctx}
+
+  if err := scope.SetVar(SCOPE_ATTR, v); err != nil {
+    panic(err)
+  }
+}
+
+// direct or nested style(scoped=true) children, nested templates have their own scope
+func hasScopedStyle(tags []*tokens.Tag) bool {
+  for _, tag := range tags {
+    switch tag.Name() {
+    case "template":
+      continue
+    case "style":
+      if scopedToken, ok := tag.RawAttributes().Get("scoped"); ok && tokens.IsTrueBool(scopedToken) {
+        return true
+      }
+    default:
+      if hasScopedStyle(tag.Children()) {
+        return true
+      }
+    }
+  }
+
+  return false
+}
+
+// also applied to the content passed to the blocks of the template, and to the nested template instances
+func applyScopeAttr(t tree.Tag, attrName string, ctx context.Context) {
+  // text and dummy tags are skipped
+  if _, ok := t.(tree.VisibleTag); ok && t.Name() != "" && t.Name() != "dummy" {
+    t.Attributes().Set(tokens.NewValueString(attrName, ctx), tokens.NewValueBool(true, ctx))
+  }
+
+  for _, child := range t.Children() {
+    applyScopeAttr(child, attrName, ctx)
+  }
+}
@@ -15,6 +15,10 @@ var IGNORE_UNSET_URLS = false
 var _fileURLs map[string]string = nil
 var _activeURL *tokens.String = nil
 
+// multilingual sites register a url for every locale of every page
+var _localeFileURLs map[string]map[string]string = nil
+var _activeLocaleURLs map[string]string = nil
+
 // path is src path
 func RegisterURL(path string, url string) {
 	if _fileURLs == nil {
@@ -24,6 +28,18 @@ func RegisterURL(path string, url string) {
 	_fileURLs[path] = url
 }
 
+func RegisterLocaleURL(lang string, path string, url string) {
+	if _localeFileURLs == nil {
+		_localeFileURLs = make(map[string]map[string]string)
+	}
+
+	if _, ok := _localeFileURLs[lang]; !ok {
+		_localeFileURLs[lang] = make(map[string]string)
+	}
+
+	_localeFileURLs[lang][path] = url
+}
+
 func GetActiveURL(ctx context.Context) (*tokens.String, error) {
 	if _activeURL == nil {
 		return nil, ctx.NewError("Error: __url__ not set here")
@@ -36,8 +52,41 @@ func SetActiveURL(url string) {
 	_activeURL = tokens.NewValueString(url, context.NewDummyContext())
 }
 
+// urls of the active page in each locale (the keys), used by locale-url()
+func SetActiveLocaleURLs(urls map[string]string) {
+	_activeLocaleURLs = urls
+}
+
 func UnsetActiveURL() {
 	_activeURL = nil
+	_activeLocaleURLs = nil
+}
+
+func lookupFileURL(path string, lang string) (string, bool) {
+	if lang != "" {
+		if urls, ok := _localeFileURLs[lang]; ok {
+			if url, ok := urls[path]; ok {
+				return url, true
+			}
+		}
+	}
+
+	url, ok := _fileURLs[path]
+	return url, ok
+}
+
+func relativeURL(scope Scope, url string, ctx context.Context) (string, error) {
+	if RELATIVE && _activeURL != nil {
+		relPath, err := filepath.Rel(filepath.Dir(_activeURL.Value()), url)
+		if err != nil {
+			return "", err
+		}
+
+		scope.NotifyRelativeURL(ctx.Path())
+		return relPath, nil
+	}
+
+	return url, nil
 }
 
 func evalFileURL(scope Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
@@ -50,8 +99,8 @@ func evalFileURL(scope Scope, args_ *tokens.Parens, ctx context.Context) (tokens
     return GetActiveURL(ctx)
   }
 
-	if len(args) != 1 {
-		return nil, ctx.NewError("Error: expected 0 or 1 arguments")
+	if len(args) != 1 && len(args) != 2 {
+		return nil, ctx.NewError("Error: expected 0, 1 or 2 arguments")
   }
 
   arg0, err := args[0].Eval(scope)
@@ -66,15 +115,26 @@ func evalFileURL(scope Scope, args_ *tokens.Parens, ctx context.Context) (tokens
 
   filePath := filePathToken.Value()
 
-	if url, ok := _fileURLs[filePath]; ok {
-    if (RELATIVE && _activeURL != nil) {
-      relPath, err := filepath.Rel(filepath.Dir(_activeURL.Value()), url)
-      if err != nil {
-        return nil, err
-      }
+  // the url in another locale can be requested explicitly
+  lang := _activeLocale
+  if len(args) == 2 {
+    arg1, err := args[1].Eval(scope)
+    if err != nil {
+      return nil, err
+    }
+
+    langToken, err := tokens.AssertString(arg1)
+    if err != nil {
+      return nil, err
+    }
 
-      scope.NotifyRelativeURL(ctx.Path())
-      url = relPath
+    lang = langToken.Value()
+  }
+
+	if url, ok := lookupFileURL(filePath, lang); ok {
+    url, err = relativeURL(scope, url, ctx)
+    if err != nil {
+      return nil, err
     }
 
     return tokens.NewValueString(url, ctx), nil
@@ -87,3 +147,38 @@ func evalFileURL(scope Scope, args_ *tokens.Parens, ctx context.Context) (tokens
     }
 	}
 }
+
+// url of the active page in another locale, eg. for language switchers
+func evalLocaleURL(scope Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
+  args, err := functions.CompleteArgs(args_, nil)
+  if err != nil {
+    return nil, err
+  }
+
+	if len(args) != 1 {
+		return nil, ctx.NewError("Error: expected 1 argument")
+  }
+
+  arg0, err := args[0].Eval(scope)
+  if err != nil {
+    return nil, err
+  }
+
+  langToken, err := tokens.AssertString(arg0)
+  if err != nil {
+    return nil, err
+  }
+
+  url, ok := _activeLocaleURLs[langToken.Value()]
+  if !ok {
+    errCtx := langToken.Context()
+    return nil, errCtx.NewError("Error: url for locale \"" + langToken.Value() + "\" not set")
+  }
+
+  url, err = relativeURL(scope, url, ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  return tokens.NewValueString(url, ctx), nil
+}
@@ -0,0 +1,137 @@
+package directives
+
+import (
+	"strconv"
+	"strings"
+)
+
+// cardinal plural categories (zero, one, two, few, many, other) of a number in plain decimal notation (eg. "3" or "1.5"), following the CLDR rules
+// languages that aren't listed use the english rules
+func pluralCategory(lang string, n string) string {
+	i, f, v := pluralOperands(n)
+
+	mod10 := i % 10
+	mod100 := i % 100
+
+	fMod10 := f % 10
+	fMod100 := f % 100
+
+	switch baseLang(lang) {
+	case "ja", "zh", "ko", "vi", "th", "id", "ms", "tr":
+		return "other"
+	case "fr":
+		if i == 0 || i == 1 {
+			return "one"
+		}
+
+		return "other"
+	case "hi", "fa":
+		if i == 0 || (i == 1 && f == 0) {
+			return "one"
+		}
+
+		return "other"
+	case "ru", "uk", "be":
+		switch {
+		case v != 0:
+			return "other"
+		case mod10 == 1 && mod100 != 11:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && !(mod100 >= 12 && mod100 <= 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "sr", "hr", "bs":
+		// the fraction digits follow the same rules as the integer digits
+		switch {
+		case (v == 0 && mod10 == 1 && mod100 != 11) || (fMod10 == 1 && fMod100 != 11):
+			return "one"
+		case (v == 0 && mod10 >= 2 && mod10 <= 4 && !(mod100 >= 12 && mod100 <= 14)) ||
+			(fMod10 >= 2 && fMod10 <= 4 && !(fMod100 >= 12 && fMod100 <= 14)):
+			return "few"
+		default:
+			return "other"
+		}
+	case "pl":
+		switch {
+		case v != 0:
+			return "other"
+		case i == 1:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && !(mod100 >= 12 && mod100 <= 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "cs", "sk":
+		switch {
+		case v != 0:
+			return "many"
+		case i == 1:
+			return "one"
+		case i >= 2 && i <= 4:
+			return "few"
+		default:
+			return "other"
+		}
+	case "ar":
+		switch {
+		case v != 0:
+			return "other"
+		case i == 0:
+			return "zero"
+		case i == 1:
+			return "one"
+		case i == 2:
+			return "two"
+		case mod100 >= 3 && mod100 <= 10:
+			return "few"
+		case mod100 >= 11:
+			return "many"
+		default:
+			return "other"
+		}
+	default:
+		if i == 1 && v == 0 {
+			return "one"
+		}
+
+		return "other"
+	}
+}
+
+// "-12.05" -> i=12, f=5, v=2 (integer digits, visible fraction digits, number of visible fraction digits)
+func pluralOperands(n string) (int, int, int) {
+	n = strings.TrimPrefix(n, "-")
+
+	iStr, fStr := n, ""
+	if dot := strings.Index(n, "."); dot != -1 {
+		iStr, fStr = n[0:dot], n[dot+1:]
+	}
+
+	i, _ := strconv.Atoi(iStr)
+	f, _ := strconv.Atoi(fStr)
+
+	return i, f, len(fStr)
+}
+
+func isPluralCategory(s string) bool {
+	switch s {
+	case "zero", "one", "two", "few", "many", "other":
+		return true
+	default:
+		return false
+	}
+}
+
+// "pt-BR" -> "pt"
+func baseLang(lang string) string {
+	for i, c := range lang {
+		if c == '-' || c == '_' {
+			return lang[0:i]
+		}
+	}
+
+	return lang
+}
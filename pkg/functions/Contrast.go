@@ -0,0 +1,78 @@
+package functions
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tree"
+)
+
+// WCAG contrast ratio (between 1 and 21) of a foreground color on a background color
+func Contrast(scope tokens.Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
+	args, err := CompleteArgs(args_, NewInterface([]string{"a", "b"}, ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	fg, err := tokens.AssertColor(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	bg, err := tokens.AssertColor(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens.NewValueFloat(tree.ColorContrast(fg, bg), ctx), nil
+}
+
+// the candidate (black or white by default) with the highest contrast on the background
+func ReadableOn(scope tokens.Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
+	defaultCandidates := tokens.NewValuesList([]tokens.Token{
+		tokens.NewValueColor(0, 0, 0, 255, ctx),
+		tokens.NewValueColor(255, 255, 255, 255, ctx),
+	}, ctx)
+
+	args, err := CompleteArgs(args_, tokens.NewParensInterf([]string{"bg", "candidates"},
+		[]tokens.Token{nil, defaultCandidates}, ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	bg, err := tokens.AssertColor(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := tokens.AssertList(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var best *tokens.Color = nil
+	bestRatio := 0.0
+	if err := candidates.Loop(func(i int, c_ tokens.Token, last bool) error {
+		c, err := tokens.AssertColor(c_)
+		if err != nil {
+			return err
+		}
+
+		if ratio := tree.ColorContrast(c, bg); best == nil || ratio > bestRatio {
+			best = c
+			bestRatio = ratio
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if best == nil {
+		errCtx := args[1].Context()
+		return nil, errCtx.NewError("Error: expected at least one candidate")
+	}
+
+	r, g, b, a := best.Values()
+
+	return tokens.NewValueColor(r, g, b, a, ctx), nil
+}
@@ -0,0 +1,37 @@
+package functions
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// eg. hsl(220, 100%, 60%) or hsl(220deg, 1.0, 0.6, 50%)
+func HSL(scope tokens.Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
+	args, err := CompleteArgs(args_, tokens.NewParensInterf([]string{"h", "s", "l", "a"},
+		[]tokens.Token{nil, nil, nil, tokens.NewValueFloat(1.0, ctx)}, ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := assertAngle(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := assertFraction(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := assertFraction(args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := assertFraction(args[3])
+	if err != nil {
+		return nil, err
+	}
+
+	return hslToColor(h, s, l, toByte(a), ctx), nil
+}
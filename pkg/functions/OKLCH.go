@@ -0,0 +1,42 @@
+package functions
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// eg. oklch(70%, 0.15, 250), colors outside the srgb gamut lose chroma
+func OKLCH(scope tokens.Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
+	args, err := CompleteArgs(args_, tokens.NewParensInterf([]string{"l", "c", "h", "a"},
+		[]tokens.Token{nil, nil, nil, tokens.NewValueFloat(1.0, ctx)}, ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := assertFraction(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := tokens.AssertIntOrFloat(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Value() < 0.0 {
+		errCtx := args[1].Context()
+		return nil, errCtx.NewError("Error: expected positive chroma")
+	}
+
+	h, err := assertAngle(args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := assertFraction(args[3])
+	if err != nil {
+		return nil, err
+	}
+
+	return oklchToColor(l, c.Value(), h, toByte(a), ctx), nil
+}
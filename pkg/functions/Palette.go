@@ -0,0 +1,59 @@
+package functions
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// tints are mixed with white, shades with black
+var _paletteSteps = []struct {
+	key string
+	f   float64 // negative for shades
+}{
+	{"50", 0.95},
+	{"100", 0.9},
+	{"200", 0.75},
+	{"300", 0.6},
+	{"400", 0.3},
+	{"500", 0.0},
+	{"600", -0.15},
+	{"700", -0.3},
+	{"800", -0.45},
+	{"900", -0.6},
+	{"950", -0.75},
+}
+
+// dict with the keys 50, 100, 200, ..., 900, 950, where 500 is the color itself
+// eg. "@tokens blue": palette(#3366ff) gives --blue-50 ... --blue-950
+func Palette(scope tokens.Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
+	args, err := CompleteArgs(args_, NewInterface([]string{"color"}, ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	color, err := tokens.AssertColor(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	white := tokens.NewValueColor(255, 255, 255, 255, ctx)
+	black := tokens.NewValueColor(0, 0, 0, 255, ctx)
+
+	res := tokens.NewEmptyStringDict(ctx)
+	for _, step := range _paletteSteps {
+		var c *tokens.Color
+		if step.f >= 0.0 {
+			c = mixColors(color, white, step.f, ctx)
+		} else {
+			c = mixColors(color, black, -step.f, ctx)
+		}
+
+		// keep the alpha of the base color
+		r, g, b, _ := c.Values()
+		_, _, _, a := color.Values()
+
+		res.Set(tokens.NewValueString(step.key, ctx), tokens.NewValueColor(r, g, b, a, ctx))
+	}
+
+	return res, nil
+}
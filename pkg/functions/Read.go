@@ -1,12 +1,16 @@
 package functions
 
 import (
+  "encoding/csv"
   "encoding/json"
   "io/ioutil"
+  "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/files"
+	"github.com/wtsuite/wtsuite/pkg/parsers"
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
 	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tree"
 )
 
 func Read(scope tokens.Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
@@ -59,8 +63,140 @@ func Read(scope tokens.Scope, args_ *tokens.Parens, ctx context.Context) (tokens
     }
 
     return tokens.GolangToToken(obj, ctx)
+  case "csv":
+    return readCSV(b, ',', fPath, ctx)
+  case "tsv":
+    return readCSV(b, '\t', fPath, ctx)
+  case "front-matter":
+    fm, _, ok := parsers.SplitFrontMatter(strings.ReplaceAll(string(b), "\r\n", "\n"))
+    if !ok {
+      return tokens.NewEmptyStringDict(ctx), nil
+    }
+
+    obj, err := parsers.ParseFrontMatter(fm, fPath.Context())
+    if err != nil {
+      return nil, err
+    }
+
+    return tokens.GolangToToken(obj, ctx)
+  case "markdown":
+    p, err := parsers.NewMarkdownParser(string(b), fPathAbs.Value())
+    if err != nil {
+      return nil, err
+    }
+
+    tags, err := p.BuildTags()
+    if err != nil {
+      return nil, err
+    }
+
+    html, err := writeMarkdownTags(scope, tags)
+    if err != nil {
+      return nil, err
+    }
+
+    return tokens.NewValueString(html, ctx), nil
   default:
     errCtx := args[1].Context()
-    return nil, errCtx.NewError("Error: encoding \"" + encoding + "\" not handled (hint: utf-8 or json)")
+    return nil, errCtx.NewError("Error: encoding \"" + encoding + "\" not handled (hint: utf-8, json, csv, tsv, front-matter or markdown)")
+  }
+}
+
+// the header row determines the keys of each row dict
+// numbers and bools are detected, all other fields remain strings
+func readCSV(b []byte, comma rune, fPath *tokens.String, ctx context.Context) (tokens.Token, error) {
+  r := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(b), "\uFEFF")))
+  r.Comma = comma
+  r.TrimLeadingSpace = true
+
+  records, err := r.ReadAll()
+  if err != nil {
+    errCtx := fPath.Context()
+    return nil, errCtx.NewError("Error: " + err.Error())
+  }
+
+  if len(records) == 0 {
+    errCtx := fPath.Context()
+    return nil, errCtx.NewError("Error: header row not found")
+  }
+
+  header := records[0]
+  for i, key := range header {
+    if key == "" {
+      errCtx := fPath.Context()
+      return nil, errCtx.NewError("Error: empty column name in header row")
+    }
+
+    for _, other := range header[0:i] {
+      if other == key {
+        errCtx := fPath.Context()
+        return nil, errCtx.NewError("Error: duplicate column name \"" + key + "\" in header row")
+      }
+    }
   }
+
+  rows := make([]interface{}, 0, len(records)-1)
+  for _, record := range records[1:] {
+    row := make(map[string]interface{})
+    for i, field := range record {
+      row[header[i]] = parseCSVField(field)
+    }
+
+    rows = append(rows, row)
+  }
+
+  return tokens.GolangToToken(rows, ctx)
+}
+
+func parseCSVField(field string) interface{} {
+  switch v := parsers.ParseScalarString(field).(type) {
+  case nil:
+    // empty fields, and null-like words, remain strings
+    return field
+  default:
+    return v
+  }
+}
+
+// markdown tags are plain html tags, so they can be converted directly
+func buildMarkdownTag(scope tokens.Scope, tag *tokens.Tag) (tree.Tag, error) {
+  if tag.IsText() {
+    return tree.NewText(tag.Text(), tag.Context()), nil
+  }
+
+  attr, err := tag.RawAttributes().EvalStringDict(scope)
+  if err != nil {
+    return nil, err
+  }
+
+  t, err := tree.BuildTag(tag.Name(), attr, tag.Context())
+  if err != nil {
+    return nil, err
+  }
+
+  for _, child := range tag.Children() {
+    c, err := buildMarkdownTag(scope, child)
+    if err != nil {
+      return nil, err
+    }
+
+    t.AppendChild(c)
+  }
+
+  return t, nil
+}
+
+func writeMarkdownTags(scope tokens.Scope, tags []*tokens.Tag) (string, error) {
+  var b strings.Builder
+
+  for _, tag := range tags {
+    t, err := buildMarkdownTag(scope, tag)
+    if err != nil {
+      return "", err
+    }
+
+    b.WriteString(t.Write("", "", ""))
+  }
+
+  return b.String(), nil
 }
@@ -0,0 +1,31 @@
+package functions
+
+import (
+	"math"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// the hsl hue is rotated, eg. rotate-hue(color, 180) for the complementary color
+func RotateHue(scope tokens.Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
+	args, err := CompleteArgs(args_, NewInterface([]string{"color", "angle"}, ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	color, err := tokens.AssertColor(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	angle, err := assertAngle(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	h, s, l := colorToHSL(color)
+	_, _, _, a := color.Values()
+
+	return hslToColor(math.Mod(h+angle, 360.0), s, l, a, ctx), nil
+}
@@ -0,0 +1,37 @@
+package functions
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// the amount is added to the hsl saturation
+func addColorSaturation(args_ *tokens.Parens, sign float64, ctx context.Context) (tokens.Token, error) {
+	args, err := CompleteArgs(args_, NewInterface([]string{"color", "amount"}, ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	color, err := tokens.AssertColor(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := assertFraction(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	h, s, l := colorToHSL(color)
+	_, _, _, a := color.Values()
+
+	return hslToColor(h, s+sign*amount, l, a, ctx), nil
+}
+
+func Saturate(scope tokens.Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
+	return addColorSaturation(args_, 1.0, ctx)
+}
+
+func Desaturate(scope tokens.Scope, args_ *tokens.Parens, ctx context.Context) (tokens.Token, error) {
+	return addColorSaturation(args_, -1.0, ctx)
+}
@@ -0,0 +1,207 @@
+package functions
+
+import (
+	"math"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// unitless (degrees), deg, rad, grad or turn, result in degrees in [0, 360)
+func assertAngle(t tokens.Token) (float64, error) {
+	f, err := tokens.AssertAnyIntOrFloat(t)
+	if err != nil {
+		return 0.0, err
+	}
+
+	deg := 0.0
+	switch f.Unit() {
+	case "", "deg":
+		deg = f.Value()
+	case "rad":
+		deg = f.Value() * 180.0 / math.Pi
+	case "grad":
+		deg = f.Value() * 0.9
+	case "turn":
+		deg = f.Value() * 360.0
+	default:
+		errCtx := t.Context()
+		return 0.0, errCtx.NewError("Error: expected angle (unitless, deg, rad, grad or turn)")
+	}
+
+	deg = math.Mod(deg, 360.0)
+	if deg < 0.0 {
+		deg += 360.0
+	}
+
+	return deg, nil
+}
+
+// unitless in [0, 1] or %, ints are also accepted (eg. 0)
+func assertFraction(t tokens.Token) (float64, error) {
+	if tokens.IsInt(t) {
+		f, err := tokens.AssertIntOrFloat(t)
+		if err != nil {
+			return 0.0, err
+		}
+
+		t = f
+	}
+
+	f, err := tokens.AssertFractionFloat(t)
+	if err != nil {
+		return 0.0, err
+	}
+
+	return f.Value(), nil
+}
+
+func clampFraction(x float64) float64 {
+	return math.Max(0.0, math.Min(1.0, x))
+}
+
+func toByte(x float64) int {
+	return int(math.Round(clampFraction(x) * 255.0))
+}
+
+func mixColors(color1 *tokens.Color, color2 *tokens.Color, f float64, ctx context.Context) *tokens.Color {
+	r1, g1, b1, a1 := color1.Values()
+	r2, g2, b2, a2 := color2.Values()
+
+	mix := func(a int, b int) int {
+		m := float64(a)*(1.0-f) + float64(b)*f
+
+		mInt := int(math.Round(m))
+
+		if mInt < 0 {
+			return 0
+		} else if mInt > 255 {
+			return 255
+		} else {
+			return mInt
+		}
+	}
+
+	return tokens.NewValueColor(mix(r1, r2), mix(g1, g2), mix(b1, b2), mix(a1, a2), ctx)
+}
+
+// h in degrees, s and l in [0, 1]
+func colorToHSL(color *tokens.Color) (float64, float64, float64) {
+	r, g, b, _ := color.FloatValues()
+
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	l := (max + min) / 2.0
+
+	if max == min {
+		return 0.0, 0.0, l
+	}
+
+	d := max - min
+
+	s := d / (1.0 - math.Abs(2.0*l-1.0))
+
+	h := 0.0
+	switch max {
+	case r:
+		h = math.Mod((g-b)/d+6.0, 6.0)
+	case g:
+		h = (b-r)/d + 2.0
+	default:
+		h = (r-g)/d + 4.0
+	}
+
+	return h * 60.0, s, l
+}
+
+func hslToColor(h, s, l float64, a int, ctx context.Context) *tokens.Color {
+	s = clampFraction(s)
+	l = clampFraction(l)
+
+	f := func(n float64) float64 {
+		k := math.Mod(n+h/30.0, 12.0)
+		return l - s*math.Min(l, 1.0-l)*math.Max(-1.0, math.Min(k-3.0, math.Min(9.0-k, 1.0)))
+	}
+
+	return tokens.NewValueColor(toByte(f(0.0)), toByte(f(8.0)), toByte(f(4.0)), a, ctx)
+}
+
+func srgbToLinear(x float64) float64 {
+	if x <= 0.04045 {
+		return x / 12.92
+	}
+
+	return math.Pow((x+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(x float64) float64 {
+	if x <= 0.0031308 {
+		return 12.92 * x
+	}
+
+	return 1.055*math.Pow(x, 1.0/2.4) - 0.055
+}
+
+// l in [0, 1], c is unbounded (but rarely above 0.4), h in degrees
+func colorToOKLCH(color *tokens.Color) (float64, float64, float64) {
+	r_, g_, b_, _ := color.FloatValues()
+	r, g, b := srgbToLinear(r_), srgbToLinear(g_), srgbToLinear(b_)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	L := 0.2104542553*l + 0.7936177850*m - 0.0040720468*s
+	A := 1.9779984951*l - 2.4285922050*m + 0.4505937099*s
+	B := 0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+
+	h := math.Atan2(B, A) * 180.0 / math.Pi
+	if h < 0.0 {
+		h += 360.0
+	}
+
+	return L, math.Sqrt(A*A + B*B), h
+}
+
+// linear srgb components, which can be out of gamut
+func oklchToLinear(L, C, h float64) (float64, float64, float64) {
+	A := C * math.Cos(h*math.Pi/180.0)
+	B := C * math.Sin(h*math.Pi/180.0)
+
+	l := L + 0.3963377774*A + 0.2158037573*B
+	m := L - 0.1055613458*A - 0.0638541728*B
+	s := L - 0.0894841775*A - 1.2914855480*B
+
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	return 4.0767416621*l - 3.3077115913*m + 0.2309699292*s,
+		-1.2684380046*l + 2.6097574011*m - 0.3413193965*s,
+		-0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+}
+
+// colors outside the srgb gamut keep their lightness and hue, but lose chroma
+func oklchToColor(L, C, h float64, a int, ctx context.Context) *tokens.Color {
+	inGamut := func(c float64) bool {
+		r, g, b := oklchToLinear(L, c, h)
+		eps := 1e-6
+		return r >= -eps && r <= 1.0+eps && g >= -eps && g <= 1.0+eps && b >= -eps && b <= 1.0+eps
+	}
+
+	if !inGamut(C) {
+		lo, hi := 0.0, C
+		for i := 0; i < 24; i++ {
+			mid := (lo + hi) / 2.0
+			if inGamut(mid) {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+
+		C = lo
+	}
+
+	r, g, b := oklchToLinear(L, C, h)
+
+	return tokens.NewValueColor(toByte(linearToSRGB(r)), toByte(linearToSRGB(g)), toByte(linearToSRGB(b)), a, ctx)
+}
@@ -20,8 +20,10 @@ var preEval = map[string]BuiltinFunction{
 	"caps":              Caps,
 	"ceil":              Ceil,
 	"contains":          Contains,
+	"contrast":          Contrast,
 	"cos":               Cos,
 	"darken":            Darken,
+	"desaturate":        Desaturate,
 	"dict":              Dict,
 	"dir":               Dir,
 	"div":               Div,
@@ -36,6 +38,7 @@ var preEval = map[string]BuiltinFunction{
 	"ge":                GE,
 	"get":               Get, // differs from the get(string, [fallback]) function (see directives)
 	"gt":                GT,
+	"hsl":               HSL,
 	"int":               Int,
 	"invert":            Invert,
 	"isbool":            IsBool,
@@ -71,6 +74,8 @@ var preEval = map[string]BuiltinFunction{
 	"neg":               Neg,
   "noext":             NoExt,
 	"not":               Not,
+	"oklch":             OKLCH,
+	"palette":           Palette,
 	"pathpos":           SVGPathPos,
 	"pi":                Pi,
 	"pow":               Pow,
@@ -78,10 +83,13 @@ var preEval = map[string]BuiltinFunction{
 	"rad":               Rad, // degrees to rad function
 	"rand":              Rand,
   "read":              Read,
+  "readable-on":       ReadableOn,
   "rel":               Rel,
 	"replace":           Replace,
   "reverse":           Reverse,
+  "rotate-hue":        RotateHue,
 	"round":             Round,
+	"saturate":          Saturate,
 	"seq":               Seq,
 	"sin":               Sin,
 	"slice":             Slice,
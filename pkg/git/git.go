@@ -636,6 +636,87 @@ func HeadHash(dir string) (string, error) {
   return headRef.Hash().String(), nil
 }
 
+// times of the last commits that touched files, every repository is only opened once and every file is only looked up once
+type CommitTimes struct {
+  repos map[string]*gitcore.Repository // worktree root -> repository
+  times map[string]time.Time
+  errs  map[string]error
+}
+
+func NewCommitTimes() *CommitTimes {
+  return &CommitTimes{make(map[string]*gitcore.Repository), make(map[string]time.Time), make(map[string]error)}
+}
+
+// the repository is detected in one of the parent directories
+func (c *CommitTimes) repo(path string) (*gitcore.Repository, string, error) {
+  for root, repo := range c.repos {
+    if relPath, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(relPath, "..") {
+      return repo, relPath, nil
+    }
+  }
+
+  repo, err := gitcore.PlainOpenWithOptions(filepath.Dir(path), &gitcore.PlainOpenOptions{DetectDotGit: true})
+  if err != nil {
+    return nil, "", err
+  }
+
+  wt, err := repo.Worktree()
+  if err != nil {
+    return nil, "", err
+  }
+
+  root := wt.Filesystem.Root()
+  c.repos[root] = repo
+
+  relPath, err := filepath.Rel(root, path)
+  if err != nil {
+    return nil, "", err
+  }
+
+  return repo, relPath, nil
+}
+
+// time of the last commit that touched the file
+func (c *CommitTimes) LastCommitTime(path string) (time.Time, error) {
+  if t, ok := c.times[path]; ok {
+    return t, nil
+  } else if err, ok := c.errs[path]; ok {
+    return time.Time{}, err
+  }
+
+  t, err := c.lastCommitTime(path)
+  if err != nil {
+    c.errs[path] = err
+  } else {
+    c.times[path] = t
+  }
+
+  return t, err
+}
+
+func (c *CommitTimes) lastCommitTime(path string) (time.Time, error) {
+  repo, relPath, err := c.repo(path)
+  if err != nil {
+    return time.Time{}, err
+  }
+
+  relPath = filepath.ToSlash(relPath)
+
+  iter, err := repo.Log(&gitcore.LogOptions{FileName: &relPath})
+  if err != nil {
+    return time.Time{}, err
+  }
+
+  defer iter.Close()
+
+  commit, err := iter.Next()
+  if err != nil {
+    return time.Time{}, errors.New(path + " not committed")
+  }
+
+  return commit.Committer.When, nil
+}
+
 func FetchPublicOrPrivate(url string, svr *files.SemVerRange) (string, error) {
   dstBase := files.PkgInstallDir(url)
 
@@ -112,10 +112,16 @@ func (p *CLIParser) Error(msg string) error {
   return errors.New(b.String())
 }
 
-// returns nil if not found, or if ambiguous
+// returns nil if not found, or if ambiguous (an exact match is never ambiguous, eg. --minify-css vs --minify-css-unsafe)
 func (p *CLIParser) findLongOption(key string) CLIOption {
   var res CLIOption = nil
 
+  for _, opt := range p.options {
+    if opt.Long() == key {
+      return opt
+    }
+  }
+
   for _, opt := range p.options {
     if strings.HasPrefix(opt.Long(), key) {
       if res != nil {
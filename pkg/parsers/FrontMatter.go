@@ -0,0 +1,272 @@
+package parsers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+// YAML-style front-matter is a block at the very start of a file, delimited by "---" lines:
+//  ---
+//  title: Hello world
+//  tags: [go, web]
+//  draft: false
+//  ---
+// 'key: value' pairs, nested by indentation, and lists of scalars (inline or block) are supported
+// values are converted into basic golang types so they can be turned into tokens with tokens.GolangToToken
+
+var frontMatterKeyRegexp = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_\-]*)[ \t]*:(?:[ \t]+(.*))?$`)
+
+var frontMatterIntRegexp = regexp.MustCompile(`^[-+]?(?:0|[1-9][0-9]*)$`) // leading zeros are kept as strings (eg. zip codes)
+
+var frontMatterFloatRegexp = regexp.MustCompile(`^[-+]?(?:(?:0|[1-9][0-9]*)\.[0-9]*|\.[0-9]+)(?:[eE][-+]?[0-9]+)?$`)
+
+// returns the front-matter content, and the rune offset of the remaining body
+// ok is false if the raw string doesn't start with a front-matter block
+func SplitFrontMatter(raw string) (string, int, bool) {
+	if !strings.HasPrefix(raw, "---\n") {
+		return "", 0, false
+	}
+
+	pos := 4
+	for pos <= len(raw) {
+		end := strings.IndexByte(raw[pos:], '\n')
+		var line string
+		if end == -1 {
+			line = raw[pos:]
+			end = len(raw)
+		} else {
+			line = raw[pos : pos+end]
+			end = pos + end + 1
+		}
+
+		if strings.TrimRight(line, " \t") == "---" {
+			return raw[4:pos], utf8.RuneCountInString(raw[0:end]), true
+		}
+
+		if end >= len(raw) {
+			break
+		}
+
+		pos = end
+	}
+
+	return "", 0, false
+}
+
+type frontMatterLine struct {
+	indent int
+	text   string
+}
+
+func ParseFrontMatter(raw string, ctx context.Context) (map[string]interface{}, error) {
+	lines := make([]frontMatterLine, 0)
+	for _, line := range strings.Split(raw, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(strings.TrimLeft(line, " "), "\t") {
+			return nil, ctx.NewError("Error: front-matter can't be indented with tabs")
+		}
+
+		lines = append(lines, frontMatterLine{len(line) - len(strings.TrimLeft(line, " ")), trimmed})
+	}
+
+	if len(lines) > 0 && lines[0].indent != 0 {
+		return nil, ctx.NewError("Error: unexpected front-matter indentation")
+	}
+
+	return parseFrontMatterMap(lines, ctx)
+}
+
+// lines with deeper indentation than the first line belong to the nested block of the previous key
+func nestedFrontMatterBlock(lines []frontMatterLine, indent int) []frontMatterLine {
+	n := 0
+	for n < len(lines) && lines[n].indent > indent {
+		n++
+	}
+
+	return lines[0:n]
+}
+
+func parseFrontMatterBlock(lines []frontMatterLine, ctx context.Context) (interface{}, error) {
+	if len(lines) > 0 && (strings.HasPrefix(lines[0].text, "- ") || lines[0].text == "-") {
+		return parseFrontMatterList(lines, ctx)
+	} else {
+		return parseFrontMatterMap(lines, ctx)
+	}
+}
+
+func parseFrontMatterMap(lines []frontMatterLine, ctx context.Context) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if line.indent != lines[0].indent {
+			return nil, ctx.NewError("Error: bad front-matter indentation of \"" + line.text + "\"")
+		}
+
+		groups := frontMatterKeyRegexp.FindStringSubmatch(line.text)
+		if groups == nil {
+			return nil, ctx.NewError("Error: bad front-matter line \"" + line.text + "\"")
+		}
+
+		key := groups[1]
+		if _, ok := result[key]; ok {
+			return nil, ctx.NewError("Error: duplicate front-matter key " + key)
+		}
+
+		nested := nestedFrontMatterBlock(lines[i+1:], line.indent)
+		// block lists can also start at the same indentation as the key
+		if len(nested) == 0 {
+			for j := i + 1; j < len(lines) && lines[j].indent == line.indent && strings.HasPrefix(lines[j].text, "-"); j++ {
+				nested = lines[i+1 : j+1]
+			}
+		}
+
+		value_ := strings.TrimSpace(groups[2])
+
+		var value interface{}
+		var err error
+		if len(nested) > 0 {
+			if value_ != "" {
+				return nil, ctx.NewError("Error: front-matter key " + key + " can't have both a value and a nested block")
+			}
+
+			value, err = parseFrontMatterBlock(nested, ctx)
+			i += len(nested)
+		} else {
+			value, err = parseFrontMatterValue(value_, ctx)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+func parseFrontMatterList(lines []frontMatterLine, ctx context.Context) ([]interface{}, error) {
+	result := make([]interface{}, 0)
+
+	for _, line := range lines {
+		if line.indent != lines[0].indent || !(strings.HasPrefix(line.text, "- ") || line.text == "-") {
+			return nil, ctx.NewError("Error: bad front-matter list item \"" + line.text + "\"")
+		}
+
+		item, err := parseFrontMatterValue(strings.TrimSpace(strings.TrimPrefix(line.text, "-")), ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+func parseFrontMatterValue(s string, ctx context.Context) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(s, "\"") || strings.HasPrefix(s, "'"):
+		if len(s) < 2 || s[len(s)-1] != s[0] {
+			return nil, ctx.NewError("Error: unterminated front-matter string " + s)
+		}
+
+		if s[0] == '"' {
+			str, err := strconv.Unquote(s)
+			if err != nil {
+				return nil, ctx.NewError("Error: bad front-matter string " + s)
+			}
+
+			return str, nil
+		} else {
+			return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+		}
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, ctx.NewError("Error: unterminated front-matter list " + s)
+		}
+
+		lst := make([]interface{}, 0)
+		inner := strings.TrimSpace(s[1 : len(s)-1])
+		if inner == "" {
+			return lst, nil
+		}
+
+		for _, item_ := range splitFrontMatterList(inner) {
+			item, err := parseFrontMatterValue(strings.TrimSpace(item_), ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			lst = append(lst, item)
+		}
+
+		return lst, nil
+	default:
+		// strip trailing comment
+		if i := strings.Index(s, " #"); i != -1 {
+			s = strings.TrimSpace(s[0:i])
+		}
+
+		return ParseScalarString(s), nil
+	}
+}
+
+// split by commas that aren't inside quotes
+func splitFrontMatterList(s string) []string {
+	result := make([]string, 0)
+
+	var quote byte = 0
+	prev := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			result = append(result, s[prev:i])
+			prev = i + 1
+		}
+	}
+
+	return append(result, s[prev:])
+}
+
+// detects null, bool, int and float values, everything else is returned as a string
+func ParseScalarString(s string) interface{} {
+	switch {
+	case s == "" || s == "null" || s == "~":
+		return nil
+	case s == "true":
+		return true
+	case s == "false":
+		return false
+	case frontMatterIntRegexp.MatchString(s):
+		if i, err := strconv.Atoi(s); err == nil {
+			return i
+		}
+
+		return s
+	case frontMatterFloatRegexp.MatchString(s):
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+
+		return s
+	default:
+		return s
+	}
+}
@@ -20,11 +20,15 @@ func (p *GLSLParser) buildFunctionArgumentRole(ts  []raw.Token) (glsl.FunctionAr
 
       switch w.Value() {
       case "in":
-        role = role & glsl.IN_ROLE
+        role = role | glsl.IN_ROLE
         iRemaining = i+1
         continue
       case "out":
-        role = role & glsl.OUT_ROLE
+        role = role | glsl.OUT_ROLE
+        iRemaining = i+1
+        continue
+      case "inout":
+        role = role | glsl.IN_ROLE | glsl.OUT_ROLE
         iRemaining = i+1
         continue
       default:
@@ -0,0 +1,810 @@
+package parsers
+
+import (
+	"errors"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// CommonMark block structure, with GFM tables and strikethrough
+// the result is a list of html tags that can be built like any other template tags
+
+type mdLine struct {
+	text  string
+	start int // rune offset in source
+}
+
+type mdLinkRef struct {
+	url   string
+	title string
+}
+
+type MarkdownParser struct {
+	ctx         context.Context
+	lines       []mdLine
+	frontMatter map[string]interface{}
+	fmCtx       context.Context
+	refs        map[string]mdLinkRef
+}
+
+var (
+	mdATXHeadingRegexp    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*))?$`)
+	mdThematicBreakRegexp = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdSetextRegexp        = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mdFenceRegexp         = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*(.*)$")
+	mdBlockquoteRegexp    = regexp.MustCompile(`^ {0,3}> ?`)
+	mdListMarkerRegexp    = regexp.MustCompile(`^( {0,3})([-+*]|[0-9]{1,9}[.)])( +|$)`)
+	mdTableDelimRegexp    = regexp.MustCompile(`^ *\|? *:?-+:? *(?:\| *:?-+:? *)*\|? *$`)
+	mdLinkRefRegexp       = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*(<[^>]*>|[^ \t]+)(?:[ \t]+("[^"]*"|'[^']*'|\([^)]*\)))?[ \t]*$`)
+	mdHTMLBlockRegexp     = regexp.MustCompile(`^ {0,3}<(!--|/?[A-Za-z][A-Za-z0-9\-]*)(?:[ \t/>]|$)`)
+)
+
+var mdHTMLBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true,
+	"canvas": true, "details": true, "dialog": true, "div": true, "dl": true, "dd": true,
+	"dt": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "iframe": true, "li": true, "main": true, "nav": true,
+	"ol": true, "p": true, "pre": true, "script": true, "section": true, "style": true,
+	"summary": true, "table": true, "tbody": true, "td": true, "textarea": true,
+	"tfoot": true, "th": true, "thead": true, "tr": true, "ul": true, "video": true,
+}
+
+// NewMarkdownParser(path string) or
+// NewMarkdownParser(raw string, path string) path just for ref
+func NewMarkdownParser(args ...string) (*MarkdownParser, error) {
+	var raw string
+	var path string
+
+	switch len(args) {
+	case 1:
+		path = args[0]
+		rawBytes, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.New("Error: problem reading \"" + path + "\" (" + err.Error() + ")")
+		}
+
+		raw = string(rawBytes)
+	case 2:
+		raw = args[0]
+		path = args[1]
+	default:
+		panic("expected 1 or 2 arguments")
+	}
+
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+
+	src := context.NewSource(raw)
+	ctx := context.NewContext(src, path)
+
+	p := &MarkdownParser{
+		ctx:         ctx,
+		lines:       nil,
+		frontMatter: make(map[string]interface{}),
+		fmCtx:       ctx.NewContext(0, 0),
+		refs:        make(map[string]mdLinkRef),
+	}
+
+	body := raw
+	bodyStart := 0
+	if fm, offset, ok := SplitFrontMatter(raw); ok {
+		var err error
+		p.fmCtx = ctx.NewContext(0, offset)
+		p.frontMatter, err = ParseFrontMatter(fm, p.fmCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		body = string([]rune(raw)[offset:])
+		bodyStart = offset
+	}
+
+	p.lines = splitMarkdownLines(body, bodyStart)
+
+	return p, nil
+}
+
+func splitMarkdownLines(raw string, start int) []mdLine {
+	lines := make([]mdLine, 0)
+
+	for _, text := range strings.Split(raw, "\n") {
+		lines = append(lines, mdLine{expandMarkdownTabs(text), start})
+		start += utf8.RuneCountInString(text) + 1
+	}
+
+	// a trailing newline doesn't start a new line
+	if len(lines) > 0 && lines[len(lines)-1].text == "" {
+		lines = lines[0 : len(lines)-1]
+	}
+
+	return lines
+}
+
+// leading tabs are expanded to 4 spaces, so that indentation can be measured in bytes
+// (this shifts the contexts slightly, but only for tab indented lines)
+func expandMarkdownTabs(text string) string {
+	var b strings.Builder
+
+	col := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\t':
+			n := 4 - col%4
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		case ' ':
+			b.WriteByte(' ')
+			col += 1
+		default:
+			b.WriteString(text[i:])
+			return b.String()
+		}
+	}
+
+	return b.String()
+}
+
+func (p *MarkdownParser) FrontMatter() map[string]interface{} {
+	return p.frontMatter
+}
+
+// for errors related to front-matter values
+func (p *MarkdownParser) FrontMatterContext() context.Context {
+	return p.fmCtx
+}
+
+func (p *MarkdownParser) BuildTags() ([]*html.Tag, error) {
+	lines := p.collectLinkRefs(p.lines)
+
+	return p.buildBlocks(lines)
+}
+
+func (l mdLine) isBlank() bool {
+	return strings.TrimSpace(l.text) == ""
+}
+
+func (l mdLine) indent() int {
+	return len(l.text) - len(strings.TrimLeft(l.text, " "))
+}
+
+// remove n leading columns
+func (l mdLine) strip(n int) mdLine {
+	if n > len(l.text) {
+		n = len(l.text)
+	}
+
+	return mdLine{l.text[n:], l.start + n}
+}
+
+func (p *MarkdownParser) lineContext(l mdLine) context.Context {
+	return p.ctx.NewContext(l.start, l.start+utf8.RuneCountInString(l.text))
+}
+
+func (p *MarkdownParser) linesContext(lines []mdLine) context.Context {
+	first := lines[0]
+	last := lines[len(lines)-1]
+	return p.ctx.NewContext(first.start, last.start+utf8.RuneCountInString(last.text))
+}
+
+func normalizeMarkdownLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// link reference definitions can be used before they are defined, so they are collected first
+func (p *MarkdownParser) collectLinkRefs(lines []mdLine) []mdLine {
+	result := make([]mdLine, 0, len(lines))
+
+	fence := ""
+	prevBlank := true
+	for _, line := range lines {
+		if groups := matchMarkdownFence(line.text); groups != nil {
+			if fence == "" {
+				fence = groups[2]
+			} else if strings.HasPrefix(groups[2], fence) && strings.TrimSpace(groups[3]) == "" {
+				fence = ""
+			}
+		} else if fence == "" && prevBlank {
+			if groups := mdLinkRefRegexp.FindStringSubmatch(line.text); groups != nil {
+				key := normalizeMarkdownLabel(groups[1])
+				if _, ok := p.refs[key]; !ok {
+					url := strings.TrimSuffix(strings.TrimPrefix(groups[2], "<"), ">")
+					title := ""
+					if len(groups[3]) >= 2 {
+						title = groups[3][1 : len(groups[3])-1]
+					}
+
+					p.refs[key] = mdLinkRef{unescapeMarkdown(url), unescapeMarkdown(title)}
+				}
+
+				// subsequent definitions can follow directly
+				continue
+			}
+		}
+
+		prevBlank = line.isBlank()
+		result = append(result, line)
+	}
+
+	return result
+}
+
+// info strings of backtick fences can't contain backticks
+func matchMarkdownFence(text string) []string {
+	groups := mdFenceRegexp.FindStringSubmatch(text)
+	if groups != nil && groups[2][0] == '`' && strings.Contains(groups[3], "`") {
+		return nil
+	}
+
+	return groups
+}
+
+// can the line interrupt a paragraph?
+func (p *MarkdownParser) startsBlock(line mdLine) bool {
+	text := line.text
+
+	if mdATXHeadingRegexp.MatchString(text) || mdThematicBreakRegexp.MatchString(text) ||
+		matchMarkdownFence(text) != nil || mdBlockquoteRegexp.MatchString(text) {
+		return true
+	}
+
+	if _, ok := p.htmlBlockStop(text); ok {
+		return true
+	}
+
+	if groups := mdListMarkerRegexp.FindStringSubmatch(text); groups != nil {
+		// empty items and ordered lists not starting at 1 can't interrupt a paragraph
+		marker := groups[2]
+		if strings.TrimSpace(text[len(groups[0]):]) == "" {
+			return false
+		}
+
+		return len(marker) == 1 || marker == "1." || marker == "1)"
+	}
+
+	return false
+}
+
+func (p *MarkdownParser) buildBlocks(lines []mdLine) ([]*html.Tag, error) {
+	blocks, _, err := p.buildSeparatedBlocks(lines)
+	return blocks, err
+}
+
+// also returns true if any of the blocks are separated by blank lines (used for the looseness of list items)
+func (p *MarkdownParser) buildSeparatedBlocks(lines []mdLine) ([]*html.Tag, bool, error) {
+	result := make([]*html.Tag, 0)
+	separated := false
+	blank := false
+
+	i := 0
+	for i < len(lines) {
+		line := lines[i]
+
+		var tag *html.Tag
+		n := 0
+		var err error
+
+		switch {
+		case line.isBlank():
+			blank = len(result) > 0
+			i++
+			continue
+		case line.indent() >= 4:
+			tag, n = p.buildIndentedCode(lines[i:])
+		case matchMarkdownFence(line.text) != nil:
+			tag, n = p.buildFencedCode(lines[i:])
+		case mdATXHeadingRegexp.MatchString(line.text):
+			tag, err = p.buildATXHeading(line)
+			n = 1
+		case mdThematicBreakRegexp.MatchString(line.text):
+			tag = html.NewTag("hr", html.NewEmptyRawDict(p.lineContext(line)), []*html.Tag{}, p.lineContext(line))
+			n = 1
+		case mdBlockquoteRegexp.MatchString(line.text):
+			tag, n, err = p.buildBlockquote(lines[i:])
+		case mdListMarkerRegexp.MatchString(line.text):
+			tag, n, err = p.buildList(lines[i:])
+		default:
+			if stop, ok := p.htmlBlockStop(line.text); ok {
+				tag, n = p.buildHTMLBlock(lines[i:], stop)
+			} else if p.isTableStart(lines[i:]) {
+				tag, n, err = p.buildTable(lines[i:])
+			} else {
+				tag, n, err = p.buildParagraph(lines[i:])
+			}
+		}
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		separated = separated || blank
+		blank = false
+
+		result = append(result, tag)
+		i += n
+	}
+
+	return result, separated, nil
+}
+
+func (p *MarkdownParser) buildIndentedCode(lines []mdLine) (*html.Tag, int) {
+	n := 0
+	last := 0
+	for n < len(lines) && (lines[n].isBlank() || lines[n].indent() >= 4) {
+		if !lines[n].isBlank() {
+			last = n
+		}
+
+		n++
+	}
+
+	n = last + 1
+
+	content := make([]string, n)
+	for i, line := range lines[0:n] {
+		content[i] = line.strip(4).text
+	}
+
+	return p.newCodeBlock(strings.Join(content, "\n"), "", lines[0:n]), n
+}
+
+func (p *MarkdownParser) buildFencedCode(lines []mdLine) (*html.Tag, int) {
+	groups := matchMarkdownFence(lines[0].text)
+	indent := len(groups[1])
+	fence := groups[2]
+	info := strings.Fields(unescapeMarkdown(groups[3]))
+
+	lang := ""
+	if len(info) > 0 {
+		lang = info[0]
+	}
+
+	content := make([]string, 0)
+	n := 1
+	for ; n < len(lines); n++ {
+		line := lines[n]
+		if stop := matchMarkdownFence(line.text); stop != nil &&
+			stop[2][0] == fence[0] && len(stop[2]) >= len(fence) && strings.TrimSpace(stop[3]) == "" {
+			n++
+			break
+		}
+
+		strip := line.indent()
+		if strip > indent {
+			strip = indent
+		}
+
+		content = append(content, line.strip(strip).text)
+	}
+
+	return p.newCodeBlock(strings.Join(content, "\n"), lang, lines[0:n]), n
+}
+
+func (p *MarkdownParser) newCodeBlock(content string, lang string, lines []mdLine) *html.Tag {
+	ctx := p.linesContext(lines)
+
+	codeAttr := html.NewEmptyRawDict(ctx)
+	if lang != "" {
+		codeAttr.Set(html.NewValueString("class", ctx), html.NewValueString("language-"+escapeMarkdownAttr(lang), ctx))
+	}
+
+	code := html.NewTag("code", codeAttr, []*html.Tag{html.NewTextTag(escapeMarkdownText(content), ctx)}, ctx)
+
+	return html.NewTag("pre", html.NewEmptyRawDict(ctx), []*html.Tag{code}, ctx)
+}
+
+func (p *MarkdownParser) buildATXHeading(line mdLine) (*html.Tag, error) {
+	groups := mdATXHeadingRegexp.FindStringSubmatch(line.text)
+	level := len(groups[1])
+
+	content := strings.TrimSpace(groups[2])
+
+	// optional closing sequence
+	closing := strings.TrimRight(content, "#")
+	if closing == "" {
+		content = ""
+	} else if len(closing) < len(content) && (strings.HasSuffix(closing, " ") || strings.HasSuffix(closing, "\t")) {
+		content = strings.TrimSpace(closing)
+	}
+
+	return p.newHeading(level, content, p.lineContext(line))
+}
+
+func (p *MarkdownParser) newHeading(level int, content string, ctx context.Context) (*html.Tag, error) {
+	children, err := p.parseInline(content, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return html.NewTag("h"+strconv.Itoa(level), html.NewEmptyRawDict(ctx), children, ctx), nil
+}
+
+func (p *MarkdownParser) buildBlockquote(lines []mdLine) (*html.Tag, int, error) {
+	inner := make([]mdLine, 0)
+
+	n := 0
+	for ; n < len(lines); n++ {
+		line := lines[n]
+		if loc := mdBlockquoteRegexp.FindStringIndex(line.text); loc != nil {
+			inner = append(inner, line.strip(loc[1]))
+		} else if !line.isBlank() && len(inner) > 0 && !inner[len(inner)-1].isBlank() && !p.startsBlock(line) {
+			// lazy continuation
+			inner = append(inner, line)
+		} else {
+			break
+		}
+	}
+
+	children, err := p.buildBlocks(inner)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ctx := p.linesContext(lines[0:n])
+	return html.NewTag("blockquote", html.NewEmptyRawDict(ctx), children, ctx), n, nil
+}
+
+type mdListMarker struct {
+	ordered bool
+	char    byte // bullet char, or delimiter of ordered list
+	start   int
+	indent  int // content indentation
+}
+
+func parseMarkdownListMarker(line mdLine) (mdListMarker, bool) {
+	groups := mdListMarkerRegexp.FindStringSubmatch(line.text)
+	if groups == nil {
+		return mdListMarker{}, false
+	}
+
+	marker := groups[2]
+
+	indent := len(groups[1]) + len(marker)
+	if len(groups[3]) > 4 || groups[3] == "" {
+		// content is indented code, or item starts with a blank line
+		indent += 1
+	} else {
+		indent += len(groups[3])
+	}
+
+	if len(marker) == 1 {
+		return mdListMarker{false, marker[0], 0, indent}, true
+	}
+
+	start, err := strconv.Atoi(marker[0 : len(marker)-1])
+	if err != nil {
+		return mdListMarker{}, false
+	}
+
+	return mdListMarker{true, marker[len(marker)-1], start, indent}, true
+}
+
+// list items that aren't in thematic breaks
+func (p *MarkdownParser) isListItem(line mdLine) (mdListMarker, bool) {
+	if mdThematicBreakRegexp.MatchString(line.text) {
+		return mdListMarker{}, false
+	}
+
+	return parseMarkdownListMarker(line)
+}
+
+func (p *MarkdownParser) buildList(lines []mdLine) (*html.Tag, int, error) {
+	first, _ := p.isListItem(lines[0])
+
+	items := make([][]mdLine, 0)
+	loose := false
+
+	n := 0
+	for n < len(lines) {
+		marker, ok := p.isListItem(lines[n])
+		if !ok || marker.ordered != first.ordered || marker.char != first.char {
+			break
+		}
+
+		item := []mdLine{lines[n].strip(marker.indent)}
+		n++
+
+		for n < len(lines) {
+			line := lines[n]
+			prev := item[len(item)-1]
+			if line.isBlank() {
+				item = append(item, mdLine{"", line.start})
+			} else if line.indent() >= marker.indent {
+				item = append(item, line.strip(marker.indent))
+			} else if _, isItem := p.isListItem(line); !isItem && !prev.isBlank() && !p.startsBlock(line) {
+				// lazy continuation
+				item = append(item, line.strip(line.indent()))
+			} else {
+				break
+			}
+
+			n++
+		}
+
+		// trailing blank lines separate items
+		nBlank := 0
+		for len(item) > 1 && item[len(item)-1].isBlank() {
+			item = item[0 : len(item)-1]
+			nBlank++
+		}
+
+		items = append(items, item)
+
+		if nBlank > 0 {
+			if n < len(lines) {
+				if next, ok := p.isListItem(lines[n]); ok && next.ordered == first.ordered && next.char == first.char {
+					loose = true
+					continue
+				}
+			}
+
+			// blank lines are not part of the list
+			n -= nBlank
+			break
+		}
+	}
+
+	ctx := p.linesContext(lines[0:n])
+
+	// a list is loose if any item contains blank lines between its direct children
+	itemBlocks := make([][]*html.Tag, len(items))
+	for i, item := range items {
+		blocks, separated, err := p.buildSeparatedBlocks(item)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		itemBlocks[i] = blocks
+		loose = loose || separated
+	}
+
+	children := make([]*html.Tag, 0)
+	for i, item := range items {
+		blocks := itemBlocks[i]
+
+		itemCtx := p.linesContext(item)
+		if !loose {
+			// tight lists don't wrap their paragraphs, subsequent paragraphs are separated by a newline
+			tight := make([]*html.Tag, 0)
+			prevIsParagraph := false
+			for _, block := range blocks {
+				if block.Name() == "p" {
+					if prevIsParagraph {
+						tight = append(tight, html.NewTextTag("\n", block.Context()))
+					}
+
+					tight = append(tight, block.Children()...)
+					prevIsParagraph = true
+				} else {
+					tight = append(tight, block)
+					prevIsParagraph = false
+				}
+			}
+
+			blocks = tight
+		}
+
+		children = append(children, html.NewTag("li", html.NewEmptyRawDict(itemCtx), blocks, itemCtx))
+	}
+
+	attr := html.NewEmptyRawDict(ctx)
+	name := "ul"
+	if first.ordered {
+		name = "ol"
+		if first.start != 1 {
+			attr.Set(html.NewValueString("start", ctx), html.NewValueInt(first.start, ctx))
+		}
+	}
+
+	return html.NewTag(name, attr, children, ctx), n, nil
+}
+
+// returns the stop condition of the html block, and ok if the line starts a html block
+func (p *MarkdownParser) htmlBlockStop(text string) (string, bool) {
+	groups := mdHTMLBlockRegexp.FindStringSubmatch(text)
+	if groups == nil {
+		return "", false
+	}
+
+	if groups[1] == "!--" {
+		return "-->", true
+	}
+
+	name := strings.ToLower(strings.TrimPrefix(groups[1], "/"))
+	switch name {
+	case "script", "pre", "style", "textarea":
+		if strings.HasPrefix(groups[1], "/") {
+			return "", true
+		}
+
+		return "</" + name + ">", true
+	default:
+		if mdHTMLBlockTags[name] {
+			return "", true
+		}
+
+		return "", false
+	}
+}
+
+// raw html is passed through unchanged
+func (p *MarkdownParser) buildHTMLBlock(lines []mdLine, stop string) (*html.Tag, int) {
+	n := 0
+	for ; n < len(lines); n++ {
+		line := lines[n]
+
+		if stop == "" {
+			if line.isBlank() {
+				break
+			}
+		} else if strings.Contains(strings.ToLower(line.text), stop) {
+			n++
+			break
+		}
+	}
+
+	content := make([]string, n)
+	for i, line := range lines[0:n] {
+		content[i] = line.text
+	}
+
+	return html.NewTextTag(strings.Join(content, "\n"), p.linesContext(lines[0:n])), n
+}
+
+func (p *MarkdownParser) buildParagraph(lines []mdLine) (*html.Tag, int, error) {
+	content := make([]string, 0)
+
+	n := 0
+	for ; n < len(lines); n++ {
+		line := lines[n]
+		if line.isBlank() {
+			break
+		}
+
+		if n > 0 {
+			if groups := mdSetextRegexp.FindStringSubmatch(line.text); groups != nil {
+				level := 1
+				if groups[1][0] == '-' {
+					level = 2
+				}
+
+				tag, err := p.newHeading(level, strings.Join(content, "\n"), p.linesContext(lines[0:n+1]))
+				return tag, n + 1, err
+			}
+
+			if p.startsBlock(line) {
+				break
+			}
+		}
+
+		content = append(content, strings.TrimLeft(line.text, " "))
+	}
+
+	ctx := p.linesContext(lines[0:n])
+
+	children, err := p.parseInline(strings.TrimRight(strings.Join(content, "\n"), " "), ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return html.NewTag("p", html.NewEmptyRawDict(ctx), children, ctx), n, nil
+}
+
+// split a table row by unescaped pipes
+func splitMarkdownTableRow(text string) []string {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "|")
+	if strings.HasSuffix(text, "|") && !strings.HasSuffix(text, "\\|") {
+		text = text[0 : len(text)-1]
+	}
+
+	cells := make([]string, 0)
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '\\' && i+1 < len(text) && text[i+1] == '|' {
+			b.WriteByte('|')
+			i++
+		} else if c == '|' {
+			cells = append(cells, strings.TrimSpace(b.String()))
+			b.Reset()
+		} else {
+			b.WriteByte(c)
+		}
+	}
+
+	return append(cells, strings.TrimSpace(b.String()))
+}
+
+func (p *MarkdownParser) isTableStart(lines []mdLine) bool {
+	if len(lines) < 2 || !strings.Contains(lines[0].text, "|") || !mdTableDelimRegexp.MatchString(lines[1].text) {
+		return false
+	}
+
+	if !strings.Contains(lines[1].text, "|") && !strings.HasPrefix(strings.TrimSpace(lines[0].text), "|") {
+		// single column tables need explicit pipes
+		return false
+	}
+
+	return len(splitMarkdownTableRow(lines[0].text)) == len(splitMarkdownTableRow(lines[1].text))
+}
+
+func (p *MarkdownParser) buildTable(lines []mdLine) (*html.Tag, int, error) {
+	header := splitMarkdownTableRow(lines[0].text)
+
+	aligns := make([]string, len(header))
+	for i, delim := range splitMarkdownTableRow(lines[1].text) {
+		switch {
+		case strings.HasPrefix(delim, ":") && strings.HasSuffix(delim, ":"):
+			aligns[i] = "center"
+		case strings.HasPrefix(delim, ":"):
+			aligns[i] = "left"
+		case strings.HasSuffix(delim, ":"):
+			aligns[i] = "right"
+		}
+	}
+
+	buildRow := func(cellName string, cells []string, ctx context.Context) (*html.Tag, error) {
+		children := make([]*html.Tag, len(header))
+		for i := range header {
+			content := ""
+			if i < len(cells) {
+				content = cells[i]
+			}
+
+			inline, err := p.parseInline(content, ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			attr := html.NewEmptyRawDict(ctx)
+			if aligns[i] != "" {
+				attr.Set(html.NewValueString("style", ctx), html.NewValueString("text-align: "+aligns[i], ctx))
+			}
+
+			children[i] = html.NewTag(cellName, attr, inline, ctx)
+		}
+
+		return html.NewTag("tr", html.NewEmptyRawDict(ctx), children, ctx), nil
+	}
+
+	headerCtx := p.lineContext(lines[0])
+	headerRow, err := buildRow("th", header, headerCtx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	thead := html.NewTag("thead", html.NewEmptyRawDict(headerCtx), []*html.Tag{headerRow}, headerCtx)
+
+	rows := make([]*html.Tag, 0)
+	n := 2
+	for ; n < len(lines); n++ {
+		line := lines[n]
+		if line.isBlank() || p.startsBlock(line) {
+			break
+		}
+
+		row, err := buildRow("td", splitMarkdownTableRow(line.text), p.lineContext(line))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		rows = append(rows, row)
+	}
+
+	ctx := p.linesContext(lines[0:n])
+
+	children := []*html.Tag{thead}
+	if len(rows) > 0 {
+		bodyCtx := p.linesContext(lines[2:n])
+		children = append(children, html.NewTag("tbody", html.NewEmptyRawDict(bodyCtx), rows, bodyCtx))
+	}
+
+	return html.NewTag("table", html.NewEmptyRawDict(ctx), children, ctx), n, nil
+}
@@ -0,0 +1,558 @@
+package parsers
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+var (
+	mdAutolinkRegexp = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.\-]{1,31}:[^ \t\n<>]*)>`)
+	mdEmailRegexp    = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_{|}~\-]+@[a-zA-Z0-9](?:[a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?)*)>`)
+	mdRawHTMLRegexp  = regexp.MustCompile(`^(?:<[A-Za-z][A-Za-z0-9\-]*(?:\s+[A-Za-z_:][A-Za-z0-9_.:\-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*/?>|</[A-Za-z][A-Za-z0-9\-]*\s*>|<!--(?:[^-]|-[^-])*-->)`)
+	mdEntityRegexp   = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+)
+
+// text, finished tag, or run of emphasis delimiters
+type mdInline struct {
+	tag      *html.Tag
+	text     string // already escaped
+	delim    byte
+	count    int
+	orig     int
+	canOpen  bool
+	canClose bool
+}
+
+func isMarkdownPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+func isMarkdownSpace(r rune) bool {
+	return unicode.IsSpace(r)
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) != -1
+}
+
+func escapeMarkdownText(s string) string {
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	return strings.ReplaceAll(s, ">", "&gt;")
+}
+
+func escapeMarkdownAttr(s string) string {
+	return strings.ReplaceAll(escapeMarkdownText(s), "\"", "&quot;")
+}
+
+func unescapeMarkdown(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+func newMarkdownAttr(ctx context.Context, keyVals ...string) *html.RawDict {
+	attr := html.NewEmptyRawDict(ctx)
+
+	for i := 0; i < len(keyVals); i += 2 {
+		attr.Set(html.NewValueString(keyVals[i], ctx), html.NewValueString(keyVals[i+1], ctx))
+	}
+
+	return attr
+}
+
+// length of the run of c starting at i
+func markdownRunLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+
+	return n
+}
+
+// returns the end of the code span starting at i, or -1 if the backticks aren't matched
+func findMarkdownCodeSpanEnd(s string, i int) (int, int) {
+	n := markdownRunLength(s, i, '`')
+
+	for j := i + n; j < len(s); {
+		if s[j] == '`' {
+			m := markdownRunLength(s, j, '`')
+			if m == n {
+				return j, n
+			}
+
+			j += m
+		} else {
+			j++
+		}
+	}
+
+	return -1, n
+}
+
+func (p *MarkdownParser) parseInline(s string, ctx context.Context) ([]*html.Tag, error) {
+	nodes := p.scanInline(s, ctx)
+
+	nodes = processMarkdownEmphasis(nodes, ctx)
+
+	return markdownInlineToTags(nodes, ctx), nil
+}
+
+func (p *MarkdownParser) scanInline(s string, ctx context.Context) []mdInline {
+	nodes := make([]mdInline, 0)
+
+	var buf strings.Builder
+
+	flush := func() {
+		if buf.Len() > 0 {
+			nodes = append(nodes, mdInline{text: escapeMarkdownText(buf.String())})
+			buf.Reset()
+		}
+	}
+
+	appendTag := func(tag *html.Tag) {
+		flush()
+		nodes = append(nodes, mdInline{tag: tag})
+	}
+
+	newBr := func() *html.Tag {
+		return html.NewTag("br", html.NewEmptyRawDict(ctx), []*html.Tag{}, ctx)
+	}
+
+	i := 0
+	for i < len(s) {
+		c := s[i]
+
+		switch c {
+		case '\\':
+			if i+1 < len(s) && isASCIIPunct(s[i+1]) {
+				buf.WriteByte(s[i+1])
+				i += 2
+			} else if i+1 < len(s) && s[i+1] == '\n' {
+				appendTag(newBr())
+				i += 2
+			} else {
+				buf.WriteByte(c)
+				i++
+			}
+		case '`':
+			end, n := findMarkdownCodeSpanEnd(s, i)
+			if end == -1 {
+				buf.WriteString(s[i : i+n])
+				i += n
+			} else {
+				code := strings.ReplaceAll(s[i+n:end], "\n", " ")
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+					code = code[1 : len(code)-1]
+				}
+
+				appendTag(html.NewTag("code", html.NewEmptyRawDict(ctx),
+					[]*html.Tag{html.NewTextTag(escapeMarkdownText(code), ctx)}, ctx))
+				i = end + n
+			}
+		case '*', '_', '~':
+			n := markdownRunLength(s, i, c)
+
+			if c == '~' && n > 2 {
+				buf.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+
+			before := ' '
+			if i > 0 {
+				before, _ = utf8.DecodeLastRuneInString(s[0:i])
+			}
+
+			after := ' '
+			if i+n < len(s) {
+				after, _ = utf8.DecodeRuneInString(s[i+n:])
+			}
+
+			leftFlanking := !isMarkdownSpace(after) &&
+				(!isMarkdownPunct(after) || isMarkdownSpace(before) || isMarkdownPunct(before))
+			rightFlanking := !isMarkdownSpace(before) &&
+				(!isMarkdownPunct(before) || isMarkdownSpace(after) || isMarkdownPunct(after))
+
+			canOpen := leftFlanking
+			canClose := rightFlanking
+			if c == '_' {
+				canOpen = leftFlanking && (!rightFlanking || isMarkdownPunct(before))
+				canClose = rightFlanking && (!leftFlanking || isMarkdownPunct(after))
+			}
+
+			flush()
+			nodes = append(nodes, mdInline{delim: c, count: n, orig: n, canOpen: canOpen, canClose: canClose})
+			i += n
+		case '!':
+			if i+1 < len(s) && s[i+1] == '[' {
+				if tag, end, ok := p.scanLink(s, i+1, true, ctx); ok {
+					appendTag(tag)
+					i = end
+					continue
+				}
+			}
+
+			buf.WriteByte(c)
+			i++
+		case '[':
+			if tag, end, ok := p.scanLink(s, i, false, ctx); ok {
+				appendTag(tag)
+				i = end
+			} else {
+				buf.WriteByte(c)
+				i++
+			}
+		case '<':
+			rest := s[i:]
+			if groups := mdAutolinkRegexp.FindStringSubmatch(rest); groups != nil {
+				appendTag(html.NewTag("a", newMarkdownAttr(ctx, "href", escapeMarkdownAttr(groups[1])),
+					[]*html.Tag{html.NewTextTag(escapeMarkdownText(groups[1]), ctx)}, ctx))
+				i += len(groups[0])
+			} else if groups := mdEmailRegexp.FindStringSubmatch(rest); groups != nil {
+				appendTag(html.NewTag("a", newMarkdownAttr(ctx, "href", "mailto:"+escapeMarkdownAttr(groups[1])),
+					[]*html.Tag{html.NewTextTag(escapeMarkdownText(groups[1]), ctx)}, ctx))
+				i += len(groups[0])
+			} else if raw := mdRawHTMLRegexp.FindString(rest); raw != "" {
+				// inline html is passed through unchanged
+				flush()
+				nodes = append(nodes, mdInline{text: raw})
+				i += len(raw)
+			} else {
+				buf.WriteByte(c)
+				i++
+			}
+		case '&':
+			if entity := mdEntityRegexp.FindString(s[i:]); entity != "" {
+				flush()
+				nodes = append(nodes, mdInline{text: entity})
+				i += len(entity)
+			} else {
+				buf.WriteByte(c)
+				i++
+			}
+		case '\n':
+			// two or more trailing spaces make a hard break, otherwise it is a soft break
+			str := buf.String()
+			trimmed := strings.TrimRight(str, " ")
+			buf.Reset()
+			buf.WriteString(trimmed)
+
+			if len(str)-len(trimmed) >= 2 {
+				appendTag(newBr())
+			} else {
+				buf.WriteByte(' ')
+			}
+
+			i++
+			for i < len(s) && s[i] == ' ' {
+				i++
+			}
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+
+	flush()
+
+	return nodes
+}
+
+// find the closing bracket of the link text starting at i, skipping code spans and escapes
+func findMarkdownLinkTextEnd(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '`':
+			end, n := findMarkdownCodeSpanEnd(s, j)
+			if end != -1 {
+				j = end + n - 1
+			} else {
+				j += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+
+	return -1
+}
+
+// inline destination and optional title, i is the position right after the opening parenthesis
+func parseMarkdownLinkDestination(s string, i int) (string, string, int, bool) {
+	skipSpace := func() {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\n' || s[i] == '\t') {
+			i++
+		}
+	}
+
+	skipSpace()
+
+	dest := ""
+	if i < len(s) && s[i] == '<' {
+		end := strings.IndexAny(s[i+1:], ">\n")
+		if end == -1 || s[i+1+end] != '>' {
+			return "", "", 0, false
+		}
+
+		dest = s[i+1 : i+1+end]
+		i += end + 2
+	} else {
+		start := i
+		depth := 0
+		for i < len(s) && s[i] > ' ' {
+			if s[i] == '\\' && i+1 < len(s) {
+				i += 2
+				continue
+			} else if s[i] == '(' {
+				depth++
+			} else if s[i] == ')' {
+				if depth == 0 {
+					break
+				}
+
+				depth--
+			}
+
+			i++
+		}
+
+		dest = s[start:i]
+	}
+
+	hasSpace := i < len(s) && (s[i] == ' ' || s[i] == '\n' || s[i] == '\t')
+	skipSpace()
+
+	title := ""
+	if hasSpace && i < len(s) && (s[i] == '"' || s[i] == '\'' || s[i] == '(') {
+		stop := s[i]
+		if stop == '(' {
+			stop = ')'
+		}
+
+		end := -1
+		for j := i + 1; j < len(s); j++ {
+			if s[j] == '\\' {
+				j++
+			} else if s[j] == stop {
+				end = j
+				break
+			}
+		}
+
+		if end == -1 {
+			return "", "", 0, false
+		}
+
+		title = s[i+1 : end]
+		i = end + 1
+		skipSpace()
+	}
+
+	if i >= len(s) || s[i] != ')' {
+		return "", "", 0, false
+	}
+
+	return unescapeMarkdown(dest), unescapeMarkdown(title), i + 1, true
+}
+
+// i is the position of the opening bracket
+func (p *MarkdownParser) scanLink(s string, i int, image bool, ctx context.Context) (*html.Tag, int, bool) {
+	j := findMarkdownLinkTextEnd(s, i)
+	if j == -1 {
+		return nil, 0, false
+	}
+
+	label := s[i+1 : j]
+
+	url := ""
+	title := ""
+	end := j + 1
+
+	found := false
+	if end < len(s) && s[end] == '(' {
+		url, title, end, found = parseMarkdownLinkDestination(s, end+1)
+	}
+
+	if !found {
+		refLabel := label
+		end = j + 1
+		if end < len(s) && s[end] == '[' {
+			if k := strings.IndexByte(s[end:], ']'); k != -1 {
+				if k > 1 {
+					refLabel = s[end+1 : end+k]
+				}
+
+				end += k + 1
+			}
+		}
+
+		ref, ok := p.refs[normalizeMarkdownLabel(refLabel)]
+		if !ok {
+			return nil, 0, false
+		}
+
+		url = ref.url
+		title = ref.title
+	}
+
+	if image {
+		attr := newMarkdownAttr(ctx, "src", escapeMarkdownAttr(url), "alt", escapeMarkdownAttr(markdownPlainText(label)))
+		if title != "" {
+			attr.Set(html.NewValueString("title", ctx), html.NewValueString(escapeMarkdownAttr(title), ctx))
+		}
+
+		return html.NewTag("img", attr, []*html.Tag{}, ctx), end, true
+	}
+
+	children, err := p.parseInline(label, ctx)
+	if err != nil {
+		return nil, 0, false
+	}
+
+	attr := newMarkdownAttr(ctx, "href", escapeMarkdownAttr(url))
+	if title != "" {
+		attr.Set(html.NewValueString("title", ctx), html.NewValueString(escapeMarkdownAttr(title), ctx))
+	}
+
+	return html.NewTag("a", attr, children, ctx), end, true
+}
+
+// used for image alt attributes
+func markdownPlainText(s string) string {
+	s = unescapeMarkdown(s)
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '*', '_', '`', '[', ']':
+			return -1
+		case '\n':
+			return ' '
+		default:
+			return r
+		}
+	}, s)
+}
+
+// CommonMark "process emphasis" procedure, without the openers_bottom optimization
+func processMarkdownEmphasis(nodes []mdInline, ctx context.Context) []mdInline {
+	for c := 0; c < len(nodes); c++ {
+		closer := nodes[c]
+		if closer.count == 0 || !closer.canClose {
+			continue
+		}
+
+		o := -1
+		for k := c - 1; k >= 0; k-- {
+			opener := nodes[k]
+			if opener.delim != closer.delim || opener.count == 0 || !opener.canOpen {
+				continue
+			}
+
+			if closer.delim == '~' {
+				if opener.count != closer.count {
+					continue
+				}
+			} else if (opener.canClose || closer.canOpen) && (opener.orig+closer.orig)%3 == 0 &&
+				!(opener.orig%3 == 0 && closer.orig%3 == 0) {
+				continue
+			}
+
+			o = k
+			break
+		}
+
+		if o == -1 {
+			continue
+		}
+
+		use := 1
+		name := "em"
+		if closer.delim == '~' {
+			use = closer.count
+			name = "del"
+		} else if closer.count >= 2 && nodes[o].count >= 2 {
+			use = 2
+			name = "strong"
+		}
+
+		tag := html.NewTag(name, html.NewEmptyRawDict(ctx), markdownInlineToTags(nodes[o+1:c], ctx), ctx)
+
+		opener := nodes[o]
+		opener.count -= use
+		closer.count -= use
+
+		newNodes := make([]mdInline, 0, len(nodes))
+		newNodes = append(newNodes, nodes[0:o]...)
+		if opener.count > 0 {
+			newNodes = append(newNodes, opener)
+		}
+
+		newNodes = append(newNodes, mdInline{tag: tag})
+
+		next := len(newNodes)
+		if closer.count > 0 {
+			newNodes = append(newNodes, closer)
+		}
+
+		newNodes = append(newNodes, nodes[c+1:]...)
+
+		nodes = newNodes
+
+		// revisit the remaining closer, if any
+		c = next - 1
+	}
+
+	return nodes
+}
+
+// unmatched delimiters become text, consecutive text is merged
+func markdownInlineToTags(nodes []mdInline, ctx context.Context) []*html.Tag {
+	result := make([]*html.Tag, 0)
+
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			result = append(result, html.NewTextTag(b.String(), ctx))
+			b.Reset()
+		}
+	}
+
+	for _, node := range nodes {
+		switch {
+		case node.tag != nil:
+			flush()
+			result = append(result, node.tag)
+		case node.delim != 0:
+			b.WriteString(strings.Repeat(string(node.delim), node.count))
+		default:
+			b.WriteString(node.text)
+		}
+	}
+
+	flush()
+
+	return result
+}
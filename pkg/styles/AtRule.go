@@ -19,8 +19,12 @@ func (r *AtRule) Context() context.Context {
   return r.sel.Context()
 }
 
+// the parent of the outermost at selector, so deeper chains (eg. from @include) are kept
+// at selectors are shared by the rules of a block, so the parent might already be set through another rule
 func (r *AtRule) SetParent(parent *AtSelector) {
-  r.sel.SetParent(parent)
+  if root := rootAtSelector(r.sel); root != parent {
+    root.SetParent(parent)
+  }
 }
 
 // not called the first time. The subsequent times nothing should change
@@ -10,10 +10,11 @@ import (
 type AtSelector struct {
   parent *AtSelector // nil if top level
   key *tokens.String
+  imported bool // from a plain css file, see isImportedAtRule
 }
 
 func NewAtSelector(key *tokens.String) *AtSelector {
-  return &AtSelector{nil, key}
+  return &AtSelector{nil, key, false}
 }
 
 func (s *AtSelector) Context() context.Context {
@@ -24,6 +25,14 @@ func (s *AtSelector) SetParent(parent *AtSelector) {
   s.parent = parent
 }
 
+func rootAtSelector(sel *AtSelector) *AtSelector {
+  for sel.parent != nil {
+    sel = sel.parent
+  }
+
+  return sel
+}
+
 func collectAtSelectors(sel *AtSelector) []*AtSelector {
   if sel.parent != nil {
     return append(collectAtSelectors(sel.parent), sel)
@@ -42,8 +42,15 @@ func (f *AttrFilterData) getValue(tag tree.Tag) (string, bool) {
     }
   default:
     attr := tag.Attributes()
+    if attr == nil {
+      return "", false
+    }
+
     v_, ok := attr.Get(f.name)
-    if ok && tokens.IsPrimitive(v_) {
+    if ok && tokens.IsTrueBool(v_) {
+      // boolean attribute, eg. disabled
+      return "", true
+    } else if ok && tokens.IsPrimitive(v_) {
       v, err := tokens.AssertPrimitive(v_)
       if err != nil {
         panic(err)
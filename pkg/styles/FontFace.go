@@ -0,0 +1,150 @@
+package styles
+
+import (
+  "path/filepath"
+  "regexp"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/directives"
+	"github.com/wtsuite/wtsuite/pkg/functions"
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// registers a local font file referenced by the src of a @font-face rule, and returns its url relative to the site root
+// nil to keep the urls as authored (eg. wt-style)
+var FONT_URL func(path string) (string, error) = nil
+
+var (
+  FONT_SRC_URL_REGEXP = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)\s]*))\s*\)`)
+  URL_SCHEME_REGEXP   = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.\-]*:`)
+)
+
+// key is "@font-face", or "@font-face <label>" to distinguish several faces in the same dict (the label isn't written)
+type FontFaceRule struct {
+  attr  *tokens.StringDict
+  fonts map[string]string // authored src url -> url relative to the site root
+  ctx   context.Context
+}
+
+func NewFontFaceRule(sel Selector, key *tokens.String, attr *tokens.StringDict) ([]Rule, error) {
+  ctx := key.Context()
+  if sel != nil {
+    return nil, ctx.NewError("Error: @font-face must be top-level")
+  }
+
+  if err := attr.AssertOnlyValidKeys([]string{"font-family", "src", "font-display", "font-stretch", "font-style",
+    "font-weight", "font-feature-settings", "font-variation-settings", "unicode-range", "ascent-override",
+    "descent-override", "line-gap-override", "size-adjust"}); err != nil {
+    return nil, err
+  }
+
+  for _, required := range []string{"font-family", "src"} {
+    if _, ok := attr.Get(required); !ok {
+      return nil, ctx.NewError("Error: @font-face " + required + " not set")
+    }
+  }
+
+  fonts := make(map[string]string)
+
+  if FONT_URL != nil {
+    src_, _ := attr.Get("src")
+    src, err := declValue(src_)
+    if err != nil {
+      return nil, err
+    }
+
+    for _, m := range FONT_SRC_URL_REGEXP.FindAllStringSubmatch(src, -1) {
+      url := m[1] + m[2] + m[3]
+      if url == "" || URL_SCHEME_REGEXP.MatchString(url) {
+        continue
+      }
+
+      path, err := functions.AbsPath(tokens.NewValueString(url, src_.Context()), src_.Context())
+      if err != nil {
+        return nil, err
+      }
+
+      fonts[url], err = FONT_URL(path.Value())
+      if err != nil {
+        errCtx := src_.Context()
+        return nil, errCtx.NewError(err.Error())
+      }
+    }
+  }
+
+  return []Rule{&FontFaceRule{attr, fonts, ctx}}, nil
+}
+
+func (r *FontFaceRule) Context() context.Context {
+  return r.ctx
+}
+
+func (r *FontFaceRule) ExpandNested() ([]Rule, error) {
+  return []Rule{r}, nil
+}
+
+// relative to the active page (eg. inline styles and critical css), or to the site root where the sheets are written
+func relativeFontURL(url string) string {
+  active, err := directives.GetActiveURL(context.NewDummyContext())
+  if err != nil {
+    return url
+  }
+
+  if !directives.RELATIVE {
+    return "/" + url
+  }
+
+  rel, err := filepath.Rel(filepath.Dir(active.Value()), "/" + url)
+  if err != nil {
+    panic(err)
+  }
+
+  return rel
+}
+
+func (r *FontFaceRule) Write(indent string, nl string, tab string) (string, error) {
+  attr := r.attr
+  if len(r.fonts) > 0 {
+    var err error
+    attr, err = r.attr.CopyStringDict(r.attr.Context())
+    if err != nil {
+      return "", err
+    }
+
+    src_, _ := attr.Get("src")
+    src, err := declValue(src_)
+    if err != nil {
+      return "", err
+    }
+
+    src = FONT_SRC_URL_REGEXP.ReplaceAllStringFunc(src, func(s string) string {
+      m := FONT_SRC_URL_REGEXP.FindStringSubmatch(s)
+      if url, ok := r.fonts[m[1] + m[2] + m[3]]; ok {
+        return "url(" + relativeFontURL(url) + ")"
+      }
+
+      return s
+    })
+
+    attr.Set("src", tokens.NewValueString(src, src_.Context()))
+  }
+
+  var b strings.Builder
+
+  b.WriteString(indent)
+  b.WriteString("@font-face{")
+  b.WriteString(nl)
+
+  inner, err := writeDeclarations(attr, indent + tab, nl)
+  if err != nil {
+    return "", err
+  }
+
+  b.WriteString(inner)
+  b.WriteString(indent)
+  b.WriteString("}")
+  b.WriteString(nl)
+
+  return b.String(), nil
+}
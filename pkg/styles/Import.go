@@ -0,0 +1,129 @@
+package styles
+
+import (
+  "regexp"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+var LAYER_NAME_REGEXP = regexp.MustCompile(`^-?[_a-zA-Z][_a-zA-Z0-9\-]*(?:\.-?[_a-zA-Z][_a-zA-Z0-9\-]*)*$`)
+
+// eg. "@import theme.css layer(base) screen": {}
+// imports, and layer statements, are written at the start of the sheet
+type ImportRule struct {
+  args string // url followed by the optional layer, supports and media conditions
+  ctx  context.Context
+}
+
+// eg. "@layer reset, base, components": {}
+type LayerStatementRule struct {
+  names []string
+  ctx   context.Context
+}
+
+func atRuleArgs(key *tokens.String) string {
+  value := strings.TrimSpace(strings.TrimLeft(key.Value(), "@"))
+
+  if i := strings.IndexAny(value, " \t\n"); i != -1 {
+    return strings.TrimSpace(value[i:])
+  }
+
+  return ""
+}
+
+func NewImportRule(sel Selector, key *tokens.String, attr *tokens.StringDict) ([]Rule, error) {
+  ctx := key.Context()
+  if sel != nil {
+    return nil, ctx.NewError("Error: @import must be top-level")
+  }
+
+  if attr.Len() != 0 {
+    errCtx := attr.Context()
+    return nil, errCtx.NewError("Error: expected empty dict")
+  }
+
+  args := atRuleArgs(key)
+  if args == "" {
+    return nil, ctx.NewError("Error: expected @import <url> [layer(<name>)] [supports(<condition>)] [<media>]")
+  }
+
+  // bare urls are wrapped
+  if !strings.HasPrefix(args, "url(") && !strings.HasPrefix(args, "\"") && !strings.HasPrefix(args, "'") {
+    fields := strings.Fields(args)
+    args = "url(" + fields[0] + ")" + strings.TrimPrefix(args, fields[0])
+  }
+
+  return []Rule{&ImportRule{args, ctx}}, nil
+}
+
+func (r *ImportRule) Context() context.Context {
+  return r.ctx
+}
+
+func (r *ImportRule) ExpandNested() ([]Rule, error) {
+  return []Rule{r}, nil
+}
+
+func (r *ImportRule) Write(indent string, nl string, tab string) (string, error) {
+  return indent + "@import " + r.args + ";" + nl, nil
+}
+
+// statement without rules, or a named or anonymous layer block
+func NewLayerRule(sel Selector, key *tokens.String, attr *tokens.StringDict) ([]Rule, error) {
+  ctx := key.Context()
+
+  names := make([]string, 0)
+  if args := atRuleArgs(key); args != "" {
+    for _, name := range strings.Split(args, ",") {
+      name = strings.TrimSpace(name)
+      if !LAYER_NAME_REGEXP.MatchString(name) {
+        return nil, ctx.NewError("Error: invalid layer name \"" + name + "\"")
+      }
+
+      names = append(names, name)
+    }
+  }
+
+  if attr.Len() == 0 {
+    if sel != nil {
+      return nil, ctx.NewError("Error: layer statement must be top-level")
+    }
+
+    if len(names) == 0 {
+      return nil, ctx.NewError("Error: expected @layer <name>[, <name> ...]")
+    }
+
+    return []Rule{&LayerStatementRule{names, ctx}}, nil
+  }
+
+  if len(names) > 1 {
+    return nil, ctx.NewError("Error: a layer block can only have one name")
+  }
+
+  return expandGenericAtRule(sel, key, attr, false)
+}
+
+func (r *LayerStatementRule) Context() context.Context {
+  return r.ctx
+}
+
+func (r *LayerStatementRule) ExpandNested() ([]Rule, error) {
+  return []Rule{r}, nil
+}
+
+func (r *LayerStatementRule) Write(indent string, nl string, tab string) (string, error) {
+  return indent + "@layer " + strings.Join(r.names, ",") + ";" + nl, nil
+}
+
+func isPreambleRule(r_ Rule) bool {
+  switch r := r_.(type) {
+  case *ImportRule, *LayerStatementRule:
+    return true
+  case *RawAtRule:
+    return r.statement
+  default:
+    return false
+  }
+}
@@ -68,7 +68,7 @@ func (kf *Keyframe) write(indent string, nl string, tab string) (string, error)
   b.WriteString(kf.pos)
   b.WriteString("{")
   b.WriteString(nl)
-  inner, err := kf.attr.ToString(indent + tab, nl)
+  inner, err := writeDeclarations(kf.attr, indent + tab, nl)
   if err != nil {
     return "", err
   }
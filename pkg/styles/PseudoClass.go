@@ -1,26 +1,36 @@
 package styles
 
 import (
+  "regexp"
+  "strconv"
   "strings"
 
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
 	"github.com/wtsuite/wtsuite/pkg/tokens/raw"
+	"github.com/wtsuite/wtsuite/pkg/tree"
 )
 
 type PseudoClass interface {
   Write() string
+  IsStatic() bool // false if the match depends on user interaction or browser state (eg. :hover)
+  Match(n *selNode) bool
+  Selectors() []*SelectorData // selectors in the arguments, eg. :is(.a, .b)
 }
 
 type GenericPseudoClass struct {
   name string
   parensContent string // uses simple context print, so far from ideal
+  static bool
+  match func(n *selNode) bool // nil for dynamic or unknown pseudo classes, which never match
+  sels []*SelectorData
 }
 
 func NewGenericPseudoClass(name string) *GenericPseudoClass {
-  return &GenericPseudoClass{name, ""}
+  return &GenericPseudoClass{name, "", false, nil, []*SelectorData{}}
 }
 
 func NewGenericPseudoClassWithArgs(name string, args string) *GenericPseudoClass {
-  return &GenericPseudoClass{name, args}
+  return &GenericPseudoClass{name, args, false, nil, []*SelectorData{}}
 }
 
 func (p *GenericPseudoClass) Write() string {
@@ -36,6 +46,410 @@ func (p *GenericPseudoClass) Write() string {
   return b.String()
 }
 
+func (p *GenericPseudoClass) IsStatic() bool {
+  return p.static
+}
+
+func (p *GenericPseudoClass) Match(n *selNode) bool {
+  return p.match != nil && p.match(n)
+}
+
+func (p *GenericPseudoClass) Selectors() []*SelectorData {
+  return p.sels
+}
+
+func hasAttr(n *selNode, name string) bool {
+  _, ok := selAttr(n.tag, name)
+  return ok
+}
+
+func isOneOf(n *selNode, names ...string) bool {
+  for _, name := range names {
+    if n.tag.Name() == name {
+      return true
+    }
+  }
+
+  return false
+}
+
+func isDisableable(n *selNode) bool {
+  return isOneOf(n, "button", "input", "select", "textarea", "optgroup", "option", "fieldset")
+}
+
+func isReadWrite(n *selNode) bool {
+  if v, ok := selAttr(n.tag, "contenteditable"); ok && v != "false" {
+    return true
+  }
+
+  return isOneOf(n, "input", "textarea") && !hasAttr(n, "readonly") && !hasAttr(n, "disabled")
+}
+
+func countSiblings(n *selNode, fromEnd bool, filter func(sib *selNode) bool) int {
+  count := 0
+  for i := range n.siblings {
+    sib := n.sibling(i)
+    if filter(sib) {
+      count += 1
+    }
+
+    if i == n.index {
+      if !fromEnd {
+        return count
+      }
+
+      count = 1
+    }
+  }
+
+  return count
+}
+
+func sameType(n *selNode) func(sib *selNode) bool {
+  return func(sib *selNode) bool {
+    return sib.tag.Name() == n.tag.Name()
+  }
+}
+
+func anySibling(sib *selNode) bool {
+  return true
+}
+
+// argumentless pseudo classes that can be determined from the tree
+var _staticPseudoClasses = map[string]func(n *selNode) bool{
+  "root": func(n *selNode) bool {
+    return n.tag.Name() == "html"
+  },
+  "scope": func(n *selNode) bool {
+    return n.parent == nil
+  },
+  "empty": func(n *selNode) bool {
+    for _, child := range n.tag.Children() {
+      if text, ok := child.(*tree.Text); ok {
+        if text.Value() != "" {
+          return false
+        }
+      } else if child.Name() != "" {
+        return false
+      }
+    }
+
+    return true
+  },
+  "first-child": func(n *selNode) bool {
+    return countSiblings(n, false, anySibling) == 1
+  },
+  "last-child": func(n *selNode) bool {
+    return countSiblings(n, true, anySibling) == 1
+  },
+  "only-child": func(n *selNode) bool {
+    return len(n.siblings) == 1
+  },
+  "first-of-type": func(n *selNode) bool {
+    return countSiblings(n, false, sameType(n)) == 1
+  },
+  "last-of-type": func(n *selNode) bool {
+    return countSiblings(n, true, sameType(n)) == 1
+  },
+  "only-of-type": func(n *selNode) bool {
+    return countSiblings(n, false, sameType(n)) == 1 && countSiblings(n, true, sameType(n)) == 1
+  },
+  "link": func(n *selNode) bool {
+    return isOneOf(n, "a", "area") && hasAttr(n, "href")
+  },
+  "any-link": func(n *selNode) bool {
+    return isOneOf(n, "a", "area") && hasAttr(n, "href")
+  },
+  "checked": func(n *selNode) bool {
+    if n.tag.Name() == "input" {
+      typ, _ := selAttr(n.tag, "type")
+      return (typ == "checkbox" || typ == "radio") && hasAttr(n, "checked")
+    }
+
+    return n.tag.Name() == "option" && hasAttr(n, "selected")
+  },
+  "disabled": func(n *selNode) bool {
+    return isDisableable(n) && hasAttr(n, "disabled")
+  },
+  "enabled": func(n *selNode) bool {
+    return isDisableable(n) && !hasAttr(n, "disabled")
+  },
+  "required": func(n *selNode) bool {
+    return isOneOf(n, "input", "select", "textarea") && hasAttr(n, "required")
+  },
+  "optional": func(n *selNode) bool {
+    return isOneOf(n, "input", "select", "textarea") && !hasAttr(n, "required")
+  },
+  "read-write": isReadWrite,
+  "read-only": func(n *selNode) bool {
+    return !isReadWrite(n)
+  },
+  "placeholder-shown": func(n *selNode) bool {
+    value, _ := selAttr(n.tag, "value")
+    return isOneOf(n, "input", "textarea") && hasAttr(n, "placeholder") && value == ""
+  },
+  "defined": func(n *selNode) bool {
+    return true
+  },
+}
+
+// An+B, with odd and even
+var _nthRegexp = regexp.MustCompile(`^([+-]?[0-9]*)n([+-][0-9]+)?$`)
+
+func parseNth(s string) (int, int, bool) {
+  s = strings.ToLower(strings.Join(strings.Fields(s), ""))
+
+  switch s {
+  case "odd":
+    return 2, 1, true
+  case "even":
+    return 2, 0, true
+  }
+
+  if b, err := strconv.Atoi(s); err == nil {
+    return 0, b, true
+  }
+
+  m := _nthRegexp.FindStringSubmatch(s)
+  if m == nil {
+    return 0, 0, false
+  }
+
+  a := 1
+  switch m[1] {
+  case "", "+":
+  case "-":
+    a = -1
+  default:
+    var err error
+    a, err = strconv.Atoi(m[1])
+    if err != nil {
+      return 0, 0, false
+    }
+  }
+
+  b := 0
+  if m[2] != "" {
+    var err error
+    b, err = strconv.Atoi(m[2])
+    if err != nil {
+      return 0, 0, false
+    }
+  }
+
+  return a, b, true
+}
+
+// is there an n >= 0 so that a*n + b == i (i starts at 1)
+func nthMatches(a int, b int, i int) bool {
+  if a == 0 {
+    return i == b
+  }
+
+  return (i - b) % a == 0 && (i - b) / a >= 0
+}
+
+func parseSubSelectors(parens *raw.Group, content string) ([]*SelectorData, error) {
+  sels_, err := ParseSelectorList(tokens.NewValueString(content, parens.Context()))
+  if err != nil {
+    return nil, err
+  }
+
+  sels := make([]*SelectorData, len(sels_))
+  for i, sel_ := range sels_ {
+    sels[i] = sel_.(*SelectorData)
+  }
+
+  return sels, nil
+}
+
+func allStatic(sels []*SelectorData) bool {
+  for _, sel := range sels {
+    for _, l := range sel.links() {
+      for _, p := range l.sel.pseudoClasses {
+        if !p.IsStatic() {
+          return false
+        }
+      }
+    }
+  }
+
+  return true
+}
+
+func matchAny(sels []*SelectorData, n *selNode) bool {
+  for _, sel := range sels {
+    if sel.matchNode(n) {
+      return true
+    }
+  }
+
+  return false
+}
+
+// relative selectors, eg. :has(> img, + p)
+// the parsed selectors are also returned
+func parseRelativeSelectors(parens *raw.Group, content string) ([][]selLink, []*SelectorData, bool, error) {
+  tss, err := parseList(tokens.NewValueString(content, parens.Context()))
+  if err != nil {
+    return nil, nil, false, err
+  }
+
+  res := make([][]selLink, 0)
+  sels := make([]*SelectorData, 0)
+  static := true
+  for _, ts := range tss {
+    if len(ts) == 0 {
+      errCtx := parens.Context()
+      return nil, nil, false, errCtx.NewError("Error: bad relative selector")
+    }
+
+    var comb byte = ' '
+    for _, c := range []string{">", "+", "~"} {
+      if raw.IsSymbol(ts[0], c) {
+        comb = c[0]
+        ts = ts[1:]
+        break
+      }
+    }
+
+    if len(ts) == 0 {
+      errCtx := parens.Context()
+      return nil, nil, false, errCtx.NewError("Error: bad relative selector")
+    }
+
+    sel, err := ParseSelector(ts)
+    if err != nil {
+      return nil, nil, false, err
+    }
+
+    static = static && allStatic([]*SelectorData{sel})
+    sels = append(sels, sel)
+
+    links := sel.links()
+    links[0].comb = comb
+    res = append(res, append([]selLink{selLink{nil, 0}}, links...))
+  }
+
+  return res, sels, static, nil
+}
+
+func matchRelative(rels [][]selLink, n *selNode) bool {
+  for _, links := range rels {
+    // candidates for the rightmost compound
+    candidates := n.descendants()
+    if comb := links[1].comb; comb == '+' || comb == '~' {
+      candidates = []*selNode{}
+      for i := n.index + 1; i < len(n.siblings); i++ {
+        sib := n.sibling(i)
+        candidates = append(candidates, sib)
+        candidates = append(candidates, sib.descendants()...)
+      }
+    }
+
+    for _, c := range candidates {
+      if matchLinks(links, len(links)-1, c, n.tag) {
+        return true
+      }
+    }
+  }
+
+  return false
+}
+
+func parsePseudoClassArgs(name string, parens *raw.Group, content string) (PseudoClass, error) {
+  p := NewGenericPseudoClassWithArgs(name, content)
+  errCtx := parens.Context()
+
+  switch name {
+  case "not", "is", "where", "matches":
+    sels, err := parseSubSelectors(parens, content)
+    if err != nil {
+      return nil, err
+    }
+
+    p.static = allStatic(sels)
+    p.sels = sels
+    if name == "not" {
+      p.match = func(n *selNode) bool {
+        return !matchAny(sels, n)
+      }
+    } else {
+      p.match = func(n *selNode) bool {
+        return matchAny(sels, n)
+      }
+    }
+  case "has":
+    rels, sels, static, err := parseRelativeSelectors(parens, content)
+    if err != nil {
+      return nil, err
+    }
+
+    p.static = static
+    p.sels = sels
+    p.match = func(n *selNode) bool {
+      return matchRelative(rels, n)
+    }
+  case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+    anb := content
+    var of []*SelectorData = nil
+    if i := strings.Index(content, " of "); i != -1 {
+      if !strings.HasSuffix(name, "child") {
+        return nil, errCtx.NewError("Error: 'of' only allowed in :nth-child() and :nth-last-child()")
+      }
+
+      var err error
+      of, err = parseSubSelectors(parens, content[i+4:])
+      if err != nil {
+        return nil, err
+      }
+
+      anb = content[0:i]
+      p.sels = of
+    }
+
+    a, b, ok := parseNth(anb)
+    if !ok {
+      return nil, errCtx.NewError("Error: bad An+B expression")
+    }
+
+    fromEnd := strings.Contains(name, "last")
+
+    p.static = of == nil || allStatic(of)
+    p.match = func(n *selNode) bool {
+      filter := anySibling
+      if strings.HasSuffix(name, "of-type") {
+        filter = sameType(n)
+      } else if of != nil {
+        if !matchAny(of, n) {
+          return false
+        }
+
+        filter = func(sib *selNode) bool {
+          return matchAny(of, sib)
+        }
+      }
+
+      return nthMatches(a, b, countSiblings(n, fromEnd, filter))
+    }
+  case "lang":
+    lang := strings.ToLower(strings.Trim(strings.TrimSpace(content), "\"'"))
+
+    p.static = true
+    p.match = func(n *selNode) bool {
+      for ; n != nil; n = n.parent {
+        if v, ok := selAttr(n.tag, "lang"); ok {
+          v = strings.ToLower(v)
+          return v == lang || strings.HasPrefix(v, lang + "-")
+        }
+      }
+
+      return false
+    }
+  }
+
+  return p, nil
+}
 
 func ParsePseudoClass(ts []raw.Token) (PseudoClass, error) {
   if len(ts) == 0 {
@@ -54,18 +468,21 @@ func ParsePseudoClass(ts []raw.Token) (PseudoClass, error) {
       return nil, err
     }
 
-    switch nameToken.Value() {
-    default:
-      pCtx := parens.Context()
-      parensContent := pCtx.Content()
-      parensContent = parensContent[1:len(parensContent)-1]
+    pCtx := parens.Context()
+    parensContent := pCtx.Content()
+    parensContent = parensContent[1:len(parensContent)-1]
 
-      return NewGenericPseudoClassWithArgs(nameToken.Value(), parensContent), nil
-    }
+    return parsePseudoClassArgs(nameToken.Value(), parens, parensContent)
   } else if len(ts) > 2 {
     errCtx := ts[2].Context()
     return nil, errCtx.NewError("Error: unexpected token")
   } else {
-    return NewGenericPseudoClass(nameToken.Value()), nil
+    p := NewGenericPseudoClass(nameToken.Value())
+    if fn, ok := _staticPseudoClasses[nameToken.Value()]; ok {
+      p.static = true
+      p.match = fn
+    }
+
+    return p, nil
   }
 }
@@ -132,7 +132,7 @@ func (r *RuleData) writeStart(indent string, nl string) string {
 }
 
 func (r *RuleData) writeAttributes(indent string, nl string) (string, error) {
-	return r.attr.ToString(indent, nl)
+	return writeDeclarations(r.attr, indent, nl)
 }
 
 func (r *RuleData) writeStop(indent string, nl string) string {
@@ -20,9 +20,9 @@ type Selector interface {
 }
 
 type SelectorData struct {
-  elementName string // *, div, body, html (can't be empty)
-  class string // can't  be combined with id, *.class is simplified as .class
-  id string // can't be combined with class, *#id is simplified as .id
+  elementName string // *, div, body, html (empty for an implicit *, eg. :root)
+  classes []string // *.class is simplified as .class
+  id string // *#id is simplified as #id
   filters []AttrFilter // also part of search queries
 
   pseudoClasses []PseudoClass
@@ -42,7 +42,7 @@ func (s *SelectorData) Context() context.Context {
 func (s *SelectorData) Copy() *SelectorData {
   return &SelectorData{
     s.elementName,
-    s.class,
+    s.classes,
     s.id,
     s.filters,
     s.pseudoClasses,
@@ -199,73 +199,77 @@ func assertNonClassOrIDWord(t raw.Token) (*raw.Word, error) {
   return w, nil
 }
 
-func parseNameToken(t raw.Token) (string, string, string, error) {
+// compound of element name, id and classes (eg. a#top.nav.active)
+func parseNameToken(t raw.Token) (string, []string, string, error) {
   elementName := ""
-  class := ""
+  classes := []string{}
   id := ""
 
   ctx := t.Context()
 
   if raw.IsSymbol(t, "*") {
-    elementName = "*"
-  } else {
-    w_, err := raw.AssertWord(t)
-    if err != nil {
-      return "", "", "", err
-    }
-    
-    w := w_.Value()
+    return "*", classes, id, nil
+  }
 
-    if strings.HasPrefix(w, ".") {
-      if strings.Contains(w, "#") {
-        return "", "", "", ctx.NewError("Error: can't mix id and class selector")
-      }
+  w_, err := raw.AssertWord(t)
+  if err != nil {
+    return "", nil, "", err
+  }
 
-      class = w[1:]
-    } else if strings.Contains(w, ".") {
-      parts := strings.Split(w, ".")
-      if len(parts) != 2 {
-        return "", "", "", ctx.NewError("Error: bad class selector")
-      }
+  w := w_.Value()
 
-      elementName = parts[0]
-      class = parts[1]
+  // split before every . and #
+  parts := []string{}
+  start := 0
+  for i, c := range w {
+    if (c == '.' || c == '#') && i > start {
+      parts = append(parts, w[start:i])
+      start = i
+    }
+  }
+  parts = append(parts, w[start:])
 
-      if strings.Contains(elementName, "#") {
-        return "", "", "", ctx.NewError("Error: can't mix id and class selector")
-      } else if strings.Contains(class, "#") {
-        return "", "", "", ctx.NewError("Error: can't mix id and class selector")
-      }
-    } else if strings.HasPrefix(w, "#") {
-      if strings.Contains(w, ".") {
-        return "", "", "", ctx.NewError("Error: can't mix id and class selector")
+  for i, part := range parts {
+    switch {
+    case strings.HasPrefix(part, "."):
+      if len(part) == 1 {
+        return "", nil, "", ctx.NewError("Error: bad class selector")
       }
 
-      id = w[1:]
-    } else if strings.Contains(w, "#") {
-      parts := strings.Split(w, "#") 
-      if len(parts) != 2 {
-        return "", "", "", ctx.NewError("Error: bad class selector")
+      classes = append(classes, part[1:])
+    case strings.HasPrefix(part, "#"):
+      if len(part) == 1 {
+        return "", nil, "", ctx.NewError("Error: bad id selector")
+      } else if id != "" {
+        return "", nil, "", ctx.NewError("Error: can't have multiple ids")
       }
 
-      elementName = parts[0]
-      id = parts[1]
-
-      if strings.Contains(elementName, ".") {
-        return "", "", "", ctx.NewError("Error: can't mix id and class selector")
-      } else if strings.Contains(id, ".") {
-        return "", "", "", ctx.NewError("Error: can't mix id and class selector")
+      id = part[1:]
+    default:
+      if i != 0 {
+        panic("algo error")
       }
-    } else {
-      elementName = w
+
+      elementName = part
     }
   }
 
-  return strings.TrimSpace(elementName), strings.TrimSpace(class), strings.TrimSpace(id), nil
+  return strings.TrimSpace(elementName), classes, strings.TrimSpace(id), nil
+}
+
+// the tokenizer drops whitespace, which is a descendant combinator in front of attribute selectors and pseudo classes
+func isSeparated(prev raw.Token, t raw.Token) bool {
+  if prev == nil {
+    return false
+  }
+
+  prevCtx := prev.Context()
+  return !prevCtx.IsConsecutive(t.Context())
 }
 
 // use a dummy *SelectorData to return all the vlues
-func parseFiltersPseudoAndDescendants(ts []raw.Token) (*SelectorData, error) {
+// prev is the token in front of ts (nil if there is none)
+func parseFiltersPseudoAndDescendants(ts []raw.Token, prev raw.Token) (*SelectorData, error) {
   ctx := raw.MergeContexts(ts...)
 
   filters := make([]AttrFilter, 0)
@@ -283,6 +287,14 @@ func parseFiltersPseudoAndDescendants(ts []raw.Token) (*SelectorData, error) {
     done := false
 
     switch {
+    case (raw.IsBracketsGroup(t) || raw.IsSymbol(t, ":") || raw.IsSymbol(t, "::")) && isSeparated(prev, t):
+      // eg. "body :first-child"
+      descendant, err = ParseSelector(ts[i:])
+      if err != nil {
+        return nil, err
+      }
+
+      done = true
     case raw.IsBracketsGroup(t):
       if len(pseudoClasses) != 0 {
         errCtx := t.Context()
@@ -388,27 +400,41 @@ func parseFiltersPseudoAndDescendants(ts []raw.Token) (*SelectorData, error) {
     if done {
       break
     }
+
+    prev = ts[i]
   }
 
-  return &SelectorData{"", "", "", filters, pseudoClasses, pseudoElement, descendant, sibling, immediate, ctx}, nil
+  return &SelectorData{"", []string{}, "", filters, pseudoClasses, pseudoElement, descendant, sibling, immediate, ctx}, nil
 }
 
 func ParseSelector(ts []raw.Token) (*SelectorData, error) {
-  elementName, class, id, err := parseNameToken(ts[0])
+  // implicit universal selector, eg. :root or [disabled]
+  if raw.IsBracketsGroup(ts[0]) || raw.IsSymbol(ts[0], ":") || raw.IsSymbol(ts[0], "::") {
+    return parseFiltersPseudoAndDescendants(ts, nil)
+  }
+
+  // *.class and *#id
+  if raw.IsSymbol(ts[0], "*") && len(ts) > 1 && raw.IsAnyWord(ts[1]) && !isSeparated(ts[0], ts[1]) {
+    if w, err := raw.AssertWord(ts[1]); err == nil && (strings.HasPrefix(w.Value(), ".") || strings.HasPrefix(w.Value(), "#")) {
+      ts = ts[1:]
+    }
+  }
+
+  elementName, classes, id, err := parseNameToken(ts[0])
   if err != nil {
     return nil, err
   }
 
   if len(ts) == 1 {
-    return &SelectorData{elementName, class, id, []AttrFilter{}, []PseudoClass{}, "", nil, nil, false, ts[0].Context()}, nil
+    return &SelectorData{elementName, classes, id, []AttrFilter{}, []PseudoClass{}, "", nil, nil, false, ts[0].Context()}, nil
   } else {
-    sel, err := parseFiltersPseudoAndDescendants(ts[1:])
+    sel, err := parseFiltersPseudoAndDescendants(ts[1:], ts[0])
     if err != nil {
       return nil, err
     }
 
     sel.elementName = elementName
-    sel.class = class
+    sel.classes = classes
     sel.id = id
 
     return sel, nil
@@ -463,7 +489,7 @@ func (s *SelectorData) extend(ts []raw.Token) (Selector, error) {
 
     res = s.setDescendant(descendant, false)
   case raw.IsBracketsGroup(ts[0]) || raw.IsSymbol(ts[0], ":") || raw.IsSymbol(ts[0], "::"):
-    sel, err := parseFiltersPseudoAndDescendants(ts)
+    sel, err := parseFiltersPseudoAndDescendants(ts, nil)
     if err != nil {
       return nil, err
     }
@@ -510,118 +536,27 @@ func (s *SelectorData) Extend(extra *tokens.String) ([]Selector, error) {
   return sels, nil
 }
 
-// see if name or attr match, don't yet look at descendants or siblings
-func (s *SelectorData) match(tag tree.Tag) bool {
-  if s.elementName != "" && s.elementName != "*" {
-    if s.elementName != tag.Name() {
-      return false
-    }
-  }
-
-  if s.class != "" {
-    foundClass := false
-    visTag, ok := tag.(tree.VisibleTag)
-    if ok {
-      classes := visTag.GetClasses()
-      for _, class := range classes {
-        if strings.TrimSpace(class) == s.class {
-          foundClass = true
-          break
-        }
-      }
-    }
-
-    if !foundClass {
-      return false
-    }
-  }
-
-  if s.id != "" {
-    if tag.GetID() != s.id {
-      return false
-    }
-  }
-
-  for _, f := range s.filters {
-    if !f.Match(tag) {
-      return false
-    }
-  }
-
-  return true
-}
-
-func (s *SelectorData) Match(tag tree.Tag) []tree.Tag {
-  if tag.Name() == "head" {
-    // don't go any deeper
-    return []tree.Tag{}
-  } else if s.match(tag) {
-    if s.sibling == nil && s.descendant == nil {
-      return []tree.Tag{tag}
-    } else if s.sibling != nil {
-      siblings := tag.LaterSiblings()
-      if len(siblings) == 0 {
-        return []tree.Tag{}
-      } else if s.immediate {
-        return s.sibling.Match(siblings[0])
-      } else {
-        res := []tree.Tag{}
-        for _, sib := range siblings {
-          sibRes := s.sibling.Match(sib)
-          res = append(res, sibRes...)
-        }
-        return res
-      }
-    } else if s.descendant != nil {
-      res := []tree.Tag{}
-      for _, child := range tag.Children() {
-        if s.immediate {
-          if !s.descendant.match(child) {
-            continue
-          }
-        }
-
-        childRes := s.descendant.Match(child)
-        res = append(res, childRes...)
-      }
-
-      return res
-    } else {
-      panic("algo error")
-    }
-  } else {
-    // ignore this tag, and look at any of the children
-    res := []tree.Tag{}
-    for _, child := range tag.Children() {
-      childRes := s.Match(child)
-      res = append(res, childRes...)
-    }
-
-    return res
-  }
-}
-
 func (s *SelectorData) Write() string {
   var b strings.Builder
 
   if s.elementName == "*" {
-    if s.class == "" && s.id == "" {
+    if len(s.classes) == 0 && s.id == "" {
       b.WriteString("*")
     }
   } else if s.elementName != "" {
     b.WriteString(s.elementName)
   }
 
-  if s.class != "" {
-    b.WriteString(".")
-    b.WriteString(s.class)
-  }
-
   if s.id != "" {
     b.WriteString("#")
     b.WriteString(s.id)
   }
 
+  for _, class := range s.classes {
+    b.WriteString(".")
+    b.WriteString(class)
+  }
+
   for _, f := range s.filters {
     b.WriteString("[")
     b.WriteString(f.Write())
@@ -6,6 +6,7 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/directives"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
 	"github.com/wtsuite/wtsuite/pkg/tokens/patterns"
 	"github.com/wtsuite/wtsuite/pkg/tree"
 )
@@ -18,6 +19,11 @@ type Sheet interface {
   Write(compr bool, nl string, tab string) (string, error)
   ExpandNested() (Sheet, error) // expanding a second time does nothing
   ApplyExtensions(root *tree.Root) (*tree.Root, error)
+  MatchDeclarations(root *tree.Root) (map[tree.Tag]map[string]tokens.Token, error)
+  Purge(roots []*tree.Root, keepClass func(class string) bool) (Sheet, error)
+  Critical(root *tree.Root, isCritical func(t tree.Tag) bool) (Sheet, error)
+  Target(browsers *Browsers) (Sheet, error)
+  Minify() (Sheet, error)
 }
 
 type SheetData struct {
@@ -41,27 +47,60 @@ func (s *SheetData) Len() int {
 }
 
 func (s *SheetData) Write(compr bool, nl string, tab string) (string, error) {
+  if MINIFY {
+    minified, err := s.Minify()
+    if err != nil {
+      return "", err
+    }
+
+    s = minified.(*SheetData)
+  }
+
   var b strings.Builder
 
+  // imports must precede all other rules
+  for _, r := range s.rules {
+    if isPreambleRule(r) {
+      inner, err := r.Write("", nl, tab)
+      if err != nil {
+        return "", err
+      }
+      b.WriteString(inner)
+    }
+  }
+
   if directives.MATH_FONT_URL != "" {
     b.WriteString(writeMathFontFace(directives.MATH_FONT_URL))
   }
 
+  // compress moves at rules to the end, so the at rules of plain css files act as barriers to keep their source order
+  var res strings.Builder
   for _, r := range s.rules {
+    if isPreambleRule(r) {
+      continue
+    }
+
     inner, err := r.Write("", nl, tab)
     if err != nil {
       return "", err
     }
-    b.WriteString(inner)
-  }
 
-  res := b.String()
+    if compr && isImportedAtRule(r) {
+      res.WriteString(compress(b.String()))
+      res.WriteString(inner)
+      b.Reset()
+    } else {
+      b.WriteString(inner)
+    }
+  }
 
   if compr {
-    res = compress(res)
+    res.WriteString(compress(b.String()))
+  } else {
+    res.WriteString(b.String())
   }
 
-  return res, nil
+  return res.String(), nil
 }
 
 func (s *SheetData) ExpandNested() (Sheet, error) {
@@ -108,6 +147,41 @@ func (s *SheetData) ApplyExtensions(root *tree.Root) (*tree.Root, error) {
   return root, nil
 }
 
+// declarations of the plain rules that match each tag, later rules override earlier rules (specificity is ignored)
+// at-rules are ignored, and rules with dynamic pseudo classes (eg. :hover) or pseudo elements don't match
+func (s *SheetData) MatchDeclarations(root *tree.Root) (map[tree.Tag]map[string]tokens.Token, error) {
+  _, htmlTag, err := root.GetDocTypeAndHTML()
+  if err != nil {
+    return nil, err
+  }
+
+  res := make(map[tree.Tag]map[string]tokens.Token)
+
+  for _, r_ := range s.rules {
+    r, ok := r_.(*RuleData)
+    if !ok {
+      continue
+    }
+
+    for _, tag := range r.sel.Match(htmlTag) {
+      decls, ok := res[tag]
+      if !ok {
+        decls = make(map[string]tokens.Token)
+        res[tag] = decls
+      }
+
+      if err := r.attr.Loop(func(key *tokens.String, value tokens.Token, last bool) error {
+        decls[key.Value()] = value
+        return nil
+      }); err != nil {
+        return nil, err
+      }
+    }
+  }
+
+  return res, nil
+}
+
 func WriteSheetToFile(s Sheet, path string) error {
   content, err := s.Write(true, patterns.NL, patterns.TAB)
   if err != nil {
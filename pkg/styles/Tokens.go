@@ -0,0 +1,207 @@
+package styles
+
+import (
+  "fmt"
+  "os"
+  "regexp"
+  "strings"
+
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+var (
+  TOKEN_NAME_REGEXP = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+  VAR_REF_REGEXP    = regexp.MustCompile(`var\(\s*(--[a-zA-Z0-9_\-]+)\s*([,)])`)
+)
+
+// design tokens are emitted as custom properties on :root, eg.:
+//  "@tokens color": {
+//    primary: #3366ff, // --color-primary
+//    text: {muted: #666}, // groups are joined with dashes: --color-text-muted
+//    "@media (prefers-color-scheme: dark)": {primary: #88aaff}, // theme override
+//    ".dark": {primary: #88aaff}, // theme override for the elements with the class (and their descendants)
+//  }
+// the label after @tokens is optional, and is used as the prefix of all the names
+func NewTokensRule(sel Selector, key *tokens.String, attr *tokens.StringDict) ([]Rule, error) {
+  ctx := key.Context()
+  if sel != nil {
+    return nil, ctx.NewError("Error: @tokens must be top-level")
+  }
+
+  prefix := ""
+  if label := atRuleArgs(key); label != "" {
+    if !TOKEN_NAME_REGEXP.MatchString(label) {
+      return nil, ctx.NewError("Error: invalid tokens label")
+    }
+
+    prefix = label + "-"
+  }
+
+  rootDecls := tokens.NewEmptyStringDict(ctx)
+  themes := tokens.NewEmptyStringDict(ctx)
+
+  if err := flattenTokens(prefix, attr, rootDecls, themes); err != nil {
+    return nil, err
+  }
+
+  d := tokens.NewEmptyStringDict(ctx)
+  d.Set(tokens.NewValueString(":root", ctx), rootDecls)
+  if err := themes.Loop(func(themeKey *tokens.String, theme tokens.Token, last bool) error {
+    d.Set(themeKey, theme)
+    return nil
+  }); err != nil {
+    return nil, err
+  }
+
+  leafAttr, rules, err := expandNested(nil, d)
+  if err != nil {
+    return nil, err
+  }
+
+  if leafAttr.Len() != 0 {
+    panic("unexpected leaf attributes")
+  }
+
+  return rules, nil
+}
+
+func isThemeSelector(key string) bool {
+  return strings.HasPrefix(key, ".") || strings.HasPrefix(key, "[") || strings.HasPrefix(key, ":") ||
+    strings.HasPrefix(key, "#")
+}
+
+// themes is nil inside themes (theme selectors can't be nested)
+func flattenTokens(prefix string, attr *tokens.StringDict, decls *tokens.StringDict, themes *tokens.StringDict) error {
+  return attr.Loop(func(key *tokens.String, value_ tokens.Token, last bool) error {
+    if tokens.IsNull(value_) {
+      return nil
+    }
+
+    k := key.Value()
+
+    switch {
+    case strings.HasPrefix(k, "@"):
+      value, err := tokens.AssertStringDict(value_)
+      if err != nil {
+        return err
+      }
+
+      // overrides of several groups under the same at-rule are merged
+      var sub *tokens.StringDict
+      if prev_, ok := decls.Get(k); ok {
+        sub, err = tokens.AssertStringDict(prev_)
+        if err != nil {
+          return err
+        }
+      } else {
+        sub = tokens.NewEmptyStringDict(value.Context())
+        decls.Set(key, sub)
+      }
+
+      return flattenTokens(prefix, value, sub, nil)
+    case isThemeSelector(k):
+      if themes == nil {
+        errCtx := key.Context()
+        return errCtx.NewError("Error: theme selectors can't be nested in themes")
+      }
+
+      value, err := tokens.AssertStringDict(value_)
+      if err != nil {
+        return err
+      }
+
+      var sub *tokens.StringDict
+      if prev_, ok := themes.Get(k); ok {
+        sub, err = tokens.AssertStringDict(prev_)
+        if err != nil {
+          return err
+        }
+      } else {
+        sub = tokens.NewEmptyStringDict(value.Context())
+        themes.Set(key, sub)
+      }
+
+      return flattenTokens(prefix, value, sub, nil)
+    case !TOKEN_NAME_REGEXP.MatchString(k):
+      errCtx := key.Context()
+      return errCtx.NewError("Error: invalid token name")
+    case tokens.IsStringDict(value_):
+      value, err := tokens.AssertStringDict(value_)
+      if err != nil {
+        return err
+      }
+
+      return flattenTokens(prefix + k + "-", value, decls, themes)
+    default:
+      decls.Set(tokens.NewValueString("--" + prefix + k, key.Context()), value_)
+      return nil
+    }
+  })
+}
+
+func collectDeclarations(rules []Rule) []*tokens.StringDict {
+  res := make([]*tokens.StringDict, 0)
+
+  for _, r_ := range rules {
+    switch r := r_.(type) {
+    case *RuleData:
+      res = append(res, r.attr)
+    case *AtRule:
+      res = append(res, collectDeclarations(r.rules)...)
+    }
+  }
+
+  return res
+}
+
+// warns about var() references without fallback to custom properties that aren't declared in the sheet
+func (s *SheetData) checkCustomProperties() error {
+  attrs := collectDeclarations(s.rules)
+
+  declared := make(map[string]bool)
+  for _, attr := range attrs {
+    if err := attr.Loop(func(key *tokens.String, value tokens.Token, last bool) error {
+      if strings.HasPrefix(key.Value(), "--") {
+        declared[key.Value()] = true
+      }
+
+      return nil
+    }); err != nil {
+      return err
+    }
+  }
+
+  // declarations are shared by the rules of a selector list
+  checked := make(map[*tokens.StringDict]bool)
+  for _, attr := range attrs {
+    if checked[attr] {
+      continue
+    }
+
+    checked[attr] = true
+
+    if err := attr.Loop(func(key *tokens.String, value_ tokens.Token, last bool) error {
+      if tokens.IsNull(value_) {
+        return nil
+      }
+
+      value, err := declValue(value_)
+      if err != nil {
+        return err
+      }
+
+      for _, m := range VAR_REF_REGEXP.FindAllStringSubmatch(value, -1) {
+        if m[2] == ")" && !declared[m[1]] {
+          errCtx := value_.Context()
+          fmt.Fprintf(os.Stderr, "%s\n", errCtx.NewError("Warning: custom property " + m[1] + " not declared").Error())
+        }
+      }
+
+      return nil
+    }); err != nil {
+      return err
+    }
+  }
+
+  return nil
+}
@@ -17,9 +17,15 @@ var _atRules = make(map[string]AtRuleGen)
 // @page
 // @font-face
 // @keyframes
+// @import
+// @layer
+// @container
+// @property
 
 // wtsuite extensions:
 // @animation
+// @tokens
+// @include (plain css file)
 
 func registerAtRuleGen(key string, fn AtRuleGen) bool {
   _atRules[key] = fn
@@ -104,7 +110,48 @@ func NewPageRule(sel Selector, key *tokens.String, attr *tokens.StringDict) ([]R
   return expandGenericAtRule(sel, key, attr, true)
 }
 
+// eg. "@container sidebar (min-width: 400px)", can be nested like @media
+func NewContainerRule(sel Selector, key *tokens.String, attr *tokens.StringDict) ([]Rule, error) {
+  if !strings.Contains(atRuleArgs(key), "(") {
+    errCtx := key.Context()
+    return nil, errCtx.NewError("Error: expected @container [<name>] (<condition>)")
+  }
+
+  return expandGenericAtRule(sel, key, attr, false)
+}
+
+// eg. "@property --angle": {syntax: "'<angle>'", inherits: "false", "initial-value": "0deg"}
+func NewPropertyRule(sel Selector, key *tokens.String, attr *tokens.StringDict) ([]Rule, error) {
+  ctx := key.Context()
+  if sel != nil {
+    return nil, ctx.NewError("Error: @property must be top-level")
+  }
+
+  if name := atRuleArgs(key); !strings.HasPrefix(name, "--") || strings.ContainsAny(name, " \t\n") {
+    return nil, ctx.NewError("Error: expected @property --<name>")
+  }
+
+  if err := attr.AssertOnlyValidKeys([]string{"syntax", "inherits", "initial-value"}); err != nil {
+    return nil, err
+  }
+
+  for _, required := range []string{"syntax", "inherits"} {
+    if _, ok := attr.Get(required); !ok {
+      return nil, ctx.NewError("Error: @property " + required + " not set")
+    }
+  }
+
+  return []Rule{NewRule(NewAtSelector(key), attr)}, nil
+}
+
 var _mediaOk = registerAtRuleGen("media", NewMediaRule)
 var _supportsOk = registerAtRuleGen("supports", NewSupportsRule)
 var _pageOk = registerAtRuleGen("page", NewPageRule)
 var _keyframesOk = registerAtRuleGen("keyframes", NewKeyframesRule)
+var _fontFaceOk = registerAtRuleGen("font-face", NewFontFaceRule)
+var _importOk = registerAtRuleGen("import", NewImportRule)
+var _layerOk = registerAtRuleGen("layer", NewLayerRule)
+var _containerOk = registerAtRuleGen("container", NewContainerRule)
+var _propertyOk = registerAtRuleGen("property", NewPropertyRule)
+var _tokensOk = registerAtRuleGen("tokens", NewTokensRule)
+var _includeOk = registerAtRuleGen("include", NewIncludeRule)
@@ -0,0 +1,171 @@
+package styles
+
+import (
+  "errors"
+  "regexp"
+  "sort"
+  "strconv"
+  "strings"
+)
+
+// targeted browsers, nil to write the sheets as authored
+var BROWSERS *Browsers = nil
+
+// lowest targeted version of each browser, older versions of the same browser are assumed to be irrelevant
+type Browsers struct {
+  min map[string]float64
+}
+
+var (
+  BROWSERS_LAST_REGEXP    = regexp.MustCompile(`^last\s+([0-9]+)\s+(?:([a-z_]+)\s+)?versions?$`)
+  BROWSERS_VERSION_REGEXP = regexp.MustCompile(`^([a-z_]+)\s*(>=)?\s*([0-9]+(?:\.[0-9]+)?)$`)
+)
+
+// comma separated list of queries, eg. "last 2 versions, safari 12":
+//  * defaults: same as "last 2 versions"
+//  * last <n> versions: the n most recent releases of every browser in the compatibility table
+//  * last <n> <browser> versions
+//  * <browser> <version> or <browser> >= <version>
+func ParseBrowsers(query string) (*Browsers, error) {
+  b := &Browsers{make(map[string]float64)}
+
+  for _, q := range strings.Split(strings.ToLower(query), ",") {
+    q = strings.Join(strings.Fields(q), " ")
+
+    if q == "defaults" {
+      q = "last 2 versions"
+    }
+
+    if m := BROWSERS_LAST_REGEXP.FindStringSubmatch(q); m != nil {
+      n, err := strconv.Atoi(m[1])
+      if err != nil || n < 1 {
+        return nil, errors.New("Error: bad browsers query \"" + q + "\"")
+      }
+
+      if m[2] == "" {
+        for browser, _ := range _browserReleases {
+          b.addLast(browser, n)
+        }
+      } else {
+        browser, ok := _browserAliases[m[2]]
+        if !ok {
+          return nil, errors.New("Error: unknown browser \"" + m[2] + "\"")
+        }
+
+        b.addLast(browser, n)
+      }
+    } else if m := BROWSERS_VERSION_REGEXP.FindStringSubmatch(q); m != nil {
+      browser, ok := _browserAliases[m[1]]
+      if !ok {
+        return nil, errors.New("Error: unknown browser \"" + m[1] + "\"")
+      }
+
+      version, err := strconv.ParseFloat(m[3], 64)
+      if err != nil {
+        return nil, errors.New("Error: bad browser version \"" + m[3] + "\"")
+      }
+
+      b.add(browser, version)
+    } else {
+      return nil, errors.New("Error: bad browsers query \"" + q + "\"")
+    }
+  }
+
+  return b, nil
+}
+
+func (b *Browsers) add(browser string, version float64) {
+  if prev, ok := b.min[browser]; !ok || version < prev {
+    b.min[browser] = version
+  }
+}
+
+func (b *Browsers) addLast(browser string, n int) {
+  releases := _browserReleases[browser]
+  if n > len(releases) {
+    n = len(releases)
+  }
+
+  b.add(browser, releases[len(releases)-n])
+}
+
+func (b *Browsers) names() []string {
+  res := make([]string, 0)
+  for browser, _ := range b.min {
+    res = append(res, browser)
+  }
+
+  sort.Strings(res)
+
+  return res
+}
+
+// resolved targets and the version of the compatibility table, eg. "chrome 130,safari 12@2025.1"
+func (b *Browsers) String() string {
+  lst := make([]string, 0)
+  for _, browser := range b.names() {
+    lst = append(lst, b.describe(browser))
+  }
+
+  return strings.Join(lst, ",") + "@" + COMPAT_TABLE_VERSION
+}
+
+func (b *Browsers) describe(browser string) string {
+  return browser + " " + strconv.FormatFloat(b.min[browser], 'f', -1, 64)
+}
+
+func (b *Browsers) supports(browser string, v compatVersions) bool {
+  since := v.since(browser)
+
+  return since != 0 && b.min[browser] >= since
+}
+
+// prefixes needed by the targets, and the targeted browsers that are left without support (eg. "safari 12")
+func (b *Browsers) check(f compatFeature) ([]string, []string) {
+  prefixes := make([]string, 0)
+  for prefix, _ := range f.prefixes {
+    prefixes = append(prefixes, prefix)
+  }
+
+  sort.Strings(prefixes)
+
+  needed := make([]string, 0)
+  missing := make([]string, 0)
+
+  for _, browser := range b.names() {
+    if b.supports(browser, f.since) {
+      continue
+    }
+
+    found := false
+    for _, prefix := range prefixes {
+      if b.supports(browser, f.prefixes[prefix]) {
+        found = true
+
+        if !stringsContain(needed, prefix) {
+          needed = append(needed, prefix)
+        }
+
+        break
+      }
+    }
+
+    if !found {
+      missing = append(missing, b.describe(browser))
+    }
+  }
+
+  sort.Strings(needed)
+
+  return needed, missing
+}
+
+func stringsContain(lst []string, s string) bool {
+  for _, x := range lst {
+    if x == s {
+      return true
+    }
+  }
+
+  return false
+}
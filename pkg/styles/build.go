@@ -13,6 +13,8 @@ import (
 func BuildDict(d *tokens.StringDict) (Sheet, error) {
   sheet := NewSheet()
 
+  hasTokens := false
+
   // the top level rules are added to the sheet
   if err := d.Loop(func(key *tokens.String, value_ tokens.Token, last bool) error {
     value, err := tokens.AssertStringDict(value_)
@@ -20,6 +22,10 @@ func BuildDict(d *tokens.StringDict) (Sheet, error) {
       return err
     }
 
+    if strings.HasPrefix(key.Value(), "@tokens") {
+      hasTokens = true
+    }
+
     if strings.HasPrefix(key.Value(), "@") {
       atRules, err := ExpandAtRules(nil, key, value)
       if err != nil {
@@ -52,11 +58,26 @@ func BuildDict(d *tokens.StringDict) (Sheet, error) {
     return nil, err
   }
 
+  // references to the tokens of other sheets can't be checked
+  if hasTokens {
+    if err := expandedSheet.(*SheetData).checkCustomProperties(); err != nil {
+      return nil, err
+    }
+  }
+
+  if BROWSERS != nil {
+    return expandedSheet.Target(BROWSERS)
+  }
+
   return expandedSheet, nil
 }
 
-// expects export var style = {...} somewhere in file 
+// expects export var style = {...} somewhere in file, or a plain css file
 func Build(path string, ctx context.Context) (Sheet, error) {
+  if strings.HasSuffix(path, ".css") {
+    return buildCSS(path, ctx)
+  }
+
   // always rebuild the 
   cache := directives.NewFileCache()
 
@@ -100,6 +121,36 @@ func Build(path string, ctx context.Context) (Sheet, error) {
   return BuildDict(d)
 }
 
+// the rules go through the same steps as the rules of a dict
+func buildCSS(path string, ctx context.Context) (Sheet, error) {
+  rules, err := ParseCSSFile(path)
+  if err != nil {
+    if _, ok := err.(*context.ContextError); ok {
+      return nil, err
+    }
+
+    errCtx := ctx
+    return nil, errCtx.NewError("Error: " + err.Error())
+  }
+
+  sheet := &SheetData{rules}
+  expandedSheet, err := sheet.ExpandNested()
+  if err != nil {
+    return nil, err
+  }
+
+  // a plain css file is self-contained, so all its references can be checked
+  if err := expandedSheet.(*SheetData).checkCustomProperties(); err != nil {
+    return nil, err
+  }
+
+  if BROWSERS != nil {
+    return expandedSheet.Target(BROWSERS)
+  }
+
+  return expandedSheet, nil
+}
+
 func BuildFile(input string, outputPath string) error {
   sheet, err := Build(input, context.NewDummyContext())
   if err != nil {
@@ -0,0 +1,149 @@
+package styles
+
+// embedded browser compatibility table, bump the version when the data changes
+const COMPAT_TABLE_VERSION = "2025.1"
+
+// the most recent releases of each browser, oldest first (used by "last N versions")
+var _browserReleases = map[string][]float64{
+  "chrome":  []float64{126, 127, 128, 129, 130, 131},
+  "edge":    []float64{126, 127, 128, 129, 130, 131},
+  "firefox": []float64{128, 129, 130, 131, 132, 133},
+  "safari":  []float64{16.6, 17.4, 17.5, 17.6, 18.0, 18.1},
+  "ios_saf": []float64{16.6, 17.4, 17.5, 17.6, 18.0, 18.1},
+  "samsung": []float64{21, 22, 23, 24, 25, 26},
+  "opera":   []float64{110, 111, 112, 113, 114, 115},
+}
+
+var _browserAliases = map[string]string{
+  "chrome":     "chrome",
+  "chromium":   "chrome",
+  "edge":       "edge",
+  "firefox":    "firefox",
+  "ff":         "firefox",
+  "safari":     "safari",
+  "ios":        "ios_saf",
+  "ios_saf":    "ios_saf",
+  "ios_safari": "ios_saf",
+  "samsung":    "samsung",
+  "opera":      "opera",
+}
+
+// chrome version on which each samsung internet version is based
+var _samsungChrome = [][2]float64{
+  {4, 44}, {5, 51}, {6.2, 56}, {7.2, 59}, {8.2, 63}, {9.2, 67}, {10.1, 71}, {11.1, 75}, {12, 79}, {13, 83}, {14, 87},
+  {15, 90}, {16, 92}, {17, 96}, {18, 99}, {19, 102}, {20, 106}, {21, 110}, {22, 111}, {23, 115}, {24, 117}, {25, 121},
+  {26, 122},
+}
+
+// first chrome, firefox and safari versions with support, 0 for no support
+// edge, opera and samsung are derived from chrome, and ios_saf from safari
+type compatVersions [3]float64
+
+func (v compatVersions) since(browser string) float64 {
+  chrome := v[0]
+
+  switch browser {
+  case "chrome":
+    return chrome
+  case "firefox":
+    return v[1]
+  case "safari", "ios_saf":
+    return v[2]
+  case "edge":
+    if chrome == 0 || chrome > 79 {
+      return chrome
+    }
+
+    return 79
+  case "opera":
+    if chrome == 0 || chrome - 14 > 15 {
+      return chrome - 14
+    }
+
+    return 15
+  case "samsung":
+    if chrome == 0 {
+      return 0
+    }
+
+    for _, pair := range _samsungChrome {
+      if pair[1] >= chrome {
+        return pair[0]
+      }
+    }
+
+    // more recent than the table
+    last := _samsungChrome[len(_samsungChrome)-1]
+    return last[0] + (chrome - last[1])
+  default:
+    panic("unhandled browser")
+  }
+}
+
+// lowering returns equivalent declarations that are supported by older browsers, or nil if the value can't be lowered
+type compatFeature struct {
+  since    compatVersions
+  prefixes map[string]compatVersions
+  lower    func(value string) [][2]string
+}
+
+var _propertyCompat = map[string]compatFeature{
+  "appearance":           compatFeature{compatVersions{84, 80, 15.4}, map[string]compatVersions{"-webkit-": {4, 64, 3.1}, "-moz-": {0, 1, 0}}, nil},
+  "backdrop-filter":      compatFeature{compatVersions{76, 103, 18}, map[string]compatVersions{"-webkit-": {0, 0, 9}}, nil},
+  "box-decoration-break": compatFeature{compatVersions{130, 32, 0}, map[string]compatVersions{"-webkit-": {22, 0, 7}}, nil},
+  "clip-path":            compatFeature{compatVersions{55, 54, 13.1}, map[string]compatVersions{"-webkit-": {24, 0, 7}}, nil},
+  "hyphens":              compatFeature{compatVersions{88, 43, 17}, map[string]compatVersions{"-webkit-": {0, 0, 5.1}}, nil},
+  "mask":                 compatFeature{compatVersions{120, 53, 15.4}, map[string]compatVersions{"-webkit-": {1, 0, 3.1}}, nil},
+  "mask-image":           compatFeature{compatVersions{120, 53, 15.4}, map[string]compatVersions{"-webkit-": {1, 0, 3.1}}, nil},
+  "print-color-adjust":   compatFeature{compatVersions{136, 97, 15.4}, map[string]compatVersions{"-webkit-": {17, 0, 6}}, nil},
+  "tab-size":             compatFeature{compatVersions{21, 91, 7}, map[string]compatVersions{"-moz-": {0, 4, 0}}, nil},
+  "user-select":          compatFeature{compatVersions{54, 69, 0}, map[string]compatVersions{"-webkit-": {6, 0, 3.1}, "-moz-": {0, 2, 0}}, nil},
+
+  "inset":                compatFeature{compatVersions{87, 66, 14.1}, nil, lowerBox("top", "right", "bottom", "left")},
+  "margin-block":         compatFeature{compatVersions{87, 66, 14.1}, nil, lowerPair("margin-top", "margin-bottom")},
+  "margin-inline":        compatFeature{compatVersions{87, 66, 14.1}, nil, lowerSymmetricPair("margin-left", "margin-right")},
+  "margin-inline-start":  compatFeature{compatVersions{87, 41, 12.1}, nil, nil},
+  "margin-inline-end":    compatFeature{compatVersions{87, 41, 12.1}, nil, nil},
+  "padding-block":        compatFeature{compatVersions{87, 66, 14.1}, nil, lowerPair("padding-top", "padding-bottom")},
+  "padding-inline":       compatFeature{compatVersions{87, 66, 14.1}, nil, lowerSymmetricPair("padding-left", "padding-right")},
+  "padding-inline-start": compatFeature{compatVersions{87, 41, 12.1}, nil, nil},
+  "padding-inline-end":   compatFeature{compatVersions{87, 41, 12.1}, nil, nil},
+  "place-content":        compatFeature{compatVersions{59, 53, 9}, nil, lowerPair("align-content", "justify-content")},
+  "place-items":          compatFeature{compatVersions{59, 45, 11}, nil, lowerPair("align-items", "justify-items")},
+  "place-self":           compatFeature{compatVersions{59, 45, 11}, nil, lowerPair("align-self", "justify-self")},
+
+  "accent-color":         compatFeature{compatVersions{93, 92, 15.4}, nil, nil},
+  "aspect-ratio":         compatFeature{compatVersions{88, 89, 15}, nil, nil},
+  "container-type":       compatFeature{compatVersions{105, 110, 16}, nil, nil},
+  "content-visibility":   compatFeature{compatVersions{85, 125, 18}, nil, nil},
+  "overscroll-behavior":  compatFeature{compatVersions{63, 59, 16}, nil, nil},
+  "scroll-behavior":      compatFeature{compatVersions{61, 36, 15.4}, nil, nil},
+  "scroll-snap-type":     compatFeature{compatVersions{69, 68, 11}, nil, nil},
+  "scrollbar-gutter":     compatFeature{compatVersions{94, 97, 18.2}, nil, nil},
+  "text-wrap":            compatFeature{compatVersions{114, 121, 17.4}, nil, nil},
+}
+
+// keyed by "<property>:<value>", the prefix is applied to the value
+var _valueCompat = map[string]compatFeature{
+  "display:contents":    compatFeature{compatVersions{65, 37, 11.1}, nil, nil},
+  "display:flex":        compatFeature{compatVersions{29, 28, 9}, map[string]compatVersions{"-webkit-": {21, 0, 6.1}}, nil},
+  "display:inline-flex": compatFeature{compatVersions{29, 28, 9}, map[string]compatVersions{"-webkit-": {21, 0, 6.1}}, nil},
+  "display:grid":        compatFeature{compatVersions{57, 52, 10.1}, nil, nil},
+  "display:inline-grid": compatFeature{compatVersions{57, 52, 10.1}, nil, nil},
+  "position:sticky":     compatFeature{compatVersions{56, 32, 13}, map[string]compatVersions{"-webkit-": {0, 0, 6.1}}, nil},
+}
+
+// 8 and 4 digit hex colors are lowered to rgba()
+var _hexAlphaCompat = compatFeature{compatVersions{62, 49, 10}, nil, nil}
+
+// keyed by ":<pseudo-class>" or "::<pseudo-element>", prefixed pseudo elements get a rule of their own
+var _selectorCompat = map[string]compatFeature{
+  ":focus-visible": compatFeature{compatVersions{86, 85, 15.4}, nil, nil},
+  ":focus-within":  compatFeature{compatVersions{60, 52, 10.1}, nil, nil},
+  ":has":           compatFeature{compatVersions{105, 121, 15.4}, nil, nil},
+  ":is":            compatFeature{compatVersions{88, 78, 14}, nil, nil},
+  ":where":         compatFeature{compatVersions{88, 78, 14}, nil, nil},
+  "::marker":       compatFeature{compatVersions{86, 68, 11.1}, nil, nil},
+  "::placeholder":  compatFeature{compatVersions{57, 51, 10.1}, map[string]compatVersions{"-webkit-input-": {4, 0, 5}, "-moz-": {0, 19, 0}}, nil},
+  "::selection":    compatFeature{compatVersions{1, 62, 1.1}, map[string]compatVersions{"-moz-": {0, 1, 0}}, nil},
+}
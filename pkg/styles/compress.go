@@ -13,6 +13,7 @@ func compress(raw string) string {
 // scan the input string, removing the queries between "@....{....}" and putting those in amap
 func compressNested(raw string, topLevel bool) string {
 	queries := make(map[string]string)
+	queryKeys := make([]string, 0) // in order of first appearance, because the order of layers matters
 	plainClasses := make(map[string]string) // not done in top level, comes before other output (inside queries), map key is actually body, map value is comma separated list of classes
 	// plainClasses compression can be turned off by always setting topLevel==true
 
@@ -34,7 +35,14 @@ func compressNested(raw string, topLevel bool) string {
 		c := raw[i]
 		if inQueryKey || (!topLevel && inClassKey) {
 			if inQueryKey {
-				if c == '{' {
+				if c == ';' {
+					// statement (eg. @import or @layer), kept in place
+					inQueryKey = false
+					output.WriteString(key.String())
+					output.WriteByte(c)
+					key.Reset()
+					prevNonWhite = '}'
+				} else if c == '{' {
 					inQueryKey = false
 					inQueryBody = true
 
@@ -95,6 +103,7 @@ func compressNested(raw string, topLevel bool) string {
 						if inQueryBody {
 							if prevBody, ok := queries[keyStr]; !ok {
 								queries[keyStr] = bodyStr
+								queryKeys = append(queryKeys, keyStr)
 							} else {
 								// XXX: is this addition slow?
 								queries[keyStr] = prevBody + bodyStr
@@ -159,7 +168,8 @@ func compressNested(raw string, topLevel bool) string {
 
 	finalOutput.WriteString(output.String())
 
-	for k, q := range queries {
+	for _, k := range queryKeys {
+		q := queries[k]
 		finalOutput.WriteString(k)
 		finalOutput.WriteByte('{')
 		finalOutput.WriteString(compressNested(q, false)) // set to true to avoid plainClass compression
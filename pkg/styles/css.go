@@ -0,0 +1,635 @@
+package styles
+
+import (
+  "fmt"
+  "io/ioutil"
+  "os"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/files"
+	"github.com/wtsuite/wtsuite/pkg/functions"
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// plain css (eg. normalize.css, or the theme of a third-party widget) is parsed into the same rules as the dict syntax
+type cssParser struct {
+  src []rune
+  ctx context.Context // of the whole source
+  pos int
+}
+
+// unknown at-rules of plain css (eg. @counter-style or @-webkit-keyframes) are written as authored
+type RawAtRule struct {
+  text      string
+  statement bool // eg. @namespace, written at the start of the sheet
+  ctx       context.Context
+}
+
+type cssDecl struct {
+  name  *tokens.String
+  value *tokens.String
+}
+
+func ParseCSS(raw string, path string) ([]Rule, error) {
+  src := context.NewSource(raw)
+
+  p := &cssParser{[]rune(raw), context.NewContext(src, path), 0}
+
+  return p.parseRules(len(p.src))
+}
+
+func ParseCSSFile(path string) ([]Rule, error) {
+  b, err := ioutil.ReadFile(path)
+  if err != nil {
+    return nil, err
+  }
+
+  return ParseCSS(string(b), path)
+}
+
+func (p *cssParser) context(start, stop int) context.Context {
+  return p.ctx.NewContext(start, stop)
+}
+
+func (p *cssParser) isComment(i int, end int) bool {
+  return i+1 < end && p.src[i] == '/' && p.src[i+1] == '*'
+}
+
+// returns the position after the comment
+func (p *cssParser) skipComment(i int, end int) (int, error) {
+  for j := i + 2; j+1 < end; j++ {
+    if p.src[j] == '*' && p.src[j+1] == '/' {
+      return j + 2, nil
+    }
+  }
+
+  errCtx := p.context(i, i+2)
+  return end, errCtx.NewError("Error: unterminated comment")
+}
+
+// returns the position after the closing quote
+func (p *cssParser) skipString(i int, end int) (int, error) {
+  q := p.src[i]
+  for j := i + 1; j < end; j++ {
+    switch p.src[j] {
+    case '\\':
+      j++
+    case q:
+      return j + 1, nil
+    case '\n':
+      errCtx := p.context(i, j)
+      return end, errCtx.NewError("Error: unterminated string")
+    }
+  }
+
+  errCtx := p.context(i, end)
+  return end, errCtx.NewError("Error: unterminated string")
+}
+
+func (p *cssParser) skipSpaceAndComments(end int) error {
+  for p.pos < end {
+    if p.isComment(p.pos, end) {
+      var err error
+      p.pos, err = p.skipComment(p.pos, end)
+      if err != nil {
+        return err
+      }
+    } else if strings.ContainsRune(" \t\r\n\f", p.src[p.pos]) {
+      p.pos++
+    } else {
+      break
+    }
+  }
+
+  return nil
+}
+
+// scans up to the first stop rune outside strings, comments, parens, brackets and braces, the returned text excludes the
+// comments, i == end if no stop rune is found
+func (p *cssParser) scan(start int, end int, stops string) (string, int, error) {
+  var b strings.Builder
+
+  depth := 0
+  i := start
+  for i < end {
+    c := p.src[i]
+
+    switch {
+    case p.isComment(i, end):
+      next, err := p.skipComment(i, end)
+      if err != nil {
+        return "", end, err
+      }
+
+      b.WriteString(" ")
+      i = next
+      continue
+    case c == '"' || c == '\'':
+      next, err := p.skipString(i, end)
+      if err != nil {
+        return "", end, err
+      }
+
+      b.WriteString(string(p.src[i:next]))
+      i = next
+      continue
+    case c == '\\' && i+1 < end:
+      b.WriteString(string(p.src[i:i+2]))
+      i += 2
+      continue
+    case depth == 0 && strings.ContainsRune(stops, c):
+      return b.String(), i, nil
+    case c == '(' || c == '[' || c == '{':
+      depth++
+    case c == ')' || c == ']' || c == '}':
+      if depth == 0 {
+        errCtx := p.context(i, i+1)
+        return "", end, errCtx.NewError("Error: unmatched '" + string(c) + "'")
+      }
+
+      depth--
+    }
+
+    b.WriteRune(c)
+    i++
+  }
+
+  if depth != 0 {
+    errCtx := p.context(start, end)
+    return "", end, errCtx.NewError("Error: unmatched brace, bracket or parenthesis")
+  }
+
+  return b.String(), i, nil
+}
+
+// the position of the closing brace of the block that starts at open
+func (p *cssParser) blockEnd(open int, end int) (int, error) {
+  _, close, err := p.scan(open+1, end, "}")
+  if err != nil {
+    return end, err
+  }
+
+  if close == end {
+    errCtx := p.context(open, open+1)
+    return end, errCtx.NewError("Error: unterminated block")
+  }
+
+  return close, nil
+}
+
+// the context of the text without the surrounding whitespace
+func (p *cssParser) trimmedString(text string, start int, stop int) *tokens.String {
+  for start < stop && strings.ContainsRune(" \t\r\n\f", p.src[start]) {
+    start++
+  }
+
+  for stop > start && strings.ContainsRune(" \t\r\n\f", p.src[stop-1]) {
+    stop--
+  }
+
+  return tokens.NewValueString(strings.TrimSpace(text), p.context(start, stop))
+}
+
+func (p *cssParser) parseDeclarations(start int, end int) ([]cssDecl, error) {
+  res := make([]cssDecl, 0)
+
+  i := start
+  for i < end {
+    text, stop, err := p.scan(i, end, ";{")
+    if err != nil {
+      return nil, err
+    }
+
+    if stop < end && p.src[stop] == '{' {
+      errCtx := p.context(i, stop)
+      return nil, errCtx.NewError("Error: nested rules aren't supported in plain css")
+    }
+
+    if strings.TrimSpace(text) != "" {
+      colon := strings.Index(text, ":")
+      if colon == -1 {
+        errCtx := p.context(i, stop)
+        return nil, errCtx.NewError("Error: expected <property>: <value>")
+      }
+
+      decl := p.trimmedString(text, i, stop)
+      ctx := decl.Context()
+
+      name := strings.TrimSpace(text[0:colon])
+      if !strings.HasPrefix(name, "--") {
+        name = strings.ToLower(name)
+      }
+
+      value := strings.TrimSpace(text[colon+1:])
+      if name == "" || value == "" {
+        return nil, ctx.NewError("Error: expected <property>: <value>")
+      }
+
+      res = append(res, cssDecl{tokens.NewValueString(name, ctx), tokens.NewValueString(value, ctx)})
+    }
+
+    i = stop + 1
+  }
+
+  return res, nil
+}
+
+var _propertySides = map[string]bool{"top": true, "right": true, "bottom": true, "left": true}
+
+// shorthands and aliases that don't share the name of the properties they set
+var _propertyShorthands = map[string][]string{
+  "columns":           []string{"column-width", "column-count"},
+  "flex-flow":         []string{"flex-direction", "flex-wrap"},
+  "grid-area":         []string{"grid-row", "grid-column"},
+  "word-wrap":         []string{"overflow-wrap"},
+  "page-break-before": []string{"break-before"},
+  "page-break-after":  []string{"break-after"},
+  "page-break-inside": []string{"break-inside"},
+}
+
+// properties of different families are only independent if both families are known (see propertyFamily)
+var _knownPropertyFamilies = map[string]bool{
+  "align": true, "animation": true, "background": true, "border": true, "box": true, "clear": true, "color": true,
+  "content": true, "cursor": true, "display": true, "fill": true, "filter": true, "flex": true, "float": true,
+  "font": true, "grid": true, "height": true, "inset": true, "letter": true, "list": true, "margin": true, "max": true,
+  "min": true, "object": true, "opacity": true, "order": true, "outline": true, "overflow": true, "padding": true,
+  "pointer": true, "position": true, "stroke": true, "text": true, "transform": true, "transition": true,
+  "user": true, "vertical": true, "visibility": true, "width": true, "z": true,
+}
+
+// true if the two properties can set the same value (eg. border-top and border-color, or font and line-height),
+// unknown pairs are assumed to overlap
+func propertiesOverlap(a string, b string) bool {
+  a = VENDOR_PREFIX_REGEXP.ReplaceAllString(a, "$1")
+  b = VENDOR_PREFIX_REGEXP.ReplaceAllString(b, "$1")
+
+  if a == b || strings.HasPrefix(a, b + "-") || strings.HasPrefix(b, a + "-") {
+    return true
+  }
+
+  for _, pair := range [][2]string{{a, b}, {b, a}} {
+    for _, longhand := range _propertyShorthands[pair[0]] {
+      if pair[1] == longhand || strings.HasPrefix(pair[1], longhand + "-") {
+        return true
+      }
+    }
+  }
+
+  if fa, fb := propertyFamily(a), propertyFamily(b); fa != fb {
+    return !_knownPropertyFamilies[fa] || !_knownPropertyFamilies[fb]
+  }
+
+  as := strings.Split(a, "-")
+  bs := strings.Split(b, "-")
+  if as[0] != bs[0] {
+    // eg. font and line-height
+    return true
+  }
+
+  // the first side and the last other word, eg. border-top-left-radius -> top, radius
+  parts := func(words []string) (string, string) {
+    side, aspect := "", ""
+    for _, w := range words[1:] {
+      if _propertySides[w] {
+        if side == "" {
+          side = w
+        }
+      } else {
+        aspect = w
+      }
+    }
+
+    return side, aspect
+  }
+
+  aSide, aAspect := parts(as)
+  bSide, bAspect := parts(bs)
+
+  if aSide != "" && bSide != "" && aSide != bSide {
+    return false
+  }
+
+  return aAspect == "" || bAspect == "" || aAspect == bAspect
+}
+
+// a repeated property (eg. a fallback value followed by a modern value) starts a new dict, so both are kept in order
+// declarations are written in alphabetical order, so a property that would be written before an earlier overlapping
+// property (eg. border after border-top-width) also starts a new dict
+func groupDeclarations(decls []cssDecl, ctx context.Context) []*tokens.StringDict {
+  res := make([]*tokens.StringDict, 0)
+
+  var attr *tokens.StringDict = nil
+  names := make([]string, 0)
+  for _, decl := range decls {
+    name := decl.name.Value()
+    for _, prev := range names {
+      if prev == name || (name < prev && propertiesOverlap(name, prev)) {
+        attr = nil
+        break
+      }
+    }
+
+    if attr == nil {
+      attr = tokens.NewEmptyStringDict(ctx)
+      res = append(res, attr)
+      names = make([]string, 0)
+    }
+
+    attr.Set(decl.name, decl.value)
+    names = append(names, name)
+  }
+
+  return res
+}
+
+// the blocks of at-rules (eg. the src of @font-face, or the frames of @keyframes) are single dicts, so only the last
+// of repeated entries is kept
+func setAtRuleEntry(attr *tokens.StringDict, key *tokens.String, value tokens.Token) {
+  if _, ok := attr.Get(key.Value()); ok {
+    errCtx := key.Context()
+    fmt.Fprintf(os.Stderr, "%s\n", errCtx.NewError("Warning: repeated " + key.Value() + ", only the last is kept").Error())
+  }
+
+  attr.Set(key, value)
+}
+
+func (p *cssParser) parseStyleRule(prelude *tokens.String, open int, close int) ([]Rule, error) {
+  sels, err := ParseSelectorList(prelude)
+  if err != nil {
+    return nil, err
+  }
+
+  decls, err := p.parseDeclarations(open+1, close)
+  if err != nil {
+    return nil, err
+  }
+
+  res := make([]Rule, 0)
+  for _, attr := range groupDeclarations(decls, prelude.Context()) {
+    for _, sel := range sels {
+      res = append(res, NewRule(sel, attr))
+    }
+  }
+
+  return res, nil
+}
+
+// eg. @media, the order of the nested rules and at-rules is kept
+func newNestedAtRules(key *tokens.String, inner []Rule) []Rule {
+  thisAtSelector := NewAtSelector(key)
+  thisAtSelector.imported = true
+
+  res := make([]Rule, 0)
+  subRules := make([]Rule, 0)
+
+  for _, r := range inner {
+    if atRule, ok := r.(*AtRule); ok {
+      if len(subRules) > 0 {
+        res = append(res, NewAtRule(thisAtSelector, subRules))
+        subRules = make([]Rule, 0)
+      }
+
+      atRule.SetParent(thisAtSelector)
+      res = append(res, atRule)
+    } else {
+      subRules = append(subRules, r)
+    }
+  }
+
+  if len(subRules) > 0 {
+    res = append(res, NewAtRule(thisAtSelector, subRules))
+  }
+
+  return res
+}
+
+func (p *cssParser) parseAtRule(prelude *tokens.String, start int, stop int, statement bool) ([]Rule, error) {
+  ctx := prelude.Context()
+
+  name := strings.ToLower(strings.TrimLeft(strings.Fields(prelude.Value())[0], "@"))
+  key := tokens.NewValueString("@" + name + strings.TrimPrefix(prelude.Value(), strings.Fields(prelude.Value())[0]), ctx)
+
+  // unknown at rules are written as is, without the comments
+  raw := func() ([]Rule, error) {
+    text, _, err := p.scan(start, stop, "")
+    if err != nil {
+      return nil, err
+    }
+
+    return []Rule{&RawAtRule{strings.TrimSpace(text), statement, p.context(start, stop)}}, nil
+  }
+
+  if statement {
+    switch name {
+    case "charset":
+      // sheets are always written as utf-8
+      return []Rule{}, nil
+    case "import", "layer":
+      return ExpandAtRules(nil, key, tokens.NewEmptyStringDict(ctx))
+    default:
+      return raw()
+    }
+  }
+
+  open, close := p.pos, stop-1
+
+  switch name {
+  case "media", "supports", "container", "layer":
+    p.pos = open + 1
+    inner, err := p.parseRules(close)
+    if err != nil {
+      return nil, err
+    }
+
+    return newNestedAtRules(key, inner), nil
+  case "font-face", "property", "page":
+    decls, err := p.parseDeclarations(open+1, close)
+    if err != nil {
+      return nil, err
+    }
+
+    attr := tokens.NewEmptyStringDict(ctx)
+    for _, decl := range decls {
+      setAtRuleEntry(attr, decl.name, decl.value)
+    }
+
+    return ExpandAtRules(nil, key, attr)
+  case "keyframes":
+    attr := tokens.NewEmptyStringDict(ctx)
+
+    i := open + 1
+    for {
+      p.pos = i
+      if err := p.skipSpaceAndComments(close); err != nil {
+        return nil, err
+      }
+
+      if p.pos >= close {
+        break
+      }
+
+      text, frameOpen, err := p.scan(p.pos, close, "{")
+      if err != nil {
+        return nil, err
+      }
+
+      if frameOpen == close {
+        errCtx := p.context(p.pos, close)
+        return nil, errCtx.NewError("Error: expected keyframe block")
+      }
+
+      frameClose, err := p.blockEnd(frameOpen, close)
+      if err != nil {
+        return nil, err
+      }
+
+      decls, err := p.parseDeclarations(frameOpen+1, frameClose)
+      if err != nil {
+        return nil, err
+      }
+
+      frame := tokens.NewEmptyStringDict(ctx)
+      for _, decl := range decls {
+        setAtRuleEntry(frame, decl.name, decl.value)
+      }
+
+      setAtRuleEntry(attr, p.trimmedString(text, p.pos, frameOpen), frame)
+
+      i = frameClose + 1
+    }
+
+    return ExpandAtRules(nil, key, attr)
+  default:
+    return raw()
+  }
+}
+
+// up to end, or to the closing brace of a nested at-rule
+func (p *cssParser) parseRules(end int) ([]Rule, error) {
+  res := make([]Rule, 0)
+
+  for {
+    if err := p.skipSpaceAndComments(end); err != nil {
+      return nil, err
+    }
+
+    if p.pos >= end {
+      return res, nil
+    }
+
+    start := p.pos
+
+    text, stop, err := p.scan(start, end, "{;")
+    if err != nil {
+      return nil, err
+    }
+
+    prelude := p.trimmedString(text, start, stop)
+
+    if stop == end || p.src[stop] == ';' {
+      if !strings.HasPrefix(prelude.Value(), "@") {
+        errCtx := prelude.Context()
+        return nil, errCtx.NewError("Error: expected block after selector")
+      }
+
+      rules, err := p.parseAtRule(prelude, start, stop, true)
+      if err != nil {
+        return nil, err
+      }
+
+      res = append(res, rules...)
+      p.pos = stop + 1
+      continue
+    }
+
+    if prelude.Value() == "" {
+      errCtx := p.context(stop, stop+1)
+      return nil, errCtx.NewError("Error: expected selector or at-rule before block")
+    }
+
+    close, err := p.blockEnd(stop, end)
+    if err != nil {
+      return nil, err
+    }
+
+    var rules []Rule
+    if strings.HasPrefix(prelude.Value(), "@") {
+      p.pos = stop
+      rules, err = p.parseAtRule(prelude, start, close+1, false)
+    } else {
+      rules, err = p.parseStyleRule(prelude, stop, close)
+    }
+
+    if err != nil {
+      return nil, err
+    }
+
+    res = append(res, rules...)
+    p.pos = close + 1
+  }
+}
+
+func (r *RawAtRule) Context() context.Context {
+  return r.ctx
+}
+
+func (r *RawAtRule) ExpandNested() ([]Rule, error) {
+  return []Rule{r}, nil
+}
+
+func (r *RawAtRule) Write(indent string, nl string, tab string) (string, error) {
+  if r.statement {
+    return indent + r.text + ";" + nl, nil
+  }
+
+  return indent + r.text + nl, nil
+}
+
+// at rules of plain css files and raw at rule blocks are written in source order
+func isImportedAtRule(r_ Rule) bool {
+  switch r := r_.(type) {
+  case *AtRule:
+    return r.sel.imported
+  case *RawAtRule:
+    return !r.statement
+  default:
+    return false
+  }
+}
+
+// eg. "@include ./normalize.css": {}, top-level or inside at-rules, the path is relative to the current file
+func NewIncludeRule(sel Selector, key *tokens.String, attr *tokens.StringDict) ([]Rule, error) {
+  ctx := key.Context()
+  if sel != nil {
+    return nil, ctx.NewError("Error: @include can't be nested in a rule")
+  }
+
+  if attr.Len() != 0 {
+    errCtx := attr.Context()
+    return nil, errCtx.NewError("Error: expected empty dict")
+  }
+
+  args := strings.Trim(atRuleArgs(key), "\"'")
+  if args == "" {
+    return nil, ctx.NewError("Error: expected @include <path>")
+  }
+
+  path, err := functions.AbsPath(tokens.NewValueString(args, ctx), ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  rules, err := ParseCSSFile(path.Value())
+  if err != nil {
+    if _, ok := err.(*context.ContextError); ok {
+      return nil, err
+    }
+
+    return nil, ctx.NewError("Error: " + err.Error())
+  }
+
+  files.AddDep(ctx.Path(), path.Value())
+
+  return rules, nil
+}
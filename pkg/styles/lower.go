@@ -0,0 +1,464 @@
+package styles
+
+import (
+  "fmt"
+  "os"
+  "regexp"
+  "strconv"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+var HEX_ALPHA_REGEXP = regexp.MustCompile(`#(?:[0-9a-fA-F]{8}|[0-9a-fA-F]{4})\b`)
+
+// state of Target, declarations are often shared by several rules (eg. selector lists)
+type lowering struct {
+  browsers *Browsers
+  decls    map[*tokens.StringDict]loweredDecls
+  warned   map[string]bool
+}
+
+// fallbacks contain the prefixed values, and must be written before the lowered declarations
+type loweredDecls struct {
+  attr      *tokens.StringDict
+  fallbacks []*tokens.StringDict
+}
+
+// splits on whitespace outside parentheses
+func splitValue(value string) []string {
+  res := make([]string, 0)
+
+  depth := 0
+  start := -1
+  for i, c := range value {
+    switch {
+    case c == '(':
+      depth += 1
+    case c == ')':
+      depth -= 1
+    case (c == ' ' || c == '\t' || c == '\n') && depth == 0:
+      if start != -1 {
+        res = append(res, value[start:i])
+        start = -1
+      }
+
+      continue
+    }
+
+    if start == -1 {
+      start = i
+    }
+  }
+
+  if start != -1 {
+    res = append(res, value[start:])
+  }
+
+  return res
+}
+
+// eg. inset
+func lowerBox(top, right, bottom, left string) func(value string) [][2]string {
+  return func(value string) [][2]string {
+    vs := splitValue(value)
+
+    switch len(vs) {
+    case 1:
+      vs = []string{vs[0], vs[0], vs[0], vs[0]}
+    case 2:
+      vs = []string{vs[0], vs[1], vs[0], vs[1]}
+    case 3:
+      vs = []string{vs[0], vs[1], vs[2], vs[1]}
+    case 4:
+    default:
+      return nil
+    }
+
+    return [][2]string{{top, vs[0]}, {right, vs[1]}, {bottom, vs[2]}, {left, vs[3]}}
+  }
+}
+
+// eg. place-items
+func lowerPair(first, second string) func(value string) [][2]string {
+  return func(value string) [][2]string {
+    vs := splitValue(value)
+
+    switch len(vs) {
+    case 1:
+      return [][2]string{{first, vs[0]}, {second, vs[0]}}
+    case 2:
+      return [][2]string{{first, vs[0]}, {second, vs[1]}}
+    default:
+      return nil
+    }
+  }
+}
+
+// eg. margin-inline, left and right are only equivalent if they get the same value (the start side depends on the direction)
+func lowerSymmetricPair(first, second string) func(value string) [][2]string {
+  return func(value string) [][2]string {
+    vs := splitValue(value)
+
+    switch {
+    case len(vs) == 1, len(vs) == 2 && vs[0] == vs[1]:
+      return [][2]string{{first, vs[0]}, {second, vs[0]}}
+    default:
+      return nil
+    }
+  }
+}
+
+// applies fn to the parts of value outside quoted strings and url() arguments
+func replaceOutsideStrings(value string, fn func(string) string) string {
+  var b strings.Builder
+
+  start := 0
+  for i := 0; i < len(value); i++ {
+    end := -1
+    switch {
+    case value[i] == '"' || value[i] == '\'':
+      end = i + 1
+      for end < len(value) && value[end] != value[i] {
+        if value[end] == '\\' {
+          end += 1
+        }
+
+        end += 1
+      }
+    case i+4 <= len(value) && strings.EqualFold(value[i:i+4], "url("):
+      end = strings.Index(value[i:], ")")
+      if end != -1 {
+        end += i
+      }
+    default:
+      continue
+    }
+
+    if end == -1 || end >= len(value) {
+      end = len(value) - 1
+    }
+
+    b.WriteString(fn(value[start:i]))
+    b.WriteString(value[i:end+1])
+
+    start = end + 1
+    i = end
+  }
+
+  b.WriteString(fn(value[start:]))
+
+  return b.String()
+}
+
+// quoted strings and urls (eg. fragments) are kept as is
+func lowerHexAlpha(value string) string {
+  return replaceOutsideStrings(value, lowerHexAlphaColors)
+}
+
+func lowerHexAlphaColors(value string) string {
+  return HEX_ALPHA_REGEXP.ReplaceAllStringFunc(value, func(hex string) string {
+    digits := hex[1:]
+    if len(digits) == 4 {
+      digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2], digits[3], digits[3]})
+    }
+
+    cs := make([]int64, 4)
+    for i := 0; i < 4; i++ {
+      cs[i], _ = strconv.ParseInt(digits[2*i:2*i+2], 16, 64)
+    }
+
+    alpha := strconv.FormatFloat(float64(cs[3])/255.0, 'f', 3, 64)
+    alpha = strings.TrimRight(strings.TrimRight(alpha, "0"), ".")
+
+    return "rgba(" + strconv.FormatInt(cs[0], 10) + "," + strconv.FormatInt(cs[1], 10) + "," +
+      strconv.FormatInt(cs[2], 10) + "," + alpha + ")"
+  })
+}
+
+// colors are written in their most compact css form (eg. red instead of #f00)
+func declValue(v tokens.Token) (string, error) {
+  if tokens.IsList(v) {
+    lst, err := tokens.AssertList(v)
+    if err != nil {
+      return "", err
+    }
+
+    parts := make([]string, 0)
+    if err := lst.Loop(func(i int, item tokens.Token, last bool) error {
+      part, err := declValue(item)
+      if err != nil {
+        return err
+      }
+
+      parts = append(parts, part)
+      return nil
+    }); err != nil {
+      return "", err
+    }
+
+    return strings.Join(parts, " "), nil
+  }
+
+  if tokens.IsColor(v) {
+    c, err := tokens.AssertColor(v)
+    if err != nil {
+      return "", err
+    }
+
+    return c.WriteCSS(), nil
+  }
+
+  p, err := tokens.AssertPrimitive(v)
+  if err != nil {
+    return "", err
+  }
+
+  return p.Write(), nil
+}
+
+// null values are ignored
+func declValues(attr *tokens.StringDict) (map[string]string, error) {
+  m := make(map[string]string)
+
+  if err := attr.Loop(func(key *tokens.String, value tokens.Token, last bool) error {
+    if tokens.IsNull(value) {
+      return nil
+    }
+
+    v, err := declValue(value)
+    if err != nil {
+      return err
+    }
+
+    m[key.Value()] = v
+    return nil
+  }); err != nil {
+    return nil, err
+  }
+
+  return m, nil
+}
+
+// sorted declarations
+func writeDeclarations(attr *tokens.StringDict, indent string, nl string) (string, error) {
+  m, err := declValues(attr)
+  if err != nil {
+    return "", err
+  }
+
+  return tokens.StringMapToString(m, indent, nl), nil
+}
+
+// ":<pseudo-class>" and "::<pseudo-element>" in the chain of descendants and siblings
+func (s *SelectorData) pseudoNames() []string {
+  res := make([]string, 0)
+
+  for _, p := range s.pseudoClasses {
+    name := p.Write()
+    if i := strings.Index(name, "("); i != -1 {
+      name = name[0:i]
+    }
+
+    res = append(res, ":" + name)
+  }
+
+  if s.pseudoElement != "" {
+    res = append(res, "::" + s.pseudoElement)
+  }
+
+  if s.descendant != nil {
+    res = append(res, s.descendant.pseudoNames()...)
+  }
+
+  if s.sibling != nil {
+    res = append(res, s.sibling.pseudoNames()...)
+  }
+
+  return res
+}
+
+func (s *SelectorData) replacePseudoElement(pseudoElement string) *SelectorData {
+  cpy := s.Copy()
+  if cpy.pseudoElement != "" {
+    cpy.pseudoElement = pseudoElement
+  }
+
+  if cpy.descendant != nil {
+    cpy.descendant = cpy.descendant.replacePseudoElement(pseudoElement)
+  }
+
+  if cpy.sibling != nil {
+    cpy.sibling = cpy.sibling.replacePseudoElement(pseudoElement)
+  }
+
+  return cpy
+}
+
+func (l *lowering) warn(ctx context.Context, feature string, missing []string) {
+  msg := ctx.NewError("Warning: " + feature + " not supported by " + strings.Join(missing, ", ")).Error()
+
+  if !l.warned[msg] {
+    l.warned[msg] = true
+    fmt.Fprintf(os.Stderr, "%s\n", msg)
+  }
+}
+
+func (l *lowering) lowerDeclarations(attr *tokens.StringDict) (loweredDecls, error) {
+  if res, ok := l.decls[attr]; ok {
+    return res, nil
+  }
+
+  res := loweredDecls{tokens.NewEmptyStringDict(attr.Context()), make([]*tokens.StringDict, 0)}
+
+  fallback := func(i int) *tokens.StringDict {
+    for len(res.fallbacks) <= i {
+      res.fallbacks = append(res.fallbacks, tokens.NewEmptyStringDict(attr.Context()))
+    }
+
+    return res.fallbacks[i]
+  }
+
+  if err := attr.Loop(func(key *tokens.String, value_ tokens.Token, last bool) error {
+    if tokens.IsNull(value_) {
+      return nil
+    }
+
+    value, err := declValue(value_)
+    if err != nil {
+      return err
+    }
+
+    var valueToken tokens.Token = value_
+    if HEX_ALPHA_REGEXP.MatchString(value) {
+      if _, missing := l.browsers.check(_hexAlphaCompat); len(missing) > 0 {
+        if lowered := lowerHexAlpha(value); lowered != value {
+          value = lowered
+          valueToken = tokens.NewValueString(value, value_.Context())
+        }
+      }
+    }
+
+    name := key.Value()
+
+    if f, ok := _propertyCompat[name]; ok {
+      prefixes, missing := l.browsers.check(f)
+
+      if len(missing) > 0 && f.lower != nil {
+        if decls := f.lower(value); decls != nil {
+          for _, decl := range decls {
+            res.attr.Set(tokens.NewValueString(decl[0], key.Context()), tokens.NewValueString(decl[1], value_.Context()))
+          }
+
+          return nil
+        }
+      }
+
+      for _, prefix := range prefixes {
+        res.attr.Set(tokens.NewValueString(prefix + name, key.Context()), valueToken)
+      }
+
+      if len(missing) > 0 {
+        l.warn(key.Context(), name, missing)
+      }
+    }
+
+    if f, ok := _valueCompat[name + ":" + strings.TrimSpace(value)]; ok {
+      prefixes, missing := l.browsers.check(f)
+
+      for i, prefix := range prefixes {
+        fallback(i).Set(key, tokens.NewValueString(prefix + strings.TrimSpace(value), value_.Context()))
+      }
+
+      if len(missing) > 0 {
+        l.warn(key.Context(), name + ": " + strings.TrimSpace(value), missing)
+      }
+    }
+
+    res.attr.Set(key, valueToken)
+
+    return nil
+  }); err != nil {
+    return res, err
+  }
+
+  l.decls[attr] = res
+
+  return res, nil
+}
+
+// prefixed pseudo elements are returned as separate selectors, because an unknown pseudo element invalidates the whole rule
+func (l *lowering) lowerSelector(sel *SelectorData) []*SelectorData {
+  res := make([]*SelectorData, 0)
+
+  for _, name := range sel.pseudoNames() {
+    f, ok := _selectorCompat[name]
+    if !ok {
+      continue
+    }
+
+    prefixes, missing := l.browsers.check(f)
+    if strings.HasPrefix(name, "::") {
+      for _, prefix := range prefixes {
+        res = append(res, sel.replacePseudoElement(prefix + name[2:]))
+      }
+    }
+
+    if len(missing) > 0 {
+      l.warn(sel.Context(), name, missing)
+    }
+  }
+
+  return res
+}
+
+func (l *lowering) lowerRules(rules []Rule) ([]Rule, error) {
+  res := make([]Rule, 0)
+
+  for _, r_ := range rules {
+    switch r := r_.(type) {
+    case *RuleData:
+      decls, err := l.lowerDeclarations(r.attr)
+      if err != nil {
+        return nil, err
+      }
+
+      if sel, ok := r.sel.(*SelectorData); ok {
+        for _, variant := range l.lowerSelector(sel) {
+          res = append(res, NewRule(variant, decls.attr))
+        }
+      }
+
+      for _, fallback := range decls.fallbacks {
+        res = append(res, NewRule(r.sel, fallback))
+      }
+
+      res = append(res, NewRule(r.sel, decls.attr))
+    case *AtRule:
+      inner, err := l.lowerRules(r.rules)
+      if err != nil {
+        return nil, err
+      }
+
+      res = append(res, NewAtRule(r.sel, inner))
+    default:
+      // keyframes and wrap rules are kept as authored
+      res = append(res, r)
+    }
+  }
+
+  return res, nil
+}
+
+// adds the vendor prefixes needed by the browsers, lowers modern properties, and warns about unsupported features
+func (s *SheetData) Target(browsers *Browsers) (Sheet, error) {
+  l := &lowering{browsers, make(map[*tokens.StringDict]loweredDecls), make(map[string]bool)}
+
+  rules, err := l.lowerRules(s.rules)
+  if err != nil {
+    return nil, err
+  }
+
+  return &SheetData{rules}, nil
+}
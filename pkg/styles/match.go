@@ -0,0 +1,231 @@
+package styles
+
+import (
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/tree"
+)
+
+// position of an element in the tree that is being matched
+// parents aren't taken from tag.Parent(), because they aren't always registered (eg. after wrapping)
+type selNode struct {
+  tag      tree.Tag
+  parent   *selNode   // nil for the tag at which matching started
+  siblings []tree.Tag // element children of the parent (including tag)
+  index    int        // index of tag in siblings
+}
+
+func isElement(t tree.Tag) bool {
+  if _, ok := t.(*tree.Text); ok {
+    return false
+  }
+
+  name := t.Name()
+
+  return name != "" && !strings.HasPrefix(name, "!")
+}
+
+func elementChildren(t tree.Tag) []tree.Tag {
+  res := make([]tree.Tag, 0)
+  for _, child := range t.Children() {
+    if isElement(child) {
+      res = append(res, child)
+    }
+  }
+
+  return res
+}
+
+func newRootSelNode(t tree.Tag) *selNode {
+  return &selNode{t, nil, []tree.Tag{t}, 0}
+}
+
+func (n *selNode) children() []*selNode {
+  kids := elementChildren(n.tag)
+
+  res := make([]*selNode, len(kids))
+  for i, kid := range kids {
+    res[i] = &selNode{kid, n, kids, i}
+  }
+
+  return res
+}
+
+func (n *selNode) sibling(i int) *selNode {
+  return &selNode{n.siblings[i], n.parent, n.siblings, i}
+}
+
+// the start node isn't a descendant of anything
+func (n *selNode) descendants() []*selNode {
+  res := make([]*selNode, 0)
+  for _, child := range n.children() {
+    res = append(res, child)
+    res = append(res, child.descendants()...)
+  }
+
+  return res
+}
+
+// a compound selector, and the combinator to the compound on its left (' ', '>', '+' or '~', 0 for the leftmost)
+// a nil sel is the anchor of a relative selector (eg. in :has(> img))
+type selLink struct {
+  sel  *SelectorData
+  comb byte
+}
+
+func (s *SelectorData) links() []selLink {
+  res := []selLink{selLink{s, 0}}
+
+  for sel := s; sel.descendant != nil || sel.sibling != nil; {
+    var comb byte
+    if sel.descendant != nil {
+      comb = ' '
+      if sel.immediate {
+        comb = '>'
+      }
+
+      sel = sel.descendant
+    } else {
+      comb = '~'
+      if sel.immediate {
+        comb = '+'
+      }
+
+      sel = sel.sibling
+    }
+
+    res = append(res, selLink{sel, comb})
+  }
+
+  return res
+}
+
+// matching is done from right to left
+func matchLinks(links []selLink, i int, n *selNode, anchor tree.Tag) bool {
+  l := links[i]
+  if l.sel == nil {
+    return n.tag == anchor
+  }
+
+  if !l.sel.matchCompound(n) {
+    return false
+  }
+
+  switch l.comb {
+  case 0:
+    return true
+  case ' ':
+    for p := n.parent; p != nil; p = p.parent {
+      if matchLinks(links, i-1, p, anchor) {
+        return true
+      }
+    }
+  case '>':
+    if n.parent != nil {
+      return matchLinks(links, i-1, n.parent, anchor)
+    }
+  case '+':
+    if n.index > 0 {
+      return matchLinks(links, i-1, n.sibling(n.index-1), anchor)
+    }
+  case '~':
+    for j := n.index - 1; j >= 0; j-- {
+      if matchLinks(links, i-1, n.sibling(j), anchor) {
+        return true
+      }
+    }
+  default:
+    panic("unhandled combinator")
+  }
+
+  return false
+}
+
+func (s *SelectorData) matchNode(n *selNode) bool {
+  links := s.links()
+
+  return matchLinks(links, len(links)-1, n, nil)
+}
+
+func selAttr(t tree.Tag, name string) (string, bool) {
+  return (&AttrFilterData{name, "", false}).getValue(t)
+}
+
+// element name, classes, id, attribute filters and pseudo classes, ignoring the combinators
+// pseudo elements never match an element
+func (s *SelectorData) matchCompound(n *selNode) bool {
+  tag := n.tag
+
+  if s.elementName != "" && s.elementName != "*" {
+    if s.elementName != tag.Name() {
+      return false
+    }
+  }
+
+  if len(s.classes) > 0 {
+    visTag, ok := tag.(tree.VisibleTag)
+    if !ok {
+      return false
+    }
+
+    tagClasses := visTag.GetClasses()
+    for _, class := range s.classes {
+      found := false
+      for _, tagClass := range tagClasses {
+        if strings.TrimSpace(tagClass) == class {
+          found = true
+          break
+        }
+      }
+
+      if !found {
+        return false
+      }
+    }
+  }
+
+  if s.id != "" {
+    if tag.GetID() != s.id {
+      return false
+    }
+  }
+
+  for _, f := range s.filters {
+    if !f.Match(tag) {
+      return false
+    }
+  }
+
+  for _, p := range s.pseudoClasses {
+    if !p.Match(n) {
+      return false
+    }
+  }
+
+  return s.pseudoElement == ""
+}
+
+// all the elements in the subtree of tag (including tag itself) that match, in document order
+// the head isn't searched
+func (s *SelectorData) Match(tag tree.Tag) []tree.Tag {
+  res := []tree.Tag{}
+
+  var walk func(n *selNode)
+  walk = func(n *selNode) {
+    if n.tag.Name() == "head" {
+      return
+    }
+
+    if isElement(n.tag) && s.matchNode(n) {
+      res = append(res, n.tag)
+    }
+
+    for _, child := range n.children() {
+      walk(child)
+    }
+  }
+
+  walk(newRootSelNode(tag))
+
+  return res
+}
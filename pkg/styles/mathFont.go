@@ -25,8 +25,12 @@ func writeMathFontFace(mathFontUrl string) string {
   return b.String()
 }
 
+func MathFontData() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(serif.Woff2Blob)
+}
+
 func SaveMathFont(dst string) error {
-	data, err := base64.StdEncoding.DecodeString(serif.Woff2Blob)
+	data, err := MathFontData()
 	if err != nil {
 		return err
 	}
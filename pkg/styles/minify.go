@@ -0,0 +1,695 @@
+package styles
+
+import (
+  "regexp"
+  "sort"
+  "strconv"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tokens/raw"
+	"github.com/wtsuite/wtsuite/pkg/tree"
+)
+
+var (
+  MINIFY        = false // optimization pass on the rules, right before writing a sheet
+  MINIFY_UNSAFE = false // also moves rules past rules with a different specificity
+)
+
+var (
+  MINIFY_HEX_REGEXP    = regexp.MustCompile(`#(?:[0-9a-fA-F]{8}|[0-9a-fA-F]{6}|[0-9a-fA-F]{3,4})\b`)
+  MINIFY_RGB_REGEXP    = regexp.MustCompile(`rgba?\(\s*([0-9]{1,3})\s*,\s*([0-9]{1,3})\s*,\s*([0-9]{1,3})\s*(?:,\s*1(?:\.0*)?\s*)?\)`)
+  MINIFY_NUMBER_REGEXP = regexp.MustCompile(`(^|[\s,(/])(-?[0-9]*\.?[0-9]+)([a-zA-Z%]*)`)
+  VENDOR_PREFIX_REGEXP = regexp.MustCompile(`(^|[^a-zA-Z0-9_-])-(?:webkit|moz|ms|o)-`)
+  MINIFY_KEYWORD_REGEXP = regexp.MustCompile(`(^|[^a-zA-Z0-9_.#-])(-?[a-zA-Z_][a-zA-Z0-9_-]*\(?)`)
+)
+
+// zero lengths don't need a unit (but zero times, angles and percentages do)
+var _lengthUnits = map[string]bool{
+  "px": true, "em": true, "rem": true, "ex": true, "ch": true, "lh": true, "rlh": true,
+  "vw": true, "vh": true, "vmin": true, "vmax": true, "vi": true, "vb": true,
+  "svw": true, "svh": true, "lvw": true, "lvh": true, "dvw": true, "dvh": true,
+  "cqw": true, "cqh": true, "cqi": true, "cqb": true,
+  "cm": true, "mm": true, "q": true, "in": true, "pt": true, "pc": true,
+}
+
+// shorthand, top, right, bottom, left
+var _boxShorthands = [][5]string{
+  {"margin", "margin-top", "margin-right", "margin-bottom", "margin-left"},
+  {"padding", "padding-top", "padding-right", "padding-bottom", "padding-left"},
+  {"border-width", "border-top-width", "border-right-width", "border-bottom-width", "border-left-width"},
+  {"border-style", "border-top-style", "border-right-style", "border-bottom-style", "border-left-style"},
+  {"border-color", "border-top-color", "border-right-color", "border-bottom-color", "border-left-color"},
+}
+
+// pseudo classes and elements supported by every browser, selectors with other pseudo classes aren't merged into lists,
+// because an unsupported selector invalidates the whole list
+var _listablePseudo = map[string]bool{
+  ":hover": true, ":focus": true, ":active": true, ":visited": true, ":link": true,
+  ":first-child": true, ":last-child": true, ":only-child": true, ":nth-child": true, ":nth-last-child": true,
+  ":first-of-type": true, ":last-of-type": true, ":only-of-type": true, ":nth-of-type": true, ":nth-last-of-type": true,
+  ":root": true, ":empty": true, ":target": true, ":checked": true, ":disabled": true, ":enabled": true,
+  "::before": true, "::after": true, "::first-line": true, "::first-letter": true,
+}
+
+// rules with identical declarations are merged into a list, only created by the minifier
+type SelectorList struct {
+  sels []Selector
+}
+
+func (s *SelectorList) Extend(extra *tokens.String) ([]Selector, error) {
+  errCtx := extra.Context()
+  return nil, errCtx.NewError("Error: can't extend a selector list")
+}
+
+func (s *SelectorList) Match(tag tree.Tag) []tree.Tag {
+  res := make([]tree.Tag, 0)
+
+  for _, sel := range s.sels {
+    res = append(res, sel.Match(tag)...)
+  }
+
+  return res
+}
+
+func (s *SelectorList) Write() string {
+  parts := make([]string, len(s.sels))
+
+  for i, sel := range s.sels {
+    parts[i] = sel.Write()
+  }
+
+  return strings.Join(parts, ",")
+}
+
+func (s *SelectorList) Context() context.Context {
+  return s.sels[0].Context()
+}
+
+func selectorsOf(sel Selector) []Selector {
+  if lst, ok := sel.(*SelectorList); ok {
+    return lst.sels
+  }
+
+  return []Selector{sel}
+}
+
+// (ids, classes/attributes/pseudo classes, elements/pseudo elements)
+// false if it depends on the arguments of a pseudo class (eg. :not(), :is())
+func (s *SelectorData) specificity() ([3]int, bool) {
+  var res [3]int
+
+  if s.id != "" {
+    res[0] += 1
+  }
+
+  res[1] += len(s.classes) + len(s.filters)
+
+  for _, p := range s.pseudoClasses {
+    name := p.Write()
+    if i := strings.Index(name, "("); i != -1 {
+      args := name[i:]
+      name = name[0:i]
+      if !(strings.HasPrefix(name, "nth-") && !strings.Contains(args, " of ")) && name != "lang" && name != "dir" {
+        return res, false
+      }
+    }
+
+    res[1] += 1
+  }
+
+  if s.elementName != "" && s.elementName != "*" {
+    res[2] += 1
+  }
+
+  if s.pseudoElement != "" {
+    res[2] += 1
+  }
+
+  for _, next := range []*SelectorData{s.descendant, s.sibling} {
+    if next != nil {
+      nextRes, ok := next.specificity()
+      if !ok {
+        return res, false
+      }
+
+      for i := 0; i < 3; i++ {
+        res[i] += nextRes[i]
+      }
+    }
+  }
+
+  return res, true
+}
+
+func isListable(sel Selector) bool {
+  s, ok := sel.(*SelectorData)
+  if !ok || VENDOR_PREFIX_REGEXP.MatchString(s.Write()) {
+    return false
+  }
+
+  for _, name := range s.pseudoNames() {
+    if !_listablePseudo[name] {
+      return false
+    }
+  }
+
+  return true
+}
+
+func isImportant(value string) bool {
+  return strings.Contains(value, "!important")
+}
+
+// declarations are only dropped or collapsed if the result is certainly the same
+func isPrefixed(name string, value string) bool {
+  return VENDOR_PREFIX_REGEXP.MatchString(name) || VENDOR_PREFIX_REGEXP.MatchString(value)
+}
+
+// the functions and keywords of a value (eg. "rgba(,var(" or "grid"), numbers, lengths and hex colors are parsed by
+// every browser
+func valueKind(value string) string {
+  value = strings.Replace(value, "!important", "", -1)
+
+  kinds := make([]string, 0)
+  for _, m := range MINIFY_KEYWORD_REGEXP.FindAllStringSubmatch(value, -1) {
+    kinds = append(kinds, strings.ToLower(m[2]))
+  }
+
+  sort.Strings(kinds)
+
+  return strings.Join(kinds, ",")
+}
+
+// properties of the same family can override each other (eg. margin and margin-top), unknown pairs are assumed to
+// be in the same family
+func propertyFamily(name string) string {
+  if strings.HasPrefix(name, "--") {
+    return name
+  }
+
+  name = VENDOR_PREFIX_REGEXP.ReplaceAllString(name, "$1")
+
+  switch name {
+  case "line-height":
+    return "font"
+  case "top", "right", "bottom", "left":
+    return "inset"
+  case "gap", "row-gap", "column-gap":
+    return "grid"
+  case "white-space":
+    return "text"
+  }
+
+  if i := strings.Index(name, "-"); i != -1 {
+    name = name[0:i]
+  }
+
+  switch name {
+  case "place", "justify", "align":
+    return "align"
+  }
+
+  return name
+}
+
+func minifyNumber(prefix string, number string, unit string, stripUnit bool) string {
+  f, err := strconv.ParseFloat(number, 64)
+  if err != nil {
+    return prefix + number + unit
+  }
+
+  if f == 0.0 {
+    if stripUnit && _lengthUnits[strings.ToLower(unit)] {
+      unit = ""
+    }
+
+    return prefix + "0" + unit
+  }
+
+  res := strconv.FormatFloat(f, 'f', -1, 64)
+  if strings.HasPrefix(res, "0.") {
+    res = res[1:]
+  } else if strings.HasPrefix(res, "-0.") {
+    res = "-" + res[2:]
+  }
+
+  return prefix + res + unit
+}
+
+// shorter colors and numbers, quoted strings and urls are left alone
+func minifyValue(name string, value string) string {
+  if strings.ContainsAny(value, "\"'") || strings.Contains(value, "url(") {
+    return value
+  }
+
+  ctx := context.NewDummyContext()
+
+  // rgba() with alpha is kept, because it might be a lowered hex color
+  value = MINIFY_RGB_REGEXP.ReplaceAllStringFunc(value, func(s string) string {
+    m := MINIFY_RGB_REGEXP.FindStringSubmatch(s)
+
+    cs := make([]int, 3)
+    for i := 0; i < 3; i++ {
+      c, err := strconv.Atoi(m[i+1])
+      if err != nil || c > 255 {
+        return s
+      }
+
+      cs[i] = c
+    }
+
+    return tokens.NewValueColor(cs[0], cs[1], cs[2], 255, ctx).WriteCSS()
+  })
+
+  value = MINIFY_HEX_REGEXP.ReplaceAllStringFunc(value, func(s string) string {
+    c, err := raw.NewLiteralColor(s, ctx)
+    if err != nil {
+      return s
+    }
+
+    r, g, b, a := c.Values()
+    return tokens.NewValueColor(r, g, b, a, ctx).WriteCSS()
+  })
+
+  // zero lengths can't be unitless inside calc(), and flex would read them as a grow or shrink factor
+  stripUnit := !strings.HasPrefix(name, "--") && name != "flex" && !strings.Contains(value, "(")
+
+  return MINIFY_NUMBER_REGEXP.ReplaceAllStringFunc(value, func(s string) string {
+    m := MINIFY_NUMBER_REGEXP.FindStringSubmatch(s)
+    return minifyNumber(m[1], m[2], m[3], stripUnit)
+  })
+}
+
+// (top, right, bottom, left) as one to four values
+func writeBox(vs [4]string) string {
+  switch {
+  case vs[0] == vs[1] && vs[0] == vs[2] && vs[0] == vs[3]:
+    return vs[0]
+  case vs[0] == vs[2] && vs[1] == vs[3]:
+    return vs[0] + " " + vs[1]
+  case vs[1] == vs[3]:
+    return vs[0] + " " + vs[1] + " " + vs[2]
+  default:
+    return vs[0] + " " + vs[1] + " " + vs[2] + " " + vs[3]
+  }
+}
+
+// declarations are written in alphabetical order, so most longhands override their shorthand (but border-width
+// overrides border-top-width)
+func collapseBoxes(decls map[string]string) {
+  for _, names := range _boxShorthands {
+    var vs [4]string
+    hasShorthand := false
+    n := 0
+
+    if v, ok := decls[names[0]]; ok {
+      if isImportant(v) || isPrefixed(names[0], v) || strings.Contains(v, "var(") {
+        continue
+      }
+
+      parts := splitValue(v)
+      switch len(parts) {
+      case 1:
+        vs = [4]string{parts[0], parts[0], parts[0], parts[0]}
+      case 2:
+        vs = [4]string{parts[0], parts[1], parts[0], parts[1]}
+      case 3:
+        vs = [4]string{parts[0], parts[1], parts[2], parts[1]}
+      case 4:
+        vs = [4]string{parts[0], parts[1], parts[2], parts[3]}
+      default:
+        continue
+      }
+
+      hasShorthand = true
+    }
+
+    ok := true
+    for i := 0; i < 4; i++ {
+      if v, has := decls[names[i+1]]; has {
+        if isImportant(v) || isPrefixed(names[i+1], v) || strings.Contains(v, "var(") || len(splitValue(v)) != 1 {
+          ok = false
+          break
+        }
+
+        if !hasShorthand || names[i+1] > names[0] {
+          vs[i] = strings.TrimSpace(v)
+        }
+        n += 1
+      }
+    }
+
+    if !ok || (!hasShorthand && n < 4) {
+      continue
+    }
+
+    for i := 0; i < 4; i++ {
+      delete(decls, names[i+1])
+    }
+
+    decls[names[0]] = writeBox(vs)
+  }
+
+  collapseBorder(decls)
+}
+
+// border-width, border-style and border-color with single values are collapsed into border, which also resets
+// border-image, so this is only done if the rule already has a border or border-image declaration, and no other border
+// declarations that would be written in between (eg. border-top)
+func collapseBorder(decls map[string]string) {
+  _, hasBorder := decls["border"]
+  _, hasImage := decls["border-image"]
+  if !hasBorder && !hasImage {
+    return
+  }
+
+  vs := make([]string, 0)
+  for _, name := range []string{"border-width", "border-style", "border-color"} {
+    v, ok := decls[name]
+    if !ok || isImportant(v) || isPrefixed(name, v) || strings.Contains(v, "var(") || len(splitValue(v)) != 1 {
+      return
+    }
+
+    vs = append(vs, strings.TrimSpace(v))
+  }
+
+  for name, v := range decls {
+    switch {
+    case name == "border" && (isImportant(v) || isPrefixed(name, v)):
+      return
+    case name == "border" || name == "border-width" || name == "border-style" || name == "border-color":
+    case strings.HasPrefix(name, "border-image") || strings.HasSuffix(name, "-radius") ||
+      name == "border-collapse" || name == "border-spacing":
+    case strings.HasPrefix(name, "border-") || strings.HasPrefix(VENDOR_PREFIX_REGEXP.ReplaceAllString(name, "$1"), "border"):
+      return
+    }
+  }
+
+  for _, name := range []string{"border-width", "border-style", "border-color"} {
+    delete(decls, name)
+  }
+
+  decls["border"] = strings.Join(vs, " ")
+}
+
+type minRule struct {
+  sel      Selector
+  decls    map[string]string
+  ctx      context.Context
+  selKey   string
+  bodyKey  string
+  specs    [][3]int
+  known    bool // all specificities are known
+  important bool
+  listable bool
+  other    Rule // at-rules, keyframes, ... are barriers
+}
+
+func newMinRule(sel Selector, decls map[string]string, ctx context.Context) *minRule {
+  r := &minRule{sel: sel, decls: decls, ctx: ctx}
+  r.update()
+  return r
+}
+
+func (r *minRule) update() {
+  r.selKey = r.sel.Write()
+  r.bodyKey = tokens.StringMapToString(r.decls, "", "")
+
+  r.important = false
+  for _, value := range r.decls {
+    if isImportant(value) {
+      r.important = true
+    }
+  }
+
+  r.specs = make([][3]int, 0)
+  r.known = true
+  r.listable = true
+  for _, sel_ := range selectorsOf(r.sel) {
+    if !isListable(sel_) {
+      r.listable = false
+    }
+
+    sel, ok := sel_.(*SelectorData)
+    if !ok {
+      r.known = false
+      continue
+    }
+
+    spec, ok := sel.specificity()
+    if !ok {
+      r.known = false
+    }
+
+    r.specs = append(r.specs, spec)
+  }
+}
+
+// only properties that are known to be independent don't overlap (see propertiesOverlap)
+func (r *minRule) overlaps(other *minRule) bool {
+  for name, _ := range r.decls {
+    for otherName, _ := range other.decls {
+      if propertiesOverlap(name, otherName) {
+        return true
+      }
+    }
+  }
+
+  return false
+}
+
+// equal is true if every pair of specificities must be equal, false if every pair must differ
+func (r *minRule) compareSpecificity(other *minRule, equal bool) bool {
+  if !r.known || !other.known {
+    return false
+  }
+
+  for _, a := range r.specs {
+    for _, b := range other.specs {
+      if (a == b) != equal {
+        return false
+      }
+    }
+  }
+
+  return true
+}
+
+// moving the rule past the other rule (in either direction) doesn't change the cascade
+// the safe mode never reorders rules with a different specificity
+func (r *minRule) canMovePast(other *minRule) bool {
+  if other.other != nil {
+    return false
+  }
+
+  if !r.overlaps(other) {
+    return MINIFY_UNSAFE || r.compareSpecificity(other, true)
+  }
+
+  return MINIFY_UNSAFE && !r.important && !other.important && r.compareSpecificity(other, false)
+}
+
+func (r *minRule) toRule() Rule {
+  if r.other != nil {
+    return r.other
+  }
+
+  attr := tokens.NewEmptyStringDict(r.ctx)
+  for name, value := range r.decls {
+    attr.Set(name, tokens.NewValueString(value, r.ctx))
+  }
+
+  return NewRule(r.sel, attr)
+}
+
+// later declarations of the same selector override the earlier ones, unless they are less important or prefixed
+// (eg. the fallbacks of Target), or use other functions or keywords (eg. the fallback in color: red; color: lab(...),
+// in case the browser can't parse the later value)
+func dropOverridden(rs []*minRule) {
+  for i, r := range rs {
+    if r.other != nil {
+      continue
+    }
+
+    for _, later := range rs[i+1:] {
+      if later.other != nil || later.selKey != r.selKey {
+        continue
+      }
+
+      for name, value := range later.decls {
+        if prev, ok := r.decls[name]; ok && (isImportant(value) || !isImportant(prev)) &&
+          !isPrefixed(name, prev) && !isPrefixed(name, value) && valueKind(prev) == valueKind(value) {
+          delete(r.decls, name)
+        }
+      }
+    }
+  }
+}
+
+func mergeSelectors(a Selector, b Selector) Selector {
+  sels := make([]Selector, 0)
+  done := make(map[string]bool)
+
+  for _, sel := range append(selectorsOf(a), selectorsOf(b)...) {
+    if key := sel.Write(); !done[key] {
+      done[key] = true
+      sels = append(sels, sel)
+    }
+  }
+
+  return &SelectorList{sels}
+}
+
+// returns nil if r can't be merged with prev, the rules in between are checked by the caller
+func mergeRule(prev *minRule, r *minRule) *minRule {
+  if prev.selKey == r.selKey {
+    // remaining common declarations were kept by dropOverridden, and declarations are written in alphabetical order,
+    // so the later rule can't contain a declaration that would be written before an overlapping one of prev
+    for name, _ := range r.decls {
+      for prevName, _ := range prev.decls {
+        if name == prevName || (name < prevName && propertiesOverlap(name, prevName)) {
+          return nil
+        }
+      }
+    }
+
+    decls := make(map[string]string)
+    for name, value := range prev.decls {
+      decls[name] = value
+    }
+
+    for name, value := range r.decls {
+      decls[name] = value
+    }
+
+    collapseBoxes(decls)
+
+    return newMinRule(prev.sel, decls, prev.ctx)
+  } else if prev.bodyKey == r.bodyKey && prev.listable && r.listable {
+    return newMinRule(mergeSelectors(prev.sel, r.sel), prev.decls, prev.ctx)
+  } else {
+    return nil
+  }
+}
+
+// each rule is merged with the closest preceding rule with the same selector or the same declarations, if one of
+// the two can be moved past the rules in between
+func mergeRules(rs []*minRule) []*minRule {
+  res := make([]*minRule, 0)
+
+  for _, r := range rs {
+    if r.other != nil {
+      res = append(res, r)
+      continue
+    }
+
+    merged := false
+
+    backward := true // r can be moved to the position of the candidate
+    for i := len(res) - 1; i >= 0; i-- {
+      prev := res[i]
+      if prev.other != nil {
+        break
+      }
+
+      if prev.selKey == r.selKey || prev.bodyKey == r.bodyKey {
+        if m := mergeRule(prev, r); m != nil {
+          if backward {
+            res[i] = m
+            merged = true
+            break
+          }
+
+          forward := true // the candidate can be moved to the end
+          for _, between := range res[i+1:] {
+            if !prev.canMovePast(between) {
+              forward = false
+              break
+            }
+          }
+
+          if forward {
+            res = append(res[0:i], res[i+1:]...)
+            res = append(res, m)
+            merged = true
+            break
+          }
+        }
+      }
+
+      if backward && !r.canMovePast(prev) {
+        backward = false
+      }
+    }
+
+    if !merged {
+      res = append(res, r)
+    }
+  }
+
+  return res
+}
+
+func minifyRules(rules []Rule) ([]Rule, error) {
+  rs := make([]*minRule, 0)
+
+  for _, r_ := range rules {
+    switch r := r_.(type) {
+    case *RuleData:
+      m, err := declValues(r.attr)
+      if err != nil {
+        return nil, err
+      }
+
+      decls := make(map[string]string)
+      for name, value := range m {
+        decls[name] = minifyValue(name, value)
+      }
+
+      collapseBoxes(decls)
+
+      rs = append(rs, newMinRule(r.sel, decls, r.attr.Context()))
+    case *AtRule:
+      inner, err := minifyRules(r.rules)
+      if err != nil {
+        return nil, err
+      }
+
+      rs = append(rs, &minRule{other: NewAtRule(r.sel, inner)})
+    default:
+      rs = append(rs, &minRule{other: r})
+    }
+  }
+
+  dropOverridden(rs)
+
+  nonEmpty := make([]*minRule, 0)
+  for _, r := range rs {
+    if r.other != nil || len(r.decls) > 0 {
+      if r.other == nil {
+        r.update()
+      }
+
+      nonEmpty = append(nonEmpty, r)
+    }
+  }
+
+  res := make([]Rule, 0)
+  for _, r := range mergeRules(nonEmpty) {
+    res = append(res, r.toRule())
+  }
+
+  return res, nil
+}
+
+// merges rules, collapses box longhands into shorthands, drops overridden declarations, and shortens colors and numbers
+func (s *SheetData) Minify() (Sheet, error) {
+  rules, err := minifyRules(s.rules)
+  if err != nil {
+    return nil, err
+  }
+
+  return &SheetData{rules}, nil
+}
@@ -0,0 +1,167 @@
+package styles
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/tree"
+)
+
+// dynamic pseudo classes depend on user interaction (:hover, :focus, ...), and pseudo elements on generated content, so
+// they are ignored when looking for a matching element
+func (s *SelectorData) withoutDynamicPseudo() *SelectorData {
+  cpy := s.Copy()
+  cpy.pseudoClasses = make([]PseudoClass, 0)
+  for _, p := range s.pseudoClasses {
+    if p.IsStatic() {
+      cpy.pseudoClasses = append(cpy.pseudoClasses, p)
+    }
+  }
+  cpy.pseudoElement = ""
+
+  if cpy.descendant != nil {
+    cpy.descendant = cpy.descendant.withoutDynamicPseudo()
+  }
+
+  if cpy.sibling != nil {
+    cpy.sibling = cpy.sibling.withoutDynamicPseudo()
+  }
+
+  return cpy
+}
+
+// all the classes mentioned in the chain of descendants and siblings, including those in the arguments of pseudo
+// classes (eg. :not(.open))
+func (s *SelectorData) allClasses() []string {
+  res := make([]string, 0)
+
+  res = append(res, s.classes...)
+
+  for _, p := range s.pseudoClasses {
+    for _, sel := range p.Selectors() {
+      res = append(res, sel.allClasses()...)
+    }
+  }
+
+  if s.descendant != nil {
+    res = append(res, s.descendant.allClasses()...)
+  }
+
+  if s.sibling != nil {
+    res = append(res, s.sibling.allClasses()...)
+  }
+
+  return res
+}
+
+// tags matched by the selector, see withoutDynamicPseudo
+func (s *SelectorData) matchAll(htmlTags []tree.Tag) []tree.Tag {
+  res := make([]tree.Tag, 0)
+
+  sel := s.withoutDynamicPseudo()
+  for _, htmlTag := range htmlTags {
+    res = append(res, sel.Match(htmlTag)...)
+  }
+
+  return res
+}
+
+// returns nil if none of the selectors of the rule is kept
+func filterRule(r_ Rule, keep func(sel *SelectorData) bool) Rule {
+  switch r := r_.(type) {
+  case *RuleData:
+    if sel, ok := r.sel.(*SelectorData); ok && !keep(sel) {
+      return nil
+    }
+
+    return r
+  case *AtRule:
+    rules := make([]Rule, 0)
+    for _, inner := range r.rules {
+      if kept := filterRule(inner, keep); kept != nil {
+        rules = append(rules, kept)
+      }
+    }
+
+    if len(rules) == 0 {
+      return nil
+    }
+
+    return NewAtRule(r.sel, rules)
+  default:
+    // keyframes, @page, wrap rules
+    return r
+  }
+}
+
+func (s *SheetData) filter(keep func(sel *SelectorData) bool) Sheet {
+  rules := make([]Rule, 0)
+  for _, r := range s.rules {
+    if kept := filterRule(r, keep); kept != nil {
+      rules = append(rules, kept)
+    }
+  }
+
+  return &SheetData{rules}
+}
+
+func htmlTags(roots []*tree.Root) ([]tree.Tag, error) {
+  res := make([]tree.Tag, 0)
+  for _, root := range roots {
+    _, htmlTag, err := root.GetDocTypeAndHTML()
+    if err != nil {
+      return nil, err
+    }
+
+    res = append(res, htmlTag)
+  }
+
+  return res, nil
+}
+
+// drops the rules whose selectors don't match any element of the roots (the sheet must already have been applied to the
+// roots, see ApplyExtensions)
+// rules that mention a class for which keepClass returns true are always kept (eg. classes added by scripts)
+func (s *SheetData) Purge(roots []*tree.Root, keepClass func(class string) bool) (Sheet, error) {
+  tags, err := htmlTags(roots)
+  if err != nil {
+    return nil, err
+  }
+
+  return s.filter(func(sel *SelectorData) bool {
+    for _, class := range sel.allClasses() {
+      if keepClass(class) {
+        return true
+      }
+    }
+
+    return len(sel.matchAll(tags)) > 0
+  }), nil
+}
+
+// the rules that match at least one critical tag of the root, keyframes and @page rules are kept
+// imports and font faces are left to the complete sheet
+func (s *SheetData) Critical(root *tree.Root, isCritical func(t tree.Tag) bool) (Sheet, error) {
+  tags, err := htmlTags([]*tree.Root{root})
+  if err != nil {
+    return nil, err
+  }
+
+  critical := s.filter(func(sel *SelectorData) bool {
+    for _, t := range sel.matchAll(tags) {
+      if isCritical(t) {
+        return true
+      }
+    }
+
+    return false
+  }).(*SheetData)
+
+  rules := make([]Rule, 0)
+  for _, r := range critical.rules {
+    switch r.(type) {
+    case *ImportRule, *FontFaceRule:
+    default:
+      rules = append(rules, r)
+    }
+  }
+
+  return &SheetData{rules}, nil
+}
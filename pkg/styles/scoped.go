@@ -0,0 +1,70 @@
+package styles
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/directives"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tokens/patterns"
+)
+
+// the subject of every selector gets the attribute, eg. ".title .icon" becomes ".title .icon[data-s0]"
+// wraps would apply to the whole page, so they aren't allowed
+func scopeRules(rules []Rule, attrName string) ([]Rule, error) {
+  res := make([]Rule, 0)
+
+  for _, r_ := range rules {
+    switch r := r_.(type) {
+    case *RuleData:
+      if sel, ok := r.sel.(*SelectorData); ok {
+        res = append(res, NewRule(sel.addFilters([]AttrFilter{NewPlainAttrFilter(attrName)}), r.attr))
+      } else {
+        res = append(res, r)
+      }
+    case *AtRule:
+      inner, err := scopeRules(r.rules, attrName)
+      if err != nil {
+        return nil, err
+      }
+
+      res = append(res, NewAtRule(r.sel, inner))
+    case Wrap:
+      errCtx := r_.Context()
+      return nil, errCtx.NewError("Error: @wrap not allowed in scoped style")
+    default:
+      // keyframes stay global
+      res = append(res, r)
+    }
+  }
+
+  return res, nil
+}
+
+func (s *SheetData) scope(attrName string) (Sheet, error) {
+  rules, err := scopeRules(s.rules, attrName)
+  if err != nil {
+    return nil, err
+  }
+
+  return &SheetData{rules}, nil
+}
+
+// the sheet is registered by the caller, once for all the instances of the template
+func BuildDictWriteScopedSheet(d *tokens.StringDict, attrName string) (directives.StyleSheet, string, error) {
+  sheet, err := BuildDict(d)
+  if err != nil {
+    return nil, "", err
+  }
+
+  sheet, err = sheet.(*SheetData).scope(attrName)
+  if err != nil {
+    return nil, "", err
+  }
+
+  css, err := sheet.Write(true, patterns.NL, patterns.TAB)
+  if err != nil {
+    return nil, "", err
+  }
+
+  return sheet, css, nil
+}
+
+var _buildDictWriteScopedSheetRegistered = directives.RegisterBuildScopedStyle(BuildDictWriteScopedSheet)
@@ -4,6 +4,7 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 )
 
 type Assign struct {
@@ -70,6 +71,10 @@ func (t *Assign) EvalStatement() error {
       rhsExpr = NewAddOp(t.lhs, t.rhs, t.Context())
     case "-":
       rhsExpr = NewSubOp(t.lhs, t.rhs, t.Context())
+    case "*":
+      rhsExpr = NewMulOp(t.lhs, t.rhs, t.Context())
+    case "/":
+      rhsExpr = NewDivOp(t.lhs, t.rhs, t.Context())
     default:
       errCtx := t.Context()
       return errCtx.NewError("Error: unrecognized assign op " + t.op)
@@ -114,3 +119,53 @@ func (t *Assign) ResolveStatementActivity(usage Usage) error {
 func (t *Assign) UniqueStatementNames(ns Namespace) error {
   return nil
 }
+
+func (t *Assign) InterpretStatement(stack *Stack) error {
+  rhs, err := t.rhs.InterpretExpression(stack)
+  if err != nil {
+    return err
+  }
+
+  if t.op != "" {
+    lhs, err := t.lhs.InterpretExpression(stack)
+    if err != nil {
+      return err
+    }
+
+    switch t.op {
+    case "+":
+      rhs, err = data.Add(lhs, rhs, t.Context())
+    case "-":
+      rhs, err = data.Sub(lhs, rhs, t.Context())
+    case "*":
+      rhs, err = data.Mul(lhs, rhs, t.Context())
+    case "/":
+      rhs, err = data.Div(lhs, rhs, t.Context())
+    default:
+      errCtx := t.Context()
+      return errCtx.NewError("Error: unrecognized assign op " + t.op)
+    }
+
+    if err != nil {
+      return err
+    }
+  }
+
+  return interpretAssign(stack, t.lhs, rhs, t.Context())
+}
+
+func interpretAssign(stack *Stack, lhs Expression, rhs data.Data, ctx context.Context) error {
+  switch lhsExpr := lhs.(type) {
+  case *VarExpression:
+    return lhsExpr.InterpretSet(stack, rhs, ctx)
+  case *Member:
+    return lhsExpr.InterpretSet(stack, rhs, ctx)
+  case *Index:
+    return lhsExpr.InterpretSet(stack, rhs, ctx)
+  case *Parens:
+    return interpretAssign(stack, lhsExpr.expr, rhs, ctx)
+  default:
+    errCtx := lhs.Context()
+    return errCtx.NewError("Error: not assignable")
+  }
+}
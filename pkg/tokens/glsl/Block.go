@@ -106,3 +106,17 @@ func (t *Block) UniqueStatementNames(ns Namespace) error {
 
   return nil
 }
+
+func (t *Block) InterpretStatements(stack *Stack) error {
+  for _, st := range t.statements {
+    if err := st.InterpretStatement(stack); err != nil {
+      return err
+    }
+
+    if stack.IsReturning() {
+      return nil
+    }
+  }
+
+  return nil
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -166,3 +167,70 @@ func (t *Call) ResolveStatementActivity(usage Usage) error {
 func (t *Call) UniqueStatementNames(ns Namespace) error {
   return nil
 }
+
+func (t *Call) getCalleeVariable() (Variable, error) {
+  switch lhs := t.lhs.(type) {
+  case *VarExpression:
+    return lhs.GetVariable(), nil
+  case *Member:
+    v, err := lhs.GetPackageMember()
+    if err != nil {
+      return nil, err
+    } else if v != nil {
+      return v, nil
+    }
+  }
+
+  errCtx := t.lhs.Context()
+  return nil, errCtx.NewError("Error: can't interpret call of this expression")
+}
+
+func (t *Call) interpret(stack *Stack) (data.Data, error) {
+  fnVar, err := t.getCalleeVariable()
+  if err != nil {
+    return nil, err
+  }
+
+  args := make([]data.Data, len(t.args))
+  for i, arg := range t.args {
+    argData, err := arg.InterpretExpression(stack)
+    if err != nil {
+      return nil, err
+    }
+
+    args[i] = argData.Copy()
+  }
+
+  switch obj := fnVar.GetObject().(type) {
+  case *Function:
+    retVal, err := obj.InterpretCall(stack, args, t.Context())
+    if err != nil {
+      return nil, err
+    }
+
+    // copy back the out arguments
+    for i, fa := range obj.fi.args {
+      if fa.role & OUT_ROLE > 0 {
+        if err := interpretAssign(stack, t.args[i], args[i], t.args[i].Context()); err != nil {
+          return nil, err
+        }
+      }
+    }
+
+    return retVal, nil
+  case *Struct:
+    return obj.InterpretConstruction(args, t.Context())
+  default:
+    return data.CallBuiltin(fnVar.Name(), args, t.Context())
+  }
+}
+
+func (t *Call) InterpretExpression(stack *Stack) (data.Data, error) {
+  return t.interpret(stack)
+}
+
+func (t *Call) InterpretStatement(stack *Stack) error {
+  _, err := t.interpret(stack)
+
+  return err
+}
@@ -110,3 +110,19 @@ func (t *Const) UniqueStatementNames(ns Namespace) error {
     return nil
   }
 }
+
+// consts injected from js can't be interpreted, so the default rhs is used instead
+func (t *Const) InterpretStatement(stack *Stack) error {
+  if t.rhsExpr == nil {
+    return nil
+  }
+
+  rhs, err := t.rhsExpr.InterpretExpression(stack)
+  if err != nil {
+    return err
+  }
+
+  stack.Declare(t.GetVariable(), rhs)
+
+  return nil
+}
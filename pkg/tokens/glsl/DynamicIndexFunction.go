@@ -6,6 +6,7 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
 type DynamicIndexFunction struct {
@@ -49,3 +50,24 @@ func (t *DynamicIndexFunction) writeTree(i string, indent string, nl string, tab
 
   return t.writeTreeRecursively(i, indent, 0, t.length, nl, tab, fnLeaf)
 }
+
+// vec and mat containers are passed as a whole, instead of as an array
+func isVecOrMatContainer(containerTypeName string, length int) bool {
+  n := strconv.Itoa(length)
+
+  return strings.HasSuffix(containerTypeName, "vec" + n) || containerTypeName == "mat" + n
+}
+
+// struct names can change during UniqueStatementNames, so this must be called again upon writing
+func dynamicIndexContentTypeName(content values.Value) string {
+  if values.IsStruct(content) {
+    str, err := values.AssertStruct(content)
+    if err == nil {
+      if strSt, ok := str.GetStructable().(*Struct); ok {
+        return strSt.Name()
+      }
+    }
+  }
+
+  return content.TypeName()
+}
@@ -68,3 +68,7 @@ func (t *Export) ResolveStatementActivity(usage Usage) error {
 func (t *Export) UniqueStatementNames(ns Namespace) error {
   return nil
 }
+
+func (t *Export) InterpretStatement(stack *Stack) error {
+  return nil
+}
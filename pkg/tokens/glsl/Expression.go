@@ -2,6 +2,7 @@ package glsl
 
 import (
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 )
 
 type Expression interface {
@@ -14,4 +15,6 @@ type Expression interface {
   EvalExpression() (values.Value, error)
 
   ResolveExpressionActivity(usage Usage) error
+
+  InterpretExpression(stack *Stack) (data.Data, error)
 }
@@ -4,6 +4,7 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -147,3 +148,37 @@ func (t *For) UniqueStatementNames(ns Namespace) error {
 
   return nil
 }
+
+func (t *For) InterpretStatement(stack *Stack) error {
+  if err := t.init.InterpretStatement(stack); err != nil {
+    return err
+  }
+
+  for {
+    compData, err := t.comp.InterpretExpression(stack)
+    if err != nil {
+      return err
+    }
+
+    comp, err := data.AssertScalar(compData, "bool", t.comp.Context())
+    if err != nil {
+      return err
+    }
+
+    if !comp.Bool() {
+      return nil
+    }
+
+    if err := t.Block.InterpretStatements(stack); err != nil {
+      return err
+    }
+
+    if stack.IsReturning() {
+      return nil
+    }
+
+    if err := t.incr.InterpretStatement(stack); err != nil {
+      return err
+    }
+  }
+}
@@ -1,9 +1,11 @@
 package glsl
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -94,6 +96,7 @@ func (t *Function) ResolveStatementNames(outer Scope) error {
 
   variable := t.GetVariable()
   variable.SetConstant()
+  variable.SetObject(t)
 
   if err := outer.SetVariable(t.Name(), variable); err != nil {
     return err
@@ -205,3 +208,61 @@ func (t *Function) UniqueStatementNames(ns Namespace) error {
 
   return t.Block.UniqueStatementNames(subNs)
 }
+
+// declaration only
+func (t *Function) InterpretStatement(stack *Stack) error {
+  return nil
+}
+
+// args are overwritten by the final values of the arguments, so the caller can copy back out arguments
+func (t *Function) InterpretCall(stack *Stack, args []data.Data, ctx context.Context) (data.Data, error) {
+  if len(args) != len(t.fi.args) {
+    return nil, ctx.NewError("Error: expected " + strconv.Itoa(len(t.fi.args)) + " args, got " + strconv.Itoa(len(args)) + " args")
+  }
+
+  stack.pushFrame()
+
+  for i, fa := range t.fi.args {
+    stack.Declare(fa.nameExpr.GetVariable(), args[i])
+  }
+
+  if err := t.Block.InterpretStatements(stack); err != nil {
+    stack.popFrame()
+    return nil, err
+  }
+
+  retVal := stack.popReturn()
+
+  for i, fa := range t.fi.args {
+    var err error
+    args[i], err = stack.Get(fa.nameExpr.GetVariable(), ctx)
+    if err != nil {
+      stack.popFrame()
+      return nil, err
+    }
+  }
+
+  stack.popFrame()
+
+  if t.fi.retType != nil && retVal == nil {
+    errCtx := t.Context()
+    return nil, errCtx.NewError("Error: function didn't return a value")
+  }
+
+  return retVal, nil
+}
+
+// values of the arguments as resolved, so includes array lengths
+func (t *Function) ArgTypes() []values.Value {
+  res := make([]values.Value, len(t.fi.args))
+  for i, fa := range t.fi.args {
+    res[i] = fa.nameExpr.GetVariable().GetValue()
+  }
+
+  return res
+}
+
+// nil for void
+func (t *Function) ReturnType() (values.Value, error) {
+  return t.fi.EvalCall(nil, t.Context())
+}
@@ -15,6 +15,10 @@ const (
 func RoleToString(role FunctionArgumentRole) string {
   var b strings.Builder
 
+  if role & IN_ROLE > 0 && role & OUT_ROLE > 0 {
+    return "inout "
+  }
+
   if role & IN_ROLE > 0 {
     b.WriteString("in ")
   }
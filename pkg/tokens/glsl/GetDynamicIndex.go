@@ -4,6 +4,7 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -126,13 +127,15 @@ func (t *GetDynamicIndex) ResolveExpressionActivity(usage Usage) error {
     return err
   }
 
-  contentTypeName := contentVal.TypeName()
-
   length := containerValue.Length()
 
-  fn := NewGetDynamicIndexFunction(containerValue.TypeName(), contentTypeName, length, t.Context())
+  fn := NewGetDynamicIndexFunction(containerValue.TypeName(), contentVal, length, t.Context())
 
   t.fnVar, err = injectDynamicIndexStatement(usage, fn.GetVariable(), fn, contentVal)
 
   return err
 }
+
+func (t *GetDynamicIndex) InterpretExpression(stack *Stack) (data.Data, error) {
+  return t.Index.InterpretExpression(stack)
+}
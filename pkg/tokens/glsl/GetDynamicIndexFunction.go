@@ -5,25 +5,28 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
 type GetDynamicIndexFunction struct {
-  vecType string // empty if just regular array
-  typeName string
+  vecType string // vec or mat container type, empty if just regular array
+  content values.Value
   DynamicIndexFunction
 }
 
-func NewGetDynamicIndexFunction(containerTypeName string, typeName string, length int, ctx context.Context) *GetDynamicIndexFunction {
+func NewGetDynamicIndexFunction(containerTypeName string, content values.Value, length int, ctx context.Context) *GetDynamicIndexFunction {
+  typeName := dynamicIndexContentTypeName(content)
+
   name := "getIndex_" + typeName + "_" + strconv.Itoa(length)
 
   vecType := ""
 
-  if strings.HasSuffix(containerTypeName, "vec" + strconv.Itoa(length)) {
+  if isVecOrMatContainer(containerTypeName, length) {
     vecType = containerTypeName
     name = "getIndex_" + containerTypeName + "_" + typeName
   }
 
-  return &GetDynamicIndexFunction{vecType, typeName, newDynamicIndexFunction(name, length, ctx)}
+  return &GetDynamicIndexFunction{vecType, content, newDynamicIndexFunction(name, length, ctx)}
 }
 
 func (t *GetDynamicIndexFunction) Dump(indent string) string {
@@ -31,10 +34,12 @@ func (t *GetDynamicIndexFunction) Dump(indent string) string {
 }
 
 func (t *GetDynamicIndexFunction) WriteStatement(usage Usage, indent string, nl string, tab string) string {
+  typeName := dynamicIndexContentTypeName(t.content)
+
   var b strings.Builder
 
   b.WriteString(indent)
-  b.WriteString(t.typeName)
+  b.WriteString(typeName)
   b.WriteString(" ")
   b.WriteString(t.Name())
 
@@ -44,7 +49,7 @@ func (t *GetDynamicIndexFunction) WriteStatement(usage Usage, indent string, nl
     b.WriteString(" ")
     b.WriteString("x")
   } else {
-    b.WriteString(t.typeName)
+    b.WriteString(typeName)
     b.WriteString(" ")
     b.WriteString("x[")
     b.WriteString(strconv.Itoa(t.length))
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -175,3 +176,27 @@ func (t *If) UniqueStatementNames(ns Namespace) error {
 
 	return nil
 }
+
+func (t *If) InterpretStatement(stack *Stack) error {
+  for i, cond := range t.conds {
+    if cond != nil {
+      condData, err := cond.InterpretExpression(stack)
+      if err != nil {
+        return err
+      }
+
+      c, err := data.AssertScalar(condData, "bool", cond.Context())
+      if err != nil {
+        return err
+      }
+
+      if !c.Bool() {
+        continue
+      }
+    }
+
+    return t.groups[i].InterpretStatements(stack)
+  }
+
+  return nil
+}
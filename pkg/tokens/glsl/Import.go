@@ -149,3 +149,6 @@ func (t *Import) UniqueStatementNames(ns Namespace) error {
   return nil
 }
 
+func (t *Import) InterpretStatement(stack *Stack) error {
+  return nil
+}
@@ -4,6 +4,7 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -107,3 +108,68 @@ func (t *Index) ResolveExpressionActivity(usage Usage) error {
 
   return nil
 }
+
+func (t *Index) interpretArgs(stack *Stack) (data.Data, int, error) {
+  container, err := t.container.InterpretExpression(stack)
+  if err != nil {
+    return nil, 0, err
+  }
+
+  indexData, err := t.index.InterpretExpression(stack)
+  if err != nil {
+    return nil, 0, err
+  }
+
+  index, err := data.AssertScalar(indexData, "int", t.index.Context())
+  if err != nil {
+    return nil, 0, err
+  }
+
+  return container, index.Int(), nil
+}
+
+func (t *Index) InterpretExpression(stack *Stack) (data.Data, error) {
+  container, index, err := t.interpretArgs(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  switch d := container.(type) {
+  case *data.Array:
+    return d.GetIndex(index, t.Context())
+  case *data.Simple:
+    return d.GetIndex(index, t.Context())
+  case *data.Mat:
+    return d.GetIndex(index, t.Context())
+  default:
+    errCtx := t.Context()
+    return nil, errCtx.NewError("Error: " + container.TypeName() + " can't be indexed")
+  }
+}
+
+func (t *Index) InterpretSet(stack *Stack, rhs data.Data, ctx context.Context) error {
+  container, index, err := t.interpretArgs(stack)
+  if err != nil {
+    return err
+  }
+
+  switch d := container.(type) {
+  case *data.Array:
+    return d.SetIndex(index, rhs, t.Context())
+  case *data.Simple:
+    if err := d.SetIndex(index, rhs, t.Context()); err != nil {
+      return err
+    }
+
+    return interpretAssign(stack, t.container, d, ctx)
+  case *data.Mat:
+    if err := d.SetIndex(index, rhs, t.Context()); err != nil {
+      return err
+    }
+
+    return interpretAssign(stack, t.container, d, ctx)
+  default:
+    errCtx := t.Context()
+    return errCtx.NewError("Error: " + container.TypeName() + " can't be indexed")
+  }
+}
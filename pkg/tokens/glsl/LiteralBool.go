@@ -2,6 +2,7 @@ package glsl
 
 import (
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -39,3 +40,7 @@ func IsLiteralBool(t Expression) bool {
 	_, ok := t.(*LiteralBool)
 	return ok
 }
+
+func (t *LiteralBool) InterpretExpression(stack *Stack) (data.Data, error) {
+  return data.NewBool(t.value), nil
+}
@@ -5,6 +5,7 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -42,3 +43,7 @@ func IsLiteralFloat(t Expression) bool {
 	_, ok := t.(*LiteralFloat)
 	return ok
 }
+
+func (t *LiteralFloat) InterpretExpression(stack *Stack) (data.Data, error) {
+  return data.NewFloat(t.value), nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -36,3 +37,7 @@ func IsLiteralInt(t Expression) bool {
 	_, ok := t.(*LiteralInt)
 	return ok
 }
+
+func (t *LiteralInt) InterpretExpression(stack *Stack) (data.Data, error) {
+  return data.NewInt(t.value), nil
+}
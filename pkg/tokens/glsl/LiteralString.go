@@ -2,6 +2,7 @@ package glsl
 
 import (
   "github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
   "github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -21,3 +22,8 @@ func (t *LiteralString) EvalExpression() (values.Value, error) {
 func (t *LiteralString) Value() string {
 	return t.value
 }
+
+func (t *LiteralString) InterpretExpression(stack *Stack) (data.Data, error) {
+  errCtx := t.Context()
+  return nil, errCtx.NewError("Error: strings can't be interpreted")
+}
@@ -38,3 +38,7 @@ func (t *MacroFunction) UniqueStatementNames(ns Namespace) error {
 
   return nil
 }
+
+func (t *MacroFunction) InterpretStatement(stack *Stack) error {
+  panic("not available")
+}
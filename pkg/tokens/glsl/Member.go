@@ -4,6 +4,7 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -149,3 +150,49 @@ func (t *Member) ResolveExpressionActivity(usage Usage) error {
 
 	return nil
 }
+
+func (t *Member) InterpretExpression(stack *Stack) (data.Data, error) {
+  pkgMember, err := t.GetPackageMember()
+  if err != nil {
+    return nil, err
+  } else if pkgMember != nil {
+    return stack.Get(pkgMember, t.Context())
+  }
+
+  obj, err := t.object.InterpretExpression(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  switch d := obj.(type) {
+  case *data.Struct:
+    return d.GetMember(t.key.Value(), t.key.Context())
+  case *data.Simple:
+    return d.GetSwizzle(t.key.Value(), t.key.Context())
+  default:
+    errCtx := t.key.Context()
+    return nil, errCtx.NewError("Error: " + obj.TypeName() + " doesn't have members")
+  }
+}
+
+func (t *Member) InterpretSet(stack *Stack, rhs data.Data, ctx context.Context) error {
+  obj, err := t.object.InterpretExpression(stack)
+  if err != nil {
+    return err
+  }
+
+  switch d := obj.(type) {
+  case *data.Struct:
+    return d.SetMember(t.key.Value(), rhs, t.key.Context())
+  case *data.Simple:
+    if err := d.SetSwizzle(t.key.Value(), rhs, t.key.Context()); err != nil {
+      return err
+    }
+
+    // swizzles of swizzles are temporary, so must be written back
+    return interpretAssign(stack, t.object, d, ctx)
+  default:
+    errCtx := t.key.Context()
+    return errCtx.NewError("Error: " + obj.TypeName() + " doesn't have members")
+  }
+}
@@ -197,7 +197,7 @@ func (m *ModuleData) CollectVersion(version *Word) (*Word, error) {
   return version, nil
 }
 
-func (m *ModuleData) CollectVaryings(varyings map[string]string) error {
+func (m *ModuleData) CollectVaryings(varyings map[string]*Varying) error {
   for _, st_ := range m.statements {
     if st, ok := st_.(*Varying); ok {
       if err := st.Collect(varyings); err != nil {
@@ -209,6 +209,18 @@ func (m *ModuleData) CollectVaryings(varyings map[string]string) error {
   return nil
 }
 
+func (m *ModuleData) CollectUniforms(uniforms map[string]*Uniform) error {
+  for _, st_ := range m.statements {
+    if st, ok := st_.(*Uniform); ok {
+      if err := st.Collect(uniforms); err != nil {
+        return err
+      }
+    }
+  }
+
+  return nil
+}
+
 func (m *ModuleData) FindExportedConst(name string) *Const {
   if exported, ok := m.exported[name]; ok {
     variable := exported.v
@@ -222,3 +234,28 @@ func (m *ModuleData) FindExportedConst(name string) *Const {
 
   return nil
 }
+
+func (m *ModuleData) FindExportedFunction(name string) *Function {
+  if exported, ok := m.exported[name]; ok {
+    variable := exported.v
+
+    if obj_ := variable.GetObject(); obj_ != nil {
+      if obj, ok := obj_.(*Function); ok {
+        return obj
+      }
+    }
+  }
+
+  return nil
+}
+
+// initializes the module level consts and variables
+func (m *ModuleData) InterpretGlobals(stack *Stack) error {
+  for _, st := range m.statements {
+    if err := st.InterpretStatement(stack); err != nil {
+      return err
+    }
+  }
+
+  return nil
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -48,3 +49,7 @@ func (t *Parens) EvalExpression() (values.Value, error) {
 func (t *Parens) ResolveExpressionActivity(usage Usage) error {
   return t.expr.ResolveExpressionActivity(usage)
 }
+
+func (t *Parens) InterpretExpression(stack *Stack) (data.Data, error) {
+  return t.expr.InterpretExpression(stack)
+}
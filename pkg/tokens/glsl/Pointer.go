@@ -63,3 +63,8 @@ func (t *Pointer) ResolveStatementActivity(usage Usage) error {
 func (t *Pointer) UniqueStatementNames(ns Namespace) error {
   return ns.OrigName(t.GetVariable())
 }
+
+// uniforms, varyings and attributes are zero until set by the caller of the interpreter
+func (t *Pointer) InterpretStatement(stack *Stack) error {
+  return nil
+}
@@ -57,3 +57,7 @@ func (t *Precision) ResolveStatementActivity(usage Usage) error {
 func (t *Precision) UniqueStatementNames(ns Namespace) error {
   return nil
 }
+
+func (t *Precision) InterpretStatement(stack *Stack) error {
+  return nil
+}
@@ -99,3 +99,19 @@ func (t *Return) ResolveStatementActivity(usage Usage) error {
 func (t *Return) UniqueStatementNames(ns Namespace) error {
   return nil
 }
+
+func (t *Return) InterpretStatement(stack *Stack) error {
+  if t.expr == nil {
+    stack.setReturn(nil)
+    return nil
+  }
+
+  d, err := t.expr.InterpretExpression(stack)
+  if err != nil {
+    return err
+  }
+
+  stack.setReturn(d.Copy())
+
+  return nil
+}
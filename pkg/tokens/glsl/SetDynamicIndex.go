@@ -108,11 +108,9 @@ func (t *SetDynamicIndex) ResolveStatementActivity(usage Usage) error {
     return err
   }
 
-  contentTypeName := contentVal.TypeName()
-
   length := containerValue.Length()
 
-  fn := NewSetDynamicIndexFunction(containerValue.TypeName(), contentTypeName, length, t.Context())
+  fn := NewSetDynamicIndexFunction(containerValue.TypeName(), contentVal, length, t.Context())
 
   t.fnVar, err = injectDynamicIndexStatement(usage, fn.GetVariable(), fn, contentVal)
   return err
@@ -121,3 +119,12 @@ func (t *SetDynamicIndex) ResolveStatementActivity(usage Usage) error {
 func (t *SetDynamicIndex) UniqueStatementNames(ns Namespace) error {
   return nil
 }
+
+func (t *SetDynamicIndex) InterpretStatement(stack *Stack) error {
+  arg, err := t.arg.InterpretExpression(stack)
+  if err != nil {
+    return err
+  }
+
+  return t.Index.InterpretSet(stack, arg, t.Context())
+}
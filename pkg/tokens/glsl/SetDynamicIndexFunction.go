@@ -5,25 +5,28 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
 type SetDynamicIndexFunction struct {
-  vecType string // empty if just regular array
-  typeName string
+  vecType string // vec or mat container type, empty if just regular array
+  content values.Value
   DynamicIndexFunction
 }
 
-func NewSetDynamicIndexFunction(containerTypeName string, typeName string, length int, ctx context.Context) *SetDynamicIndexFunction {
+func NewSetDynamicIndexFunction(containerTypeName string, content values.Value, length int, ctx context.Context) *SetDynamicIndexFunction {
+  typeName := dynamicIndexContentTypeName(content)
+
   name := "setIndex_" + typeName + "_" + strconv.Itoa(length)
 
   vecType := ""
 
-  if strings.HasSuffix(containerTypeName, "vec" + strconv.Itoa(length)) {
+  if isVecOrMatContainer(containerTypeName, length) {
     vecType = containerTypeName
     name = "setIndex_" + containerTypeName + "_" + typeName
   }
 
-  return &SetDynamicIndexFunction{vecType, typeName, newDynamicIndexFunction(name, length, ctx)}
+  return &SetDynamicIndexFunction{vecType, content, newDynamicIndexFunction(name, length, ctx)}
 }
 
 func (t *SetDynamicIndexFunction) Dump(indent string) string {
@@ -31,6 +34,8 @@ func (t *SetDynamicIndexFunction) Dump(indent string) string {
 }
 
 func (t *SetDynamicIndexFunction) WriteStatement(usage Usage, indent string, nl string, tab string) string {
+  typeName := dynamicIndexContentTypeName(t.content)
+
   var b strings.Builder
 
   b.WriteString(indent)
@@ -43,7 +48,7 @@ func (t *SetDynamicIndexFunction) WriteStatement(usage Usage, indent string, nl
     b.WriteString(" ")
     b.WriteString("x")
   } else {
-    b.WriteString(t.typeName)
+    b.WriteString(typeName)
     b.WriteString(" ")
     b.WriteString("x[")
     b.WriteString(strconv.Itoa(t.length))
@@ -51,7 +56,7 @@ func (t *SetDynamicIndexFunction) WriteStatement(usage Usage, indent string, nl
   }
 
   b.WriteString(",in int i,in ")
-  b.WriteString(t.typeName)
+  b.WriteString(typeName)
   b.WriteString(" a){")
   b.WriteString(nl)
   b.WriteString(t.DynamicIndexFunction.writeTree("i", indent + tab, nl, tab, func(i int) string {
@@ -0,0 +1,116 @@
+package glsl
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
+)
+
+// runtime state of the cpu interpreter
+// variables are resolved statically, so they can be used directly as keys
+type Stack struct {
+  globals map[Variable]data.Data
+  frames []map[Variable]data.Data // one per function call
+
+  retVal data.Data
+  returning bool
+}
+
+func NewStack() *Stack {
+  return &Stack{make(map[Variable]data.Data), make([]map[Variable]data.Data, 0), nil, false}
+}
+
+func (s *Stack) top() map[Variable]data.Data {
+  if len(s.frames) == 0 {
+    return s.globals
+  } else {
+    return s.frames[len(s.frames)-1]
+  }
+}
+
+func (s *Stack) pushFrame() {
+  s.frames = append(s.frames, make(map[Variable]data.Data))
+}
+
+func (s *Stack) popFrame() {
+  s.frames = s.frames[0:len(s.frames)-1]
+}
+
+// returns a reference, so members, items and components can be modified in place
+func (s *Stack) Get(v Variable, ctx context.Context) (data.Data, error) {
+  if d, ok := s.top()[v]; ok {
+    return d, nil
+  } else if d, ok := s.globals[v]; ok {
+    return d, nil
+  }
+
+  // uniforms, varyings, attributes and builtin variables start as zero
+  d, err := NewZeroData(v.GetValue(), ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  s.globals[v] = d
+
+  return d, nil
+}
+
+func (s *Stack) Set(v Variable, d data.Data) {
+  if _, ok := s.top()[v]; ok {
+    s.top()[v] = d.Copy()
+  } else if _, ok := s.globals[v]; ok {
+    s.globals[v] = d.Copy()
+  } else {
+    s.top()[v] = d.Copy()
+  }
+}
+
+// always in the top frame, eg. for function arguments and local variables
+func (s *Stack) Declare(v Variable, d data.Data) {
+  s.top()[v] = d.Copy()
+}
+
+func (s *Stack) setReturn(d data.Data) {
+  s.retVal = d
+  s.returning = true
+}
+
+func (s *Stack) popReturn() data.Data {
+  d := s.retVal
+
+  s.retVal = nil
+  s.returning = false
+
+  return d
+}
+
+func (s *Stack) IsReturning() bool {
+  return s.returning
+}
+
+func NewZeroData(val values.Value, ctx context.Context) (data.Data, error) {
+  val = values.UnpackContextValue(val)
+
+  switch v := val.(type) {
+  case *values.Array:
+    content := make([]data.Data, v.Length())
+    for i, _ := range content {
+      var err error
+      content[i], err = NewZeroData(v.Content(), ctx)
+      if err != nil {
+        return nil, err
+      }
+    }
+
+    return data.NewArray(content), nil
+  case *values.Struct:
+    st, ok := v.GetStructable().(*Struct)
+    if !ok {
+      panic("expected only glsl.Struct")
+    }
+
+    return st.newZeroData(ctx)
+  default:
+    return data.NewZero(val.TypeName(), ctx)
+  }
+}
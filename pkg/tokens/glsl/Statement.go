@@ -12,4 +12,6 @@ type Statement interface {
   ResolveStatementActivity(usage Usage) error
 
   UniqueStatementNames(ns Namespace) error
+
+  InterpretStatement(stack *Stack) error
 }
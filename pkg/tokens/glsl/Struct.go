@@ -5,6 +5,7 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -159,7 +160,10 @@ func (t *Struct) ResolveStatementNames(scope Scope) error {
     }
   }
 
+  // set the type immediately, so the struct can be used in function interfaces
   variable := t.GetVariable()
+  variable.SetObject(t)
+  variable.SetValue(values.NewStructType(t, t.Context()))
 
   if err := scope.SetVariable(t.Name(), variable); err != nil {
     return err
@@ -230,3 +234,46 @@ func (t *Struct) UniqueStatementNames(ns Namespace) error {
 
   return nil
 }
+
+func (t *Struct) InterpretStatement(stack *Stack) error {
+  return nil
+}
+
+func (t *Struct) entryNames() []string {
+  keys := make([]string, len(t.entries))
+  for i, entry := range t.entries {
+    keys[i] = entry.Name()
+  }
+
+  return keys
+}
+
+func (t *Struct) newZeroData(ctx context.Context) (data.Data, error) {
+  members := make([]data.Data, len(t.entries))
+  for i, entry := range t.entries {
+    val, err := entry.Instantiate(ctx)
+    if err != nil {
+      return nil, err
+    }
+
+    members[i], err = NewZeroData(val, ctx)
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  return data.NewStruct(t.Name(), t.entryNames(), members), nil
+}
+
+func (t *Struct) InterpretConstruction(args []data.Data, ctx context.Context) (data.Data, error) {
+  if len(args) != len(t.entries) {
+    return nil, ctx.NewError("Error: expected " + strconv.Itoa(len(t.entries)) + ", got " + strconv.Itoa(len(args)))
+  }
+
+  members := make([]data.Data, len(args))
+  for i, arg := range args {
+    members[i] = arg.Copy()
+  }
+
+  return data.NewStruct(t.Name(), t.entryNames(), members), nil
+}
@@ -4,6 +4,7 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -60,3 +61,8 @@ func (t *TypeExpression) Instantiate(ctx context.Context) (values.Value, error)
 
   return val, nil
 }
+
+func (t *TypeExpression) InterpretExpression(stack *Stack) (data.Data, error) {
+  errCtx := t.Context()
+  return nil, errCtx.NewError("Error: type can't be interpreted")
+}
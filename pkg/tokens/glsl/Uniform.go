@@ -74,3 +74,24 @@ func (t *Uniform) EvalStatement() error {
 
   return nil
 }
+
+func (t *Uniform) TypeName() string {
+  return t.typeExpr.WriteExpression()
+}
+
+func (t *Uniform) Length() int {
+  return t.length
+}
+
+func (t *Uniform) Collect(uniforms map[string]*Uniform) error {
+  if other, ok := uniforms[t.Name()]; ok && other != t {
+    errCtx := t.Context()
+    err := errCtx.NewError("Error: uniform " + t.Name() + " declared more than once")
+    err.AppendContextString("Info: also declared here", other.Context())
+    return err
+  }
+
+  uniforms[t.Name()] = t
+
+  return nil
+}
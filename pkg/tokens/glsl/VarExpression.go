@@ -4,6 +4,7 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -141,3 +142,13 @@ func (t *VarExpression) EvalSet(v values.Value, ctx context.Context) error {
 func (t *VarExpression) ResolveExpressionActivity(usage Usage) error {
   return usage.Use(t.GetVariable(), t.Context())
 }
+
+func (t *VarExpression) InterpretExpression(stack *Stack) (data.Data, error) {
+  return stack.Get(t.variable, t.Context())
+}
+
+func (t *VarExpression) InterpretSet(stack *Stack, rhs data.Data, ctx context.Context) error {
+  stack.Set(t.variable, rhs)
+
+  return nil
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -134,3 +135,24 @@ func (t *VarStatement) UniqueStatementNames(ns Namespace) error {
 
   return nil
 }
+
+func (t *VarStatement) InterpretStatement(stack *Stack) error {
+  var d data.Data = nil
+  if t.rhsExpr != nil {
+    var err error
+    d, err = t.rhsExpr.InterpretExpression(stack)
+    if err != nil {
+      return err
+    }
+  } else {
+    var err error
+    d, err = NewZeroData(t.nameExpr.GetVariable().GetValue(), t.Context())
+    if err != nil {
+      return err
+    }
+  }
+
+  stack.Declare(t.nameExpr.GetVariable(), d)
+
+  return nil
+}
@@ -58,9 +58,9 @@ func (t *Varying) EvalStatement() error {
 
   val := variable.GetValue()
 
-  if !values.IsSimple(val) {
+  if !values.IsSimple(val) && !values.IsMat(val) {
     errCtx := val.Context()
-    return errCtx.NewError("Error: expected simple type, got " +val.TypeName())
+    return errCtx.NewError("Error: expected simple or mat type, got " +val.TypeName())
   }
 
   return nil
@@ -75,9 +75,24 @@ func (t *Varying) ResolveStatementActivity(usage Usage) error {
   }
 }
 
-func (t *Varying) Collect(varyings map[string]string) error {
+func (t *Varying) TypeName() string {
+  return t.typeExpr.WriteExpression()
+}
+
+func (t *Varying) Precision() PrecisionType {
+  return t.precType
+}
+
+func (t *Varying) Collect(varyings map[string]*Varying) error {
   // expecting only simple types
-  varyings[t.Name()] = t.typeExpr.WriteExpression()
+  if other, ok := varyings[t.Name()]; ok && other != t {
+    errCtx := t.Context()
+    err := errCtx.NewError("Error: varying " + t.Name() + " declared more than once")
+    err.AppendContextString("Info: also declared here", other.Context())
+    return err
+  }
+
+  varyings[t.Name()] = t
 
   return nil
 }
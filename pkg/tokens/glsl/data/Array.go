@@ -0,0 +1,87 @@
+package data
+
+import (
+  "strconv"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+type Array struct {
+  content []Data
+}
+
+func NewArray(content []Data) *Array {
+  return &Array{content}
+}
+
+func (d *Array) Length() int {
+  return len(d.content)
+}
+
+func (d *Array) TypeName() string {
+  return d.content[0].TypeName() + "[" + strconv.Itoa(len(d.content)) + "]"
+}
+
+func (d *Array) Copy() Data {
+  content := make([]Data, len(d.content))
+  for i, item := range d.content {
+    content[i] = item.Copy()
+  }
+
+  return &Array{content}
+}
+
+func (d *Array) Write() string {
+  var b strings.Builder
+
+  b.WriteString("[")
+  for i, item := range d.content {
+    b.WriteString(item.Write())
+
+    if i < len(d.content) - 1 {
+      b.WriteString(",")
+    }
+  }
+  b.WriteString("]")
+
+  return b.String()
+}
+
+func (d *Array) ApproxEqual(other_ Data, tol float64) bool {
+  other, ok := other_.(*Array)
+  if !ok || len(other.content) != len(d.content) {
+    return false
+  }
+
+  for i, item := range d.content {
+    if !item.ApproxEqual(other.content[i], tol) {
+      return false
+    }
+  }
+
+  return true
+}
+
+// returns a reference, so the item can be modified in place
+func (d *Array) GetIndex(i int, ctx context.Context) (Data, error) {
+  if i < 0 || i >= len(d.content) {
+    return nil, ctx.NewError("Error: index " + strconv.Itoa(i) + " out of range")
+  }
+
+  return d.content[i], nil
+}
+
+func (d *Array) SetIndex(i int, arg Data, ctx context.Context) error {
+  if i < 0 || i >= len(d.content) {
+    return ctx.NewError("Error: index " + strconv.Itoa(i) + " out of range")
+  }
+
+  if arg.TypeName() != d.content[i].TypeName() {
+    return ctx.NewError("Error: can't assign " + arg.TypeName() + " to item of " + d.TypeName())
+  }
+
+  d.content[i] = arg.Copy()
+
+  return nil
+}
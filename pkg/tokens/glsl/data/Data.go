@@ -0,0 +1,31 @@
+package data
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+// runtime counterpart of values.Value, used by the cpu interpreter
+type Data interface {
+  TypeName() string
+  Copy() Data // glsl has value semantics, so data is copied upon assignment
+  Write() string // glsl literal syntax
+  ApproxEqual(other Data, tol float64) bool
+}
+
+func NewZero(typeName string, ctx context.Context) (Data, error) {
+  switch typeName {
+  case "float", "int", "bool":
+    return NewScalar(typeName, 0.0), nil
+  case "vec2", "vec3", "vec4":
+    return NewVec("float", make([]float64, int(typeName[3] - '0'))), nil
+  case "ivec2", "ivec3", "ivec4":
+    return NewVec("int", make([]float64, int(typeName[4] - '0'))), nil
+  case "bvec2", "bvec3", "bvec4":
+    return NewVec("bool", make([]float64, int(typeName[4] - '0'))), nil
+  case "mat2", "mat3", "mat4":
+    n := int(typeName[3] - '0')
+    return NewMat(n, make([]float64, n*n)), nil
+  default:
+    return nil, ctx.NewError("Error: can't interpret " + typeName)
+  }
+}
@@ -0,0 +1,131 @@
+package data
+
+import (
+  "math"
+  "strconv"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+// square float matrix, components are stored column by column
+type Mat struct {
+  n int
+  comps []float64
+}
+
+func NewMat(n int, comps []float64) *Mat {
+  if len(comps) != n*n {
+    panic("wrong number of components")
+  }
+
+  return &Mat{n, comps}
+}
+
+func NewIdentityMat(n int) *Mat {
+  comps := make([]float64, n*n)
+  for i := 0; i < n; i++ {
+    comps[i*n + i] = 1.0
+  }
+
+  return NewMat(n, comps)
+}
+
+// number of columns
+func (d *Mat) Length() int {
+  return d.n
+}
+
+func (d *Mat) Comps() []float64 {
+  return d.comps
+}
+
+// column j, row i
+func (d *Mat) Get(j int, i int) float64 {
+  return d.comps[j*d.n + i]
+}
+
+func (d *Mat) TypeName() string {
+  return "mat" + strconv.Itoa(d.n)
+}
+
+func (d *Mat) Copy() Data {
+  comps := make([]float64, len(d.comps))
+  copy(comps, d.comps)
+
+  return &Mat{d.n, comps}
+}
+
+func (d *Mat) Write() string {
+  var b strings.Builder
+
+  b.WriteString(d.TypeName())
+  b.WriteString("(")
+
+  for i, x := range d.comps {
+    b.WriteString(NewFloat(x).Write())
+
+    if i < len(d.comps) - 1 {
+      b.WriteString(",")
+    }
+  }
+
+  b.WriteString(")")
+
+  return b.String()
+}
+
+func (d *Mat) ApproxEqual(other_ Data, tol float64) bool {
+  other, ok := other_.(*Mat)
+  if !ok || other.n != d.n {
+    return false
+  }
+
+  for i, x := range d.comps {
+    if math.Abs(x - other.comps[i]) > tol {
+      return false
+    }
+  }
+
+  return true
+}
+
+// returns a copy of the column, so modifications must be written back with SetIndex
+func (d *Mat) GetIndex(j int, ctx context.Context) (Data, error) {
+  if j < 0 || j >= d.n {
+    return nil, ctx.NewError("Error: index " + strconv.Itoa(j) + " out of range")
+  }
+
+  comps := make([]float64, d.n)
+  copy(comps, d.comps[j*d.n:(j+1)*d.n])
+
+  return NewVec("float", comps), nil
+}
+
+func (d *Mat) SetIndex(j int, arg Data, ctx context.Context) error {
+  if j < 0 || j >= d.n {
+    return ctx.NewError("Error: index " + strconv.Itoa(j) + " out of range")
+  }
+
+  src, ok := arg.(*Simple)
+  if !ok || src.compType != "float" || len(src.comps) != d.n {
+    return ctx.NewError("Error: can't assign " + arg.TypeName() + " to column of " + d.TypeName())
+  }
+
+  copy(d.comps[j*d.n:(j+1)*d.n], src.comps)
+
+  return nil
+}
+
+// the overlapping part is copied, the rest is taken from the identity matrix
+func (d *Mat) resize(n int) *Mat {
+  res := NewIdentityMat(n)
+
+  for j := 0; j < n && j < d.n; j++ {
+    for i := 0; i < n && i < d.n; i++ {
+      res.comps[j*n + i] = d.Get(j, i)
+    }
+  }
+
+  return res
+}
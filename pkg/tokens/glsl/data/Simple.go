@@ -0,0 +1,271 @@
+package data
+
+import (
+  "math"
+  "strconv"
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+// scalars and vectors, components are always stored as float64
+// ints are kept truncated, bools are 0 or 1
+type Simple struct {
+  compType string // float, int or bool
+  comps []float64
+}
+
+func NewScalar(compType string, x float64) *Simple {
+  return NewVec(compType, []float64{x})
+}
+
+func NewFloat(x float64) *Simple {
+  return NewScalar("float", x)
+}
+
+func NewInt(i int) *Simple {
+  return NewScalar("int", float64(i))
+}
+
+func NewBool(b bool) *Simple {
+  if b {
+    return NewScalar("bool", 1.0)
+  } else {
+    return NewScalar("bool", 0.0)
+  }
+}
+
+func NewVec(compType string, comps []float64) *Simple {
+  s := &Simple{compType, comps}
+
+  s.normalize()
+
+  return s
+}
+
+func (d *Simple) normalize() {
+  for i, x := range d.comps {
+    switch d.compType {
+    case "int":
+      d.comps[i] = math.Trunc(x)
+    case "bool":
+      if x != 0.0 {
+        d.comps[i] = 1.0
+      }
+    }
+  }
+}
+
+func (d *Simple) CompType() string {
+  return d.compType
+}
+
+func (d *Simple) Comps() []float64 {
+  return d.comps
+}
+
+func (d *Simple) Length() int {
+  return len(d.comps)
+}
+
+func (d *Simple) IsScalar() bool {
+  return len(d.comps) == 1
+}
+
+func (d *Simple) Float() float64 {
+  return d.comps[0]
+}
+
+func (d *Simple) Int() int {
+  return int(d.comps[0])
+}
+
+func (d *Simple) Bool() bool {
+  return d.comps[0] != 0.0
+}
+
+func (d *Simple) TypeName() string {
+  n := len(d.comps)
+  if n == 1 {
+    return d.compType
+  }
+
+  typeName := "vec" + strconv.Itoa(n)
+
+  switch d.compType {
+  case "int":
+    typeName = "i" + typeName
+  case "bool":
+    typeName = "b" + typeName
+  }
+
+  return typeName
+}
+
+func (d *Simple) Copy() Data {
+  comps := make([]float64, len(d.comps))
+  copy(comps, d.comps)
+
+  return &Simple{d.compType, comps}
+}
+
+func (d *Simple) writeComp(x float64) string {
+  switch d.compType {
+  case "int":
+    return strconv.Itoa(int(x))
+  case "bool":
+    if x != 0.0 {
+      return "true"
+    } else {
+      return "false"
+    }
+  default:
+    res := strconv.FormatFloat(x, 'g', -1, 64)
+    if !strings.ContainsAny(res, ".eIN") {
+      res += ".0"
+    }
+
+    return res
+  }
+}
+
+func (d *Simple) Write() string {
+  if d.IsScalar() {
+    return d.writeComp(d.comps[0])
+  }
+
+  var b strings.Builder
+
+  b.WriteString(d.TypeName())
+  b.WriteString("(")
+
+  for i, x := range d.comps {
+    b.WriteString(d.writeComp(x))
+
+    if i < len(d.comps) - 1 {
+      b.WriteString(",")
+    }
+  }
+
+  b.WriteString(")")
+
+  return b.String()
+}
+
+func (d *Simple) ApproxEqual(other_ Data, tol float64) bool {
+  other, ok := other_.(*Simple)
+  if !ok || other.TypeName() != d.TypeName() {
+    return false
+  }
+
+  for i, x := range d.comps {
+    if math.Abs(x - other.comps[i]) > tol {
+      return false
+    }
+  }
+
+  return true
+}
+
+func swizzleIndex(c byte) int {
+  switch c {
+  case 'x', 'r', 's':
+    return 0
+  case 'y', 'g', 't':
+    return 1
+  case 'z', 'b', 'p':
+    return 2
+  case 'w', 'a', 'q':
+    return 3
+  default:
+    return -1
+  }
+}
+
+func (d *Simple) swizzleIndices(key string, ctx context.Context) ([]int, error) {
+  if d.IsScalar() || len(key) > 4 {
+    return nil, ctx.NewError("Error: " + d.TypeName() + "." + key + " not found")
+  }
+
+  res := make([]int, len(key))
+  for i := 0; i < len(key); i++ {
+    res[i] = swizzleIndex(key[i])
+
+    if res[i] < 0 || res[i] >= len(d.comps) {
+      return nil, ctx.NewError("Error: " + d.TypeName() + "." + key + " not found")
+    }
+  }
+
+  return res, nil
+}
+
+func (d *Simple) GetSwizzle(key string, ctx context.Context) (*Simple, error) {
+  indices, err := d.swizzleIndices(key, ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  comps := make([]float64, len(indices))
+  for i, j := range indices {
+    comps[i] = d.comps[j]
+  }
+
+  return NewVec(d.compType, comps), nil
+}
+
+func (d *Simple) SetSwizzle(key string, arg Data, ctx context.Context) error {
+  indices, err := d.swizzleIndices(key, ctx)
+  if err != nil {
+    return err
+  }
+
+  src, ok := arg.(*Simple)
+  if !ok || src.compType != d.compType || len(src.comps) != len(indices) {
+    return ctx.NewError("Error: can't assign " + arg.TypeName() + " to " + d.TypeName() + "." + key)
+  }
+
+  for i, j := range indices {
+    d.comps[j] = src.comps[i]
+  }
+
+  return nil
+}
+
+func (d *Simple) GetIndex(i int, ctx context.Context) (Data, error) {
+  if d.IsScalar() || i < 0 || i >= len(d.comps) {
+    return nil, ctx.NewError("Error: index " + strconv.Itoa(i) + " out of range")
+  }
+
+  return NewScalar(d.compType, d.comps[i]), nil
+}
+
+func (d *Simple) SetIndex(i int, arg Data, ctx context.Context) error {
+  if d.IsScalar() || i < 0 || i >= len(d.comps) {
+    return ctx.NewError("Error: index " + strconv.Itoa(i) + " out of range")
+  }
+
+  src, ok := arg.(*Simple)
+  if !ok || !src.IsScalar() || src.compType != d.compType {
+    return ctx.NewError("Error: can't assign " + arg.TypeName() + " to component of " + d.TypeName())
+  }
+
+  d.comps[i] = src.comps[0]
+
+  return nil
+}
+
+func AssertSimple(d_ Data, ctx context.Context) (*Simple, error) {
+  if d, ok := d_.(*Simple); ok {
+    return d, nil
+  } else {
+    return nil, ctx.NewError("Error: expected scalar or vector, got " + d_.TypeName())
+  }
+}
+
+func AssertScalar(d_ Data, compType string, ctx context.Context) (*Simple, error) {
+  if d, ok := d_.(*Simple); ok && d.IsScalar() && d.compType == compType {
+    return d, nil
+  } else {
+    return nil, ctx.NewError("Error: expected " + compType + ", got " + d_.TypeName())
+  }
+}
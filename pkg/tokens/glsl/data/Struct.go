@@ -0,0 +1,96 @@
+package data
+
+import (
+  "strings"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+type Struct struct {
+  name string
+  keys []string // keep the order of the declaration
+  members map[string]Data
+}
+
+func NewStruct(name string, keys []string, members []Data) *Struct {
+  m := make(map[string]Data)
+
+  for i, key := range keys {
+    m[key] = members[i]
+  }
+
+  return &Struct{name, keys, m}
+}
+
+func (d *Struct) Keys() []string {
+  return d.keys
+}
+
+func (d *Struct) TypeName() string {
+  return "struct " + d.name
+}
+
+func (d *Struct) Copy() Data {
+  members := make([]Data, len(d.keys))
+  for i, key := range d.keys {
+    members[i] = d.members[key].Copy()
+  }
+
+  return NewStruct(d.name, d.keys, members)
+}
+
+func (d *Struct) Write() string {
+  var b strings.Builder
+
+  b.WriteString(d.name)
+  b.WriteString("(")
+  for i, key := range d.keys {
+    b.WriteString(d.members[key].Write())
+
+    if i < len(d.keys) - 1 {
+      b.WriteString(",")
+    }
+  }
+  b.WriteString(")")
+
+  return b.String()
+}
+
+func (d *Struct) ApproxEqual(other_ Data, tol float64) bool {
+  other, ok := other_.(*Struct)
+  if !ok || other.name != d.name {
+    return false
+  }
+
+  for _, key := range d.keys {
+    if !d.members[key].ApproxEqual(other.members[key], tol) {
+      return false
+    }
+  }
+
+  return true
+}
+
+// returns a reference, so the member can be modified in place
+func (d *Struct) GetMember(key string, ctx context.Context) (Data, error) {
+  if m, ok := d.members[key]; ok {
+    return m, nil
+  } else {
+    return nil, ctx.NewError("Error: " + d.name + "." + key + " not found")
+  }
+}
+
+func (d *Struct) SetMember(key string, arg Data, ctx context.Context) error {
+  m, ok := d.members[key]
+  if !ok {
+    return ctx.NewError("Error: " + d.name + "." + key + " not found")
+  }
+
+  if m.TypeName() != arg.TypeName() {
+    return ctx.NewError("Error: can't assign " + arg.TypeName() + " to " + d.name + "." + key)
+  }
+
+  d.members[key] = arg.Copy()
+
+  return nil
+}
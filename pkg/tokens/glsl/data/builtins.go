@@ -0,0 +1,522 @@
+package data
+
+import (
+  "math"
+  "strconv"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+type BuiltinFunction func(args []Data, ctx context.Context) (Data, error)
+
+var _builtins map[string]BuiltinFunction = nil
+
+func IsBuiltin(name string) bool {
+  _, ok := _builtins[name]
+  return ok
+}
+
+func CallBuiltin(name string, args []Data, ctx context.Context) (Data, error) {
+  fn, ok := _builtins[name]
+  if !ok {
+    return nil, ctx.NewError("Error: builtin " + name + " not available in interpreter")
+  }
+
+  return fn(args, ctx)
+}
+
+func assertNArgs(args []Data, n int, ctx context.Context) error {
+  if len(args) != n {
+    return ctx.NewError("Error: expected " + strconv.Itoa(n) + " arguments, got " + strconv.Itoa(len(args)))
+  }
+
+  return nil
+}
+
+func assertSimpleArgs(args []Data, n int, ctx context.Context) ([]*Simple, error) {
+  if err := assertNArgs(args, n, ctx); err != nil {
+    return nil, err
+  }
+
+  res := make([]*Simple, n)
+  for i, arg := range args {
+    var err error
+    res[i], err = AssertSimple(arg, ctx)
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  return res, nil
+}
+
+func flattenArgs(args []Data, n int, ctx context.Context) ([]float64, error) {
+  comps := make([]float64, 0)
+  for i, arg_ := range args {
+    if len(comps) >= n {
+      return nil, ctx.NewError("Error: too many arguments (argument " + strconv.Itoa(i+1) + " unused)")
+    }
+
+    switch arg := arg_.(type) {
+    case *Simple:
+      comps = append(comps, arg.comps...)
+    case *Mat:
+      comps = append(comps, arg.comps...)
+    default:
+      return nil, ctx.NewError("Error: expected scalar, vector or matrix, got " + arg_.TypeName())
+    }
+  }
+
+  return comps, nil
+}
+
+// constructors flatten all the arguments, a single scalar argument is broadcast
+func newConstructor(compType string, n int) BuiltinFunction {
+  return func(args []Data, ctx context.Context) (Data, error) {
+    if len(args) == 0 {
+      return nil, ctx.NewError("Error: expected at least 1 argument")
+    }
+
+    comps, err := flattenArgs(args, n, ctx)
+    if err != nil {
+      return nil, err
+    }
+
+    if len(args) == 1 && len(comps) == 1 {
+      for len(comps) < n {
+        comps = append(comps, comps[0])
+      }
+    } else if len(comps) < n {
+      return nil, ctx.NewError("Error: not enough components")
+    }
+
+    return NewVec(compType, comps[0:n]), nil
+  }
+}
+
+// a single scalar argument fills the diagonal, a single matrix argument is resized
+func newMatConstructor(n int) BuiltinFunction {
+  return func(args []Data, ctx context.Context) (Data, error) {
+    if len(args) == 0 {
+      return nil, ctx.NewError("Error: expected at least 1 argument")
+    }
+
+    if len(args) == 1 {
+      switch arg := args[0].(type) {
+      case *Mat:
+        return arg.resize(n), nil
+      case *Simple:
+        if arg.IsScalar() {
+          comps := make([]float64, n*n)
+          for i := 0; i < n; i++ {
+            comps[i*n + i] = arg.comps[0]
+          }
+
+          return NewMat(n, comps), nil
+        }
+      }
+    }
+
+    comps, err := flattenArgs(args, n*n, ctx)
+    if err != nil {
+      return nil, err
+    }
+
+    if len(comps) < n*n {
+      return nil, ctx.NewError("Error: not enough components")
+    }
+
+    return NewMat(n, comps[0:n*n]), nil
+  }
+}
+
+// applied to scalars and to vectors componentwise
+func newOneToOne(fn func(x float64) float64) BuiltinFunction {
+  return func(args []Data, ctx context.Context) (Data, error) {
+    as, err := assertSimpleArgs(args, 1, ctx)
+    if err != nil {
+      return nil, err
+    }
+
+    a := as[0]
+
+    comps := make([]float64, len(a.comps))
+    for i, x := range a.comps {
+      comps[i] = fn(x)
+    }
+
+    return NewVec(a.compType, comps), nil
+  }
+}
+
+// second (and third) arguments can be scalars that are broadcast
+func newTwoToOne(name string, fn func(x, y float64) float64) BuiltinFunction {
+  return func(args []Data, ctx context.Context) (Data, error) {
+    if err := assertNArgs(args, 2, ctx); err != nil {
+      return nil, err
+    }
+
+    return componentwise(args[0], args[1], name, ctx, fn)
+  }
+}
+
+func newThreeToOne(name string, fn func(x, y, z float64) float64) BuiltinFunction {
+  return func(args []Data, ctx context.Context) (Data, error) {
+    as, err := assertSimpleArgs(args, 3, ctx)
+    if err != nil {
+      return nil, err
+    }
+
+    n := 1
+    for _, a := range as {
+      if a.Length() > n {
+        n = a.Length()
+      }
+    }
+
+    comp := func(a *Simple, i int) float64 {
+      if a.IsScalar() {
+        return a.comps[0]
+      } else {
+        return a.comps[i]
+      }
+    }
+
+    comps := make([]float64, n)
+    for i := 0; i < n; i++ {
+      for _, a := range as {
+        if !a.IsScalar() && a.Length() != n {
+          return nil, ctx.NewError("Error: bad " + name + " arguments")
+        }
+      }
+
+      comps[i] = fn(comp(as[0], i), comp(as[1], i), comp(as[2], i))
+    }
+
+    return NewVec("float", comps), nil
+  }
+}
+
+func dot(a *Simple, b *Simple) float64 {
+  res := 0.0
+  for i, x := range a.comps {
+    res += x*b.comps[i]
+  }
+
+  return res
+}
+
+func assertSameLength(a *Simple, b *Simple, ctx context.Context) error {
+  if a.TypeName() != b.TypeName() {
+    return ctx.NewError("Error: expected " + a.TypeName() + ", got " + b.TypeName())
+  }
+
+  return nil
+}
+
+func builtinLength(args []Data, ctx context.Context) (Data, error) {
+  as, err := assertSimpleArgs(args, 1, ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  return NewFloat(math.Sqrt(dot(as[0], as[0]))), nil
+}
+
+func builtinDistance(args []Data, ctx context.Context) (Data, error) {
+  if err := assertNArgs(args, 2, ctx); err != nil {
+    return nil, err
+  }
+
+  d, err := Sub(args[0], args[1], ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  return builtinLength([]Data{d}, ctx)
+}
+
+func builtinDot(args []Data, ctx context.Context) (Data, error) {
+  as, err := assertSimpleArgs(args, 2, ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  if err := assertSameLength(as[0], as[1], ctx); err != nil {
+    return nil, err
+  }
+
+  return NewFloat(dot(as[0], as[1])), nil
+}
+
+func builtinCross(args []Data, ctx context.Context) (Data, error) {
+  as, err := assertSimpleArgs(args, 2, ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  a, b := as[0], as[1]
+  if a.TypeName() != "vec3" || b.TypeName() != "vec3" {
+    return nil, ctx.NewError("Error: expected vec3 arguments")
+  }
+
+  return NewVec("float", []float64{
+    a.comps[1]*b.comps[2] - a.comps[2]*b.comps[1],
+    a.comps[2]*b.comps[0] - a.comps[0]*b.comps[2],
+    a.comps[0]*b.comps[1] - a.comps[1]*b.comps[0],
+  }), nil
+}
+
+func builtinNormalize(args []Data, ctx context.Context) (Data, error) {
+  as, err := assertSimpleArgs(args, 1, ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  l := math.Sqrt(dot(as[0], as[0]))
+
+  return Div(as[0], NewFloat(l), ctx)
+}
+
+func builtinAtan(args []Data, ctx context.Context) (Data, error) {
+  if len(args) == 1 {
+    return newOneToOne(math.Atan)(args, ctx)
+  } else {
+    return newTwoToOne("atan", math.Atan2)(args, ctx)
+  }
+}
+
+func builtinReflect(args []Data, ctx context.Context) (Data, error) {
+  as, err := assertSimpleArgs(args, 2, ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  i, n := as[0], as[1]
+
+  // I - 2.0*dot(N, I)*N
+  d := NewFloat(2.0*dot(n, i))
+  dn, err := Mul(d, n, ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  return Sub(i, dn, ctx)
+}
+
+func builtinRefract(args []Data, ctx context.Context) (Data, error) {
+  if err := assertNArgs(args, 3, ctx); err != nil {
+    return nil, err
+  }
+
+  i, err := AssertSimple(args[0], ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  n, err := AssertSimple(args[1], ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  eta_, err := AssertScalar(args[2], "float", ctx)
+  if err != nil {
+    return nil, err
+  }
+  eta := eta_.Float()
+
+  ni := dot(n, i)
+  k := 1.0 - eta*eta*(1.0 - ni*ni)
+  if k < 0.0 {
+    return NewZero(i.TypeName(), ctx)
+  }
+
+  // eta*I - (eta*dot(N, I) + sqrt(k))*N
+  a, err := Mul(NewFloat(eta), i, ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  b, err := Mul(NewFloat(eta*ni + math.Sqrt(k)), n, ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  return Sub(a, b, ctx)
+}
+
+func builtinFaceForward(args []Data, ctx context.Context) (Data, error) {
+  as, err := assertSimpleArgs(args, 3, ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  if dot(as[2], as[1]) < 0.0 {
+    return as[0].Copy(), nil
+  } else {
+    return Neg(as[0], ctx)
+  }
+}
+
+func newCompareVecs(fn func(x, y float64) bool) BuiltinFunction {
+  return func(args []Data, ctx context.Context) (Data, error) {
+    as, err := assertSimpleArgs(args, 2, ctx)
+    if err != nil {
+      return nil, err
+    }
+
+    if err := assertSameLength(as[0], as[1], ctx); err != nil {
+      return nil, err
+    }
+
+    comps := make([]float64, as[0].Length())
+    for i, x := range as[0].comps {
+      if fn(x, as[1].comps[i]) {
+        comps[i] = 1.0
+      }
+    }
+
+    return NewVec("bool", comps), nil
+  }
+}
+
+func newAnyAll(all bool) BuiltinFunction {
+  return func(args []Data, ctx context.Context) (Data, error) {
+    as, err := assertSimpleArgs(args, 1, ctx)
+    if err != nil {
+      return nil, err
+    }
+
+    for _, x := range as[0].comps {
+      if (x != 0.0) != all {
+        return NewBool(!all), nil
+      }
+    }
+
+    return NewBool(all), nil
+  }
+}
+
+func builtinNot(args []Data, ctx context.Context) (Data, error) {
+  as, err := assertSimpleArgs(args, 1, ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  comps := make([]float64, as[0].Length())
+  for i, x := range as[0].comps {
+    if x == 0.0 {
+      comps[i] = 1.0
+    }
+  }
+
+  return NewVec("bool", comps), nil
+}
+
+func builtinMatrixCompMult(args []Data, ctx context.Context) (Data, error) {
+  if err := assertNArgs(args, 2, ctx); err != nil {
+    return nil, err
+  }
+
+  if _, ok := args[0].(*Mat); !ok {
+    return nil, ctx.NewError("Error: expected matrix, got " + args[0].TypeName())
+  } else if _, ok := args[1].(*Mat); !ok {
+    return nil, ctx.NewError("Error: expected matrix, got " + args[1].TypeName())
+  }
+
+  return matComponentwise(args[0], args[1], "multiply", ctx, func(x, y float64) float64 {
+    return x * y
+  })
+}
+
+// glsl mod differs from math.Mod for negative numbers
+func fmod(x, y float64) float64 {
+  return x - y*math.Floor(x/y)
+}
+
+func clamp(x, a, b float64) float64 {
+  return math.Min(math.Max(x, a), b)
+}
+
+func init() {
+  _builtins = map[string]BuiltinFunction{
+    "float": newConstructor("float", 1),
+    "int":   newConstructor("int", 1),
+    "bool":  newConstructor("bool", 1),
+    "vec2":  newConstructor("float", 2),
+    "vec3":  newConstructor("float", 3),
+    "vec4":  newConstructor("float", 4),
+    "ivec2": newConstructor("int", 2),
+    "ivec3": newConstructor("int", 3),
+    "ivec4": newConstructor("int", 4),
+    "bvec2": newConstructor("bool", 2),
+    "bvec3": newConstructor("bool", 3),
+    "bvec4": newConstructor("bool", 4),
+    "mat2":  newMatConstructor(2),
+    "mat3":  newMatConstructor(3),
+    "mat4":  newMatConstructor(4),
+
+    "abs":         newOneToOne(math.Abs),
+    "acos":        newOneToOne(math.Acos),
+    "all":         newAnyAll(true),
+    "any":         newAnyAll(false),
+    "asin":        newOneToOne(math.Asin),
+    "atan":        builtinAtan,
+    "ceil":        newOneToOne(math.Ceil),
+    "clamp":       newThreeToOne("clamp", clamp),
+    "cos":         newOneToOne(math.Cos),
+    "cross":       builtinCross,
+    "degrees":     newOneToOne(func(x float64) float64 {return x*180.0/math.Pi}),
+    "distance":    builtinDistance,
+    "dot":         builtinDot,
+    "equal":       newCompareVecs(func(x, y float64) bool {return x == y}),
+    "exp":         newOneToOne(math.Exp),
+    "exp2":        newOneToOne(math.Exp2),
+    "faceforward": builtinFaceForward,
+    "floor":       newOneToOne(math.Floor),
+    "fract":       newOneToOne(func(x float64) float64 {return x - math.Floor(x)}),
+    "greaterThan": newCompareVecs(func(x, y float64) bool {return x > y}),
+    "greaterThanEqual": newCompareVecs(func(x, y float64) bool {return x >= y}),
+    "inversesqrt": newOneToOne(func(x float64) float64 {return 1.0/math.Sqrt(x)}),
+    "length":      builtinLength,
+    "lessThan":    newCompareVecs(func(x, y float64) bool {return x < y}),
+    "lessThanEqual": newCompareVecs(func(x, y float64) bool {return x <= y}),
+    "log":         newOneToOne(math.Log),
+    "log2":        newOneToOne(math.Log2),
+    "matrixCompMult": builtinMatrixCompMult,
+    "max":         newTwoToOne("max", math.Max),
+    "min":         newTwoToOne("min", math.Min),
+    "mix":         newThreeToOne("mix", func(x, y, a float64) float64 {return x*(1.0 - a) + y*a}),
+    "mod":         newTwoToOne("mod", fmod),
+    "normalize":   builtinNormalize,
+    "not":         builtinNot,
+    "notEqual":    newCompareVecs(func(x, y float64) bool {return x != y}),
+    "pow":         newTwoToOne("pow", math.Pow),
+    "radians":     newOneToOne(func(x float64) float64 {return x*math.Pi/180.0}),
+    "reflect":     builtinReflect,
+    "refract":     builtinRefract,
+    "sign":        newOneToOne(func(x float64) float64 {
+      if x > 0.0 {
+        return 1.0
+      } else if x < 0.0 {
+        return -1.0
+      } else {
+        return 0.0
+      }
+    }),
+    "sin":         newOneToOne(math.Sin),
+    "smoothstep":  newThreeToOne("smoothstep", func(a, b, x float64) float64 {
+      t := clamp((x - a)/(b - a), 0.0, 1.0)
+      return t*t*(3.0 - 2.0*t)
+    }),
+    "sqrt":        newOneToOne(math.Sqrt),
+    "step":        newTwoToOne("step", func(edge, x float64) float64 {
+      if x < edge {
+        return 0.0
+      } else {
+        return 1.0
+      }
+    }),
+    "tan":         newOneToOne(math.Tan),
+  }
+}
@@ -0,0 +1,321 @@
+package data
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+// componentwise op, scalars are broadcast to the length of the other operand
+func componentwise(a_ Data, b_ Data, opName string, ctx context.Context, fn func(x, y float64) float64) (*Simple, error) {
+  a, aOk := a_.(*Simple)
+  b, bOk := b_.(*Simple)
+
+  if !aOk || !bOk || a.compType != b.compType || a.compType == "bool" {
+    return nil, ctx.NewError("Error: can't " + opName + " " + a_.TypeName() + " and " + b_.TypeName())
+  }
+
+  n := len(a.comps)
+  if b.IsScalar() {
+    // ok
+  } else if a.IsScalar() {
+    n = len(b.comps)
+  } else if len(b.comps) != n {
+    return nil, ctx.NewError("Error: can't " + opName + " " + a_.TypeName() + " and " + b_.TypeName())
+  }
+
+  comps := make([]float64, n)
+  for i := 0; i < n; i++ {
+    x := a.comps[0]
+    if !a.IsScalar() {
+      x = a.comps[i]
+    }
+
+    y := b.comps[0]
+    if !b.IsScalar() {
+      y = b.comps[i]
+    }
+
+    comps[i] = fn(x, y)
+  }
+
+  return NewVec(a.compType, comps), nil
+}
+
+// mat-mat, mat-float and float-mat componentwise op
+// returns nil if neither a nor b is a mat
+func matComponentwise(a_ Data, b_ Data, opName string, ctx context.Context, fn func(x, y float64) float64) (Data, error) {
+  aMat, aIsMat := a_.(*Mat)
+  bMat, bIsMat := b_.(*Mat)
+
+  if !aIsMat && !bIsMat {
+    return nil, nil
+  }
+
+  // scalar operands are broadcast
+  getA := func(i int) float64 {
+    return a_.(*Simple).comps[0]
+  }
+
+  getB := func(i int) float64 {
+    return b_.(*Simple).comps[0]
+  }
+
+  n := 0
+  switch {
+  case aIsMat && bIsMat && aMat.n == bMat.n:
+    n = aMat.n
+    getA = func(i int) float64 {return aMat.comps[i]}
+    getB = func(i int) float64 {return bMat.comps[i]}
+  case aIsMat && !bIsMat:
+    if _, err := AssertScalar(b_, "float", ctx); err == nil {
+      n = aMat.n
+      getA = func(i int) float64 {return aMat.comps[i]}
+    }
+  case !aIsMat && bIsMat:
+    if _, err := AssertScalar(a_, "float", ctx); err == nil {
+      n = bMat.n
+      getB = func(i int) float64 {return bMat.comps[i]}
+    }
+  }
+
+  if n == 0 {
+    return nil, ctx.NewError("Error: can't " + opName + " " + a_.TypeName() + " and " + b_.TypeName())
+  }
+
+  comps := make([]float64, n*n)
+  for i := range comps {
+    comps[i] = fn(getA(i), getB(i))
+  }
+
+  return NewMat(n, comps), nil
+}
+
+// linear algebraic product of mat-mat, mat-vec (column vector) and vec-mat (row vector)
+// returns nil if neither a nor b is a mat, or if one of them is a scalar
+func matMul(a_ Data, b_ Data, ctx context.Context) (Data, error) {
+  aMat, aIsMat := a_.(*Mat)
+  bMat, bIsMat := b_.(*Mat)
+
+  switch {
+  case aIsMat && bIsMat:
+    if aMat.n != bMat.n {
+      return nil, ctx.NewError("Error: can't multiply " + a_.TypeName() + " and " + b_.TypeName())
+    }
+
+    n := aMat.n
+    comps := make([]float64, n*n)
+    for j := 0; j < n; j++ {
+      for i := 0; i < n; i++ {
+        for k := 0; k < n; k++ {
+          comps[j*n + i] += aMat.Get(k, i)*bMat.Get(j, k)
+        }
+      }
+    }
+
+    return NewMat(n, comps), nil
+  case aIsMat:
+    b, ok := b_.(*Simple)
+    if !ok || b.IsScalar() {
+      return nil, nil
+    } else if b.compType != "float" || len(b.comps) != aMat.n {
+      return nil, ctx.NewError("Error: can't multiply " + a_.TypeName() + " and " + b_.TypeName())
+    }
+
+    n := aMat.n
+    comps := make([]float64, n)
+    for i := 0; i < n; i++ {
+      for k := 0; k < n; k++ {
+        comps[i] += aMat.Get(k, i)*b.comps[k]
+      }
+    }
+
+    return NewVec("float", comps), nil
+  case bIsMat:
+    a, ok := a_.(*Simple)
+    if !ok || a.IsScalar() {
+      return nil, nil
+    } else if a.compType != "float" || len(a.comps) != bMat.n {
+      return nil, ctx.NewError("Error: can't multiply " + a_.TypeName() + " and " + b_.TypeName())
+    }
+
+    n := bMat.n
+    comps := make([]float64, n)
+    for j := 0; j < n; j++ {
+      for k := 0; k < n; k++ {
+        comps[j] += a.comps[k]*bMat.Get(j, k)
+      }
+    }
+
+    return NewVec("float", comps), nil
+  default:
+    return nil, nil
+  }
+}
+
+func Add(a Data, b Data, ctx context.Context) (Data, error) {
+  if res, err := matComponentwise(a, b, "add", ctx, func(x, y float64) float64 {
+    return x + y
+  }); err != nil || res != nil {
+    return res, err
+  }
+
+  return componentwise(a, b, "add", ctx, func(x, y float64) float64 {
+    return x + y
+  })
+}
+
+func Sub(a Data, b Data, ctx context.Context) (Data, error) {
+  if res, err := matComponentwise(a, b, "subtract", ctx, func(x, y float64) float64 {
+    return x - y
+  }); err != nil || res != nil {
+    return res, err
+  }
+
+  return componentwise(a, b, "subtract", ctx, func(x, y float64) float64 {
+    return x - y
+  })
+}
+
+func Mul(a Data, b Data, ctx context.Context) (Data, error) {
+  if res, err := matMul(a, b, ctx); err != nil || res != nil {
+    return res, err
+  }
+
+  if res, err := matComponentwise(a, b, "multiply", ctx, func(x, y float64) float64 {
+    return x * y
+  }); err != nil || res != nil {
+    return res, err
+  }
+
+  return componentwise(a, b, "multiply", ctx, func(x, y float64) float64 {
+    return x * y
+  })
+}
+
+func Div(a Data, b Data, ctx context.Context) (Data, error) {
+  if res, err := matComponentwise(a, b, "divide", ctx, func(x, y float64) float64 {
+    return x / y
+  }); err != nil || res != nil {
+    return res, err
+  }
+
+  // int division by zero is undefined in glsl, but we don't want to crash
+  return componentwise(a, b, "divide", ctx, func(x, y float64) float64 {
+    return x / y
+  })
+}
+
+func Neg(a_ Data, ctx context.Context) (Data, error) {
+  if aMat, ok := a_.(*Mat); ok {
+    comps := make([]float64, len(aMat.comps))
+    for i, x := range aMat.comps {
+      comps[i] = -x
+    }
+
+    return NewMat(aMat.n, comps), nil
+  }
+
+  a, ok := a_.(*Simple)
+  if !ok || a.compType == "bool" {
+    return nil, ctx.NewError("Error: can't negate " + a_.TypeName())
+  }
+
+  comps := make([]float64, len(a.comps))
+  for i, x := range a.comps {
+    comps[i] = -x
+  }
+
+  return NewVec(a.compType, comps), nil
+}
+
+func Not(a_ Data, ctx context.Context) (Data, error) {
+  a, err := AssertScalar(a_, "bool", ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  return NewBool(!a.Bool()), nil
+}
+
+func logical(a_ Data, b_ Data, ctx context.Context, fn func(x, y bool) bool) (Data, error) {
+  a, err := AssertScalar(a_, "bool", ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  b, err := AssertScalar(b_, "bool", ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  return NewBool(fn(a.Bool(), b.Bool())), nil
+}
+
+func Xor(a Data, b Data, ctx context.Context) (Data, error) {
+  return logical(a, b, ctx, func(x, y bool) bool {
+    return x != y
+  })
+}
+
+// && and || are short-circuited by the caller, so both arguments are already evaluated here
+func And(a Data, b Data, ctx context.Context) (Data, error) {
+  return logical(a, b, ctx, func(x, y bool) bool {
+    return x && y
+  })
+}
+
+func Or(a Data, b Data, ctx context.Context) (Data, error) {
+  return logical(a, b, ctx, func(x, y bool) bool {
+    return x || y
+  })
+}
+
+func compare(a_ Data, b_ Data, op string, ctx context.Context, fn func(x, y float64) bool) (Data, error) {
+  a, aOk := a_.(*Simple)
+  b, bOk := b_.(*Simple)
+
+  if !aOk || !bOk || !a.IsScalar() || !b.IsScalar() || a.compType != b.compType || a.compType == "bool" {
+    return nil, ctx.NewError("Error: " + a_.TypeName() + op + b_.TypeName() + " is illegal")
+  }
+
+  return NewBool(fn(a.Float(), b.Float())), nil
+}
+
+func LT(a Data, b Data, ctx context.Context) (Data, error) {
+  return compare(a, b, "<", ctx, func(x, y float64) bool {
+    return x < y
+  })
+}
+
+func GT(a Data, b Data, ctx context.Context) (Data, error) {
+  return compare(a, b, ">", ctx, func(x, y float64) bool {
+    return x > y
+  })
+}
+
+func LE(a Data, b Data, ctx context.Context) (Data, error) {
+  return compare(a, b, "<=", ctx, func(x, y float64) bool {
+    return x <= y
+  })
+}
+
+func GE(a Data, b Data, ctx context.Context) (Data, error) {
+  return compare(a, b, ">=", ctx, func(x, y float64) bool {
+    return x >= y
+  })
+}
+
+func Eq(a Data, b Data, ctx context.Context) (Data, error) {
+  if a.TypeName() != b.TypeName() {
+    return nil, ctx.NewError("Error: " + a.TypeName() + "==" + b.TypeName() + " is illegal")
+  }
+
+  return NewBool(a.ApproxEqual(b, 0.0)), nil
+}
+
+func NE(a Data, b Data, ctx context.Context) (Data, error) {
+  if a.TypeName() != b.TypeName() {
+    return nil, ctx.NewError("Error: " + a.TypeName() + "!=" + b.TypeName() + " is illegal")
+  }
+
+  return NewBool(!a.ApproxEqual(b, 0.0)), nil
+}
@@ -47,6 +47,10 @@ func FillCoreScope(scope Scope) {
   registerValue(scope, "ivec3", true, values.NewVecType("int", 3, ctx))
   registerValue(scope, "ivec4", true, values.NewVecType("int", 4, ctx))
 
+  registerValue(scope, "mat2", true, values.NewMatType(2, ctx))
+  registerValue(scope, "mat3", true, values.NewMatType(3, ctx))
+  registerValue(scope, "mat4", true, values.NewMatType(4, ctx))
+
 
   // builtin functions
   registerValue(scope, "abs"        , true, values.NewOneToOneFunction(ctx))
@@ -76,6 +80,7 @@ func FillCoreScope(scope Scope) {
   registerValue(scope, "lessThanEqual", true, values.NewCompareFunction(ctx))
   registerValue(scope, "log"        , true, values.NewOneToOneFunction(ctx))
   registerValue(scope, "log2"       , true, values.NewOneToOneFunction(ctx))
+  registerValue(scope, "matrixCompMult", true, values.NewMatrixCompMultFunction(ctx))
   registerValue(scope, "max"        , true, values.NewMinMaxFunction(ctx))
   registerValue(scope, "min"        , true, values.NewMinMaxFunction(ctx))
   registerValue(scope, "mix"        , true, values.NewMixFunction(ctx))
@@ -4,6 +4,7 @@ import (
   "strings"
 
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/data"
 	"github.com/wtsuite/wtsuite/pkg/tokens/glsl/values"
 )
 
@@ -311,12 +312,98 @@ func (t *BinaryOp) evalArgs() (values.Value, values.Value, error) {
   return a, b, nil
 }
 
+// vec-vec, vec-scalar and scalar-vec with matching component types
+// returns nil if neither a nor b is a vec
+func (t *BinaryOp) evalVecArgs(a values.Value, b values.Value, verb string) (values.Value, error) {
+  errCtx := t.Context()
+
+  switch {
+  case values.IsVec(a) && values.IsVec(b):
+    if err := a.Check(b, errCtx); err != nil {
+      return nil, errCtx.NewError("Error: can't " + verb + " " + a.TypeName() + " and " + b.TypeName())
+    }
+
+    return values.NewContextValue(a, errCtx), nil
+  case values.IsVec(a) && values.IsScalar(b):
+    aVec, err := values.AssertVec(a)
+    if err != nil {
+      return nil, err
+    }
+
+    if aVec.CompName() != b.TypeName() {
+      return nil, errCtx.NewError("Error: can't " + verb + " " + a.TypeName() + " and " + b.TypeName())
+    }
+
+    return values.NewContextValue(a, errCtx), nil
+  case values.IsScalar(a) && values.IsVec(b):
+    bVec, err := values.AssertVec(b)
+    if err != nil {
+      return nil, err
+    }
+
+    if bVec.CompName() != a.TypeName() {
+      return nil, errCtx.NewError("Error: can't " + verb + " " + a.TypeName() + " and " + b.TypeName())
+    }
+
+    return values.NewContextValue(b, errCtx), nil
+  default:
+    return nil, nil
+  }
+}
+
+// mat-mat, mat-scalar and scalar-mat, and for multiplication also mat-vec and vec-mat
+// returns nil if neither a nor b is a mat
+func (t *BinaryOp) evalMatArgs(a values.Value, b values.Value, verb string) (values.Value, error) {
+  errCtx := t.Context()
+
+  if !values.IsMat(a) && !values.IsMat(b) {
+    return nil, nil
+  }
+
+  switch {
+  case values.IsMat(a) && values.IsMat(b):
+    if err := a.Check(b, errCtx); err == nil {
+      return values.NewContextValue(a, errCtx), nil
+    }
+  case values.IsMat(a) && values.IsFloat(b):
+    return values.NewContextValue(a, errCtx), nil
+  case values.IsFloat(a) && values.IsMat(b):
+    return values.NewContextValue(b, errCtx), nil
+  case t.op == "*" && values.IsMat(a) && values.IsVec(b):
+    // column vector
+    check := values.NewVec("float", a.Length(), errCtx)
+    if err := check.Check(b, errCtx); err == nil {
+      return values.NewContextValue(b, errCtx), nil
+    }
+  case t.op == "*" && values.IsVec(a) && values.IsMat(b):
+    // row vector
+    check := values.NewVec("float", b.Length(), errCtx)
+    if err := check.Check(a, errCtx); err == nil {
+      return values.NewContextValue(a, errCtx), nil
+    }
+  }
+
+  return nil, errCtx.NewError("Error: can't " + verb + " " + a.TypeName() + " and " + b.TypeName())
+}
+
 func (t *AddOp) EvalExpression() (values.Value, error) {
   a, b, err := t.evalArgs()
   if err != nil {
     return nil, err
   }
 
+  if matVal, err := t.evalMatArgs(a, b, "add"); err != nil {
+    return nil, err
+  } else if matVal != nil {
+    return matVal, nil
+  }
+
+  if vecVal, err := t.evalVecArgs(a, b, "add"); err != nil {
+    return nil, err
+  } else if vecVal != nil {
+    return vecVal, nil
+  }
+
   switch {
   case values.IsInt(a):
     if _, err := values.AssertInt(b); err != nil {
@@ -340,6 +427,18 @@ func (t *SubOp) EvalExpression() (values.Value, error) {
     return nil, err
   }
 
+  if matVal, err := t.evalMatArgs(a, b, "subtract"); err != nil {
+    return nil, err
+  } else if matVal != nil {
+    return matVal, nil
+  }
+
+  if vecVal, err := t.evalVecArgs(a, b, "subtract"); err != nil {
+    return nil, err
+  } else if vecVal != nil {
+    return vecVal, nil
+  }
+
   switch {
   case values.IsInt(a):
     if _, err := values.AssertInt(b); err != nil {
@@ -363,6 +462,18 @@ func (t *DivOp) EvalExpression() (values.Value, error) {
     return nil, err
   }
 
+  if matVal, err := t.evalMatArgs(a, b, "divide"); err != nil {
+    return nil, err
+  } else if matVal != nil {
+    return matVal, nil
+  }
+
+  if vecVal, err := t.evalVecArgs(a, b, "divide"); err != nil {
+    return nil, err
+  } else if vecVal != nil {
+    return vecVal, nil
+  }
+
   switch {
   case values.IsFloat(a):
     if _, err := values.AssertFloat(b); err != nil {
@@ -381,6 +492,18 @@ func (t *MulOp) EvalExpression() (values.Value, error) {
     return nil, err
   }
 
+  if matVal, err := t.evalMatArgs(a, b, "multiply"); err != nil {
+    return nil, err
+  } else if matVal != nil {
+    return matVal, nil
+  }
+
+  if vecVal, err := t.evalVecArgs(a, b, "multiply"); err != nil {
+    return nil, err
+  } else if vecVal != nil {
+    return vecVal, nil
+  }
+
   switch {
   case values.IsInt(a):
     if _, err := values.AssertInt(b); err != nil {
@@ -409,6 +532,10 @@ func (t *NegOp) EvalExpression() (values.Value, error) {
     return values.NewContextValue(a, t.Context()), nil
   case values.IsFloat(a):
     return values.NewContextValue(a, t.Context()), nil
+  case values.IsVec(a) && !strings.HasPrefix(a.TypeName(), "b"):
+    return values.NewContextValue(a, t.Context()), nil
+  case values.IsMat(a):
+    return values.NewContextValue(a, t.Context()), nil
   default:
     errCtx := t.Context()
     return nil, errCtx.NewError("Error: can't negate " + a.TypeName())
@@ -537,3 +664,201 @@ func (t *PostUnaryOp) ResolveStatementActivity(usage Usage) error {
 func (t *PostUnaryOp) UniqueStatementNames(ns Namespace) error {
   return nil
 }
+
+func (t *BinaryOp) interpretArgs(stack *Stack) (data.Data, data.Data, error) {
+  a, err := t.a.InterpretExpression(stack)
+  if err != nil {
+    return nil, nil, err
+  }
+
+  b, err := t.b.InterpretExpression(stack)
+  if err != nil {
+    return nil, nil, err
+  }
+
+  return a, b, nil
+}
+
+func (t *AddOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, b, err := t.interpretArgs(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.Add(a, b, t.Context())
+}
+
+func (t *SubOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, b, err := t.interpretArgs(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.Sub(a, b, t.Context())
+}
+
+func (t *DivOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, b, err := t.interpretArgs(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.Div(a, b, t.Context())
+}
+
+func (t *MulOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, b, err := t.interpretArgs(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.Mul(a, b, t.Context())
+}
+
+func (t *XorOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, b, err := t.interpretArgs(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.Xor(a, b, t.Context())
+}
+
+func (t *LTOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, b, err := t.interpretArgs(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.LT(a, b, t.Context())
+}
+
+func (t *GTOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, b, err := t.interpretArgs(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.GT(a, b, t.Context())
+}
+
+func (t *LEOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, b, err := t.interpretArgs(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.LE(a, b, t.Context())
+}
+
+func (t *GEOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, b, err := t.interpretArgs(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.GE(a, b, t.Context())
+}
+
+func (t *EqOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, b, err := t.interpretArgs(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.Eq(a, b, t.Context())
+}
+
+func (t *NEOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, b, err := t.interpretArgs(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.NE(a, b, t.Context())
+}
+
+// rhs is only evaluated if needed
+func (t *LogicalBinaryOp) interpretShortCircuit(stack *Stack, stopAt bool) (data.Data, error) {
+  a_, err := t.a.InterpretExpression(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  a, err := data.AssertScalar(a_, "bool", t.a.Context())
+  if err != nil {
+    return nil, err
+  }
+
+  if a.Bool() == stopAt {
+    return data.NewBool(stopAt), nil
+  }
+
+  b, err := t.b.InterpretExpression(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  if _, err := data.AssertScalar(b, "bool", t.b.Context()); err != nil {
+    return nil, err
+  }
+
+  return b.Copy(), nil
+}
+
+func (t *AndOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  return t.interpretShortCircuit(stack, false)
+}
+
+func (t *OrOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  return t.interpretShortCircuit(stack, true)
+}
+
+func (t *NegOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, err := t.a.InterpretExpression(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.Neg(a, t.Context())
+}
+
+func (t *PosOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, err := t.a.InterpretExpression(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return a.Copy(), nil
+}
+
+func (t *NotOp) InterpretExpression(stack *Stack) (data.Data, error) {
+  a, err := t.a.InterpretExpression(stack)
+  if err != nil {
+    return nil, err
+  }
+
+  return data.Not(a, t.Context())
+}
+
+func (t *PostUnaryOp) interpretIncr(stack *Stack, delta int) error {
+  a_, err := t.a.InterpretExpression(stack)
+  if err != nil {
+    return err
+  }
+
+  a, err := data.AssertScalar(a_, "int", t.a.Context())
+  if err != nil {
+    return err
+  }
+
+  return interpretAssign(stack, t.a, data.NewInt(a.Int() + delta), t.Context())
+}
+
+func (t *PostIncrOp) InterpretStatement(stack *Stack) error {
+  return t.interpretIncr(stack, 1)
+}
+
+func (t *PostDecrOp) InterpretStatement(stack *Stack) error {
+  return t.interpretIncr(stack, -1)
+}
@@ -109,3 +109,7 @@ func (t *PreProc) ResolveStatementActivity(usage Usage) error {
 func (t *PreProc) UniqueStatementNames(ns Namespace) error {
   return nil
 }
+
+func (t *PreProc) InterpretStatement(stack *Stack) error {
+  return nil
+}
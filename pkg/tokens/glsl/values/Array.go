@@ -73,3 +73,7 @@ func (v *Array) SetIndex(idx *LiteralInt, arg Value, ctx context.Context) error
 func (v *Array) Length() int {
   return v.length
 }
+
+func (v *Array) Content() Value {
+  return v.content
+}
@@ -301,3 +301,15 @@ func NewTextureCubeFunction(ctx context.Context) Value {
     []Value{sC, v3, f, v4},
   }, ctx)
 }
+
+func NewMatrixCompMultFunction(ctx context.Context) Value {
+  m2 := NewMat2(ctx)
+  m3 := NewMat3(ctx)
+  m4 := NewMat4(ctx)
+
+  return NewBuiltinFunction([][]Value{
+    []Value{m2, m2, m2},
+    []Value{m3, m3, m3},
+    []Value{m4, m4, m4},
+  }, ctx)
+}
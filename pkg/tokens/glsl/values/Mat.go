@@ -0,0 +1,110 @@
+package values
+
+import (
+  "strconv"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+// square float matrix, indexing returns a column
+type Mat struct {
+  n int
+  ValueData
+}
+
+func NewMat(n int, ctx context.Context) Value {
+  return &Mat{n, newValueData(ctx)}
+}
+
+func NewMat2(ctx context.Context) Value {
+  return NewMat(2, ctx)
+}
+
+func NewMat3(ctx context.Context) Value {
+  return NewMat(3, ctx)
+}
+
+func NewMat4(ctx context.Context) Value {
+  return NewMat(4, ctx)
+}
+
+func matTypeName(n int) string {
+  if n < 2 || n > 4 {
+    panic("unhandled")
+  }
+
+  return "mat" + strconv.Itoa(n)
+}
+
+func (v *Mat) TypeName() string {
+  return matTypeName(v.n)
+}
+
+func (v *Mat) Check(other_ Value, ctx context.Context) error {
+  other_ = UnpackContextValue(other_)
+
+  if other, ok := other_.(*Mat); ok {
+    if other.n == v.n {
+      return nil
+    }
+  }
+
+  return ctx.NewError("Error: expected " + v.TypeName() + ", got " + other_.TypeName())
+}
+
+func (v *Mat) EvalFunction(args []Value, ctx context.Context) (Value, error) {
+  return nil, ctx.NewError("Error: not a function")
+}
+
+func (v *Mat) GetMember(key string, ctx context.Context) (Value, error) {
+  return nil, ctx.NewError("Error: " + v.TypeName() + "." + key + " not found")
+}
+
+func (v *Mat) SetMember(key string, arg Value, ctx context.Context) error {
+  return ctx.NewError("Error: " + v.TypeName() + "." + key + " not found")
+}
+
+func (v *Mat) GetIndex(idx *LiteralInt, ctx context.Context) (Value, error) {
+  i, _ := idx.LiteralIntValue()
+
+  if i < 0 || i >= v.n {
+    return nil, ctx.NewError("Error: index " + strconv.Itoa(i) + " out of range")
+  }
+
+  return NewVec("float", v.n, ctx), nil
+}
+
+func (v *Mat) SetIndex(idx *LiteralInt, arg Value, ctx context.Context) error {
+  i, _ := idx.LiteralIntValue()
+
+  if i < 0 || i >= v.n {
+    return ctx.NewError("Error: index " + strconv.Itoa(i) + " out of range")
+  }
+
+  check := NewVec("float", v.n, ctx)
+
+  return check.Check(arg, ctx)
+}
+
+// number of columns
+func (v *Mat) Length() int {
+  return v.n
+}
+
+func IsMat(v_ Value) bool {
+  v_ = UnpackContextValue(v_)
+
+  _, ok := v_.(*Mat)
+  return ok
+}
+
+func AssertMat(v_ Value) (*Mat, error) {
+  errCtx := v_.Context()
+  v_ = UnpackContextValue(v_)
+
+  if v, ok := v_.(*Mat); ok {
+    return v, nil
+  } else {
+    return nil, errCtx.NewError("Error: expected mat, got " + v_.TypeName())
+  }
+}
@@ -0,0 +1,71 @@
+package values
+
+import (
+  "strconv"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+type MatType struct {
+  n int // 2, 3 or 4
+  TypeData
+}
+
+func NewMatType(n int, ctx context.Context) Value {
+  return &MatType{n, newTypeData(matTypeName(n), ctx)}
+}
+
+func (v *MatType) Check(other Value, ctx context.Context) error {
+  instance, _ := v.Instantiate(v.Context())
+
+  return instance.Check(other, ctx)
+}
+
+func (v *MatType) Instantiate(ctx context.Context) (Value, error) {
+  return NewMat(v.n, ctx), nil
+}
+
+// a single scalar argument fills the diagonal, a single mat argument is resized,
+// otherwise the components of the scalars, vecs and mats fill the matrix column by column (like vec constructors)
+func (v *MatType) EvalFunction(args []Value, ctx context.Context) (Value, error) {
+  if len(args) == 0 {
+    return nil, ctx.NewError("Error: expected at least 1 argument")
+  }
+
+  if len(args) == 1 && IsMat(args[0]) {
+    return v.Instantiate(ctx)
+  }
+
+  nTotal := v.n*v.n
+
+  n := 0
+  for i := 0; i < len(args); i++ {
+    argCtx := args[i].Context()
+    arg := UnpackContextValue(args[i])
+
+    if n >= nTotal {
+      errCtx := argCtx
+      return nil, errCtx.NewError("Error: too many arguments")
+    }
+
+    switch a := arg.(type) {
+    case *Scalar:
+      n += 1
+    case *LiteralInt:
+      n += 1
+    case *Vec:
+      n += a.n
+    case *Mat:
+      n += a.n*a.n
+    default:
+      errCtx := argCtx
+      return nil, errCtx.NewError("Error: expected scalar, vec or mat argument")
+    }
+  }
+
+  if n < nTotal && !(len(args) == 1 && n == 1) {
+    return nil, ctx.NewError("Error: expected " + strconv.Itoa(nTotal) + " components, got " + strconv.Itoa(n))
+  }
+
+  return v.Instantiate(ctx)
+}
@@ -52,6 +52,10 @@ func NewBVec4(ctx context.Context) Value {
   return NewVec("bool", 4, ctx)
 }
 
+func (v *Vec) CompName() string {
+  return v.compName
+}
+
 func (v *Vec) TypeName() string {
   return vecTypeName(v.compName, v.n)
 }
@@ -169,3 +173,14 @@ func IsVec(v_ Value) bool {
   _, ok := v_.(*Vec)
   return ok
 }
+
+func AssertVec(v_ Value) (*Vec, error) {
+  errCtx := v_.Context()
+  v_ = UnpackContextValue(v_)
+
+  if v, ok := v_.(*Vec); ok {
+    return v, nil
+  } else {
+    return nil, errCtx.NewError("Error: expected vec, got " + v_.TypeName())
+  }
+}
@@ -49,20 +49,40 @@ func (v *VecType) Instantiate(ctx context.Context) (Value, error) {
   return NewVec(v.compType, v.n, ctx), nil
 }
 
-// can't vecs also take other vecs as arguments?
+// arguments can be scalars, vecs or mats, a single scalar is broadcast
+// the last argument can contain more components than needed
 func (v *VecType) EvalFunction(args []Value, ctx context.Context) (Value, error) {
-  if len(args) != v.n {
-    return nil, ctx.NewError("Error: expected " + strconv.Itoa(v.n) + " arguments")
+  if len(args) == 0 {
+    return nil, ctx.NewError("Error: expected at least 1 argument")
   }
 
-  for i := 0; i < v.n; i++ {
+  n := 0
+  for i := 0; i < len(args); i++ {
     argCtx := args[i].Context()
     arg := UnpackContextValue(args[i])
 
-    if _, ok := arg.(*Scalar); !ok {
+    if n >= v.n {
       errCtx := argCtx
-      return nil, errCtx.NewError("Error: expected scalar argument")
+      return nil, errCtx.NewError("Error: too many arguments")
     }
+
+    switch a := arg.(type) {
+    case *Scalar:
+      n += 1
+    case *LiteralInt:
+      n += 1
+    case *Vec:
+      n += a.n
+    case *Mat:
+      n += a.n*a.n
+    default:
+      errCtx := argCtx
+      return nil, errCtx.NewError("Error: expected scalar, vec or mat argument")
+    }
+  }
+
+  if n < v.n && !(len(args) == 1 && n == 1) {
+    return nil, ctx.NewError("Error: expected " + strconv.Itoa(v.n) + " components, got " + strconv.Itoa(n))
   }
 
   return v.Instantiate(ctx)
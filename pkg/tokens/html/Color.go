@@ -41,6 +41,41 @@ func (t *Color) EvalLazy(tag FinalTag) (Token, error) {
 	return t, nil
 }
 
+// named colors that are shorter than their hex notation
+var _shortColorNames = map[string]string{
+	"#f00":    "red",
+	"#d2b48c": "tan",
+	"#f0ffff": "azure",
+	"#f5f5dc": "beige",
+	"#ffe4c4": "bisque",
+	"#a52a2a": "brown",
+	"#ff7f50": "coral",
+	"#ffd700": "gold",
+	"#808080": "gray",
+	"#008000": "green",
+	"#4b0082": "indigo",
+	"#fffff0": "ivory",
+	"#f0e68c": "khaki",
+	"#faf0e6": "linen",
+	"#800000": "maroon",
+	"#000080": "navy",
+	"#808000": "olive",
+	"#ffa500": "orange",
+	"#da70d6": "orchid",
+	"#cd853f": "peru",
+	"#ffc0cb": "pink",
+	"#dda0dd": "plum",
+	"#800080": "purple",
+	"#fa8072": "salmon",
+	"#a0522d": "sienna",
+	"#c0c0c0": "silver",
+	"#fffafa": "snow",
+	"#008080": "teal",
+	"#ff6347": "tomato",
+	"#ee82ee": "violet",
+	"#f5deb3": "wheat",
+}
+
 func (t *Color) Write() string {
 	formatHex := func(i int) string {
 		return fmt.Sprintf("%02x", i)
@@ -64,6 +99,42 @@ func (t *Color) Write() string {
 	}
 }
 
+// most compact css form: short hex (also with alpha), or a shorter color name
+// only for style sheets, because not every consumer of Write understands #rgba hex or color names
+func (t *Color) WriteCSS() string {
+	formatHex := func(i int) string {
+		return fmt.Sprintf("%02x", i)
+	}
+
+	s := formatHex(t.r) + formatHex(t.g) + formatHex(t.b)
+	if t.a != 255 {
+		s += formatHex(t.a)
+	}
+
+	short := true
+	for i := 0; i < len(s); i += 2 {
+		if s[i] != s[i+1] {
+			short = false
+			break
+		}
+	}
+
+	if short {
+		b := make([]byte, 0, len(s)/2)
+		for i := 0; i < len(s); i += 2 {
+			b = append(b, s[i])
+		}
+
+		s = string(b)
+	}
+
+	if name, ok := _shortColorNames["#"+s]; ok {
+		return name
+	}
+
+	return "#" + s
+}
+
 func (t *Color) Dump(indent string) string {
 	return indent + "Color(" + t.Write() + ")\n"
 }
@@ -228,8 +228,10 @@ func FillBrowserScope(scope Scope) {
   registerPrototype(scope, pr.NewURLSearchParamsPrototype())
   registerPrototype(scope, pr.NewWebGLBufferPrototype())
   registerPrototype(scope, pr.NewWebGLExtensionPrototype())
+  registerPrototype(scope, pr.NewWebGLPassPrototype())
   registerPrototype(scope, pr.NewWebGLProgramPrototype())
   registerPrototype(scope, pr.NewWebGLRenderingContextPrototype())
+  registerPrototype(scope, pr.NewWebGLRenderTargetPrototype())
   registerPrototype(scope, pr.NewWebGLShaderPrototype())
   registerPrototype(scope, pr.NewWebGLTexturePrototype())
   registerPrototype(scope, pr.NewWheelEventPrototype())
@@ -81,6 +81,7 @@ func WriteHeaders() string {
     rpcServerHeader,
 		webAssemblyEnvHeader,
     webGLProgramHeader,
+    webGLPassHeader,
 		//searchIndexHeader,
     mathFontHeader,
 	}
@@ -0,0 +1,220 @@
+package macros
+
+import (
+  "sort"
+  "strconv"
+  "strings"
+
+  "github.com/wtsuite/wtsuite/pkg/tokens/context"
+  "github.com/wtsuite/wtsuite/pkg/tokens/js"
+  "github.com/wtsuite/wtsuite/pkg/tokens/js/prototypes"
+  "github.com/wtsuite/wtsuite/pkg/tokens/js/values"
+)
+
+// new WebGLPass(gl, fragmentPath[, fragmentConsts])
+// the vertex stage is a generated fullscreen quad
+type WebGLPass struct {
+  vertexSource string
+  fragmentSource string
+  setters map[string]string // uniform name -> runtime setter code
+  Macro
+}
+
+// returns the vertex source, the fragment source, and the uniforms of the fragment shader (name -> glsl type, eg. "vec3" or "float[4]")
+type TranspileWebGLPassShaderFunc func(callerPath string, fragmentPath *js.Word,
+  fragmentConsts map[string]values.Value) (string, string, map[string]string, error)
+
+var transpileWebGLPassShader TranspileWebGLPassShaderFunc = nil
+
+func RegisterTranspileWebGLPassShader(fn TranspileWebGLPassShaderFunc) bool {
+  transpileWebGLPassShader = fn
+
+  return true
+}
+
+func NewWebGLPass(args []js.Expression, ctx context.Context) (js.Expression, error) {
+  if len(args) < 2 || len(args) > 3 {
+    errCtx := ctx
+    return nil, errCtx.NewError("Error: expected 2 or 3 arguments, got " + strconv.Itoa(len(args)))
+  }
+
+  return &WebGLPass{"", "", nil, newMacro(args, ctx)}, nil
+}
+
+func (m *WebGLPass) Dump(indent string) string {
+  var b strings.Builder
+
+  b.WriteString(indent)
+  b.WriteString("new WebGLPass(...)")
+  for _, arg := range m.args {
+    b.WriteString(arg.Dump(indent + "  "))
+  }
+
+  return b.String()
+}
+
+func (m *WebGLPass) writeSetters() string {
+  var b strings.Builder
+
+  names := make([]string, 0)
+  for name, _ := range m.setters {
+    names = append(names, name)
+  }
+  sort.Strings(names)
+
+  b.WriteString("{")
+  for i, name := range names {
+    b.WriteString(name)
+    b.WriteString(":'")
+    b.WriteString(m.setters[name])
+    b.WriteString("'")
+
+    if i < len(names) - 1 {
+      b.WriteString(",")
+    }
+  }
+  b.WriteString("}")
+
+  return b.String()
+}
+
+func (m *WebGLPass) WriteExpression() string {
+  var b strings.Builder
+
+  b.WriteString("((function(gl,f){")
+  b.WriteString("return ")
+  b.WriteString(webGLPassHeader.Name())
+  b.WriteString("(gl,")
+  b.WriteString(m.vertexSource)
+  b.WriteString(",")
+  b.WriteString(m.fragmentSource)
+  b.WriteString(",")
+  b.WriteString(m.writeSetters())
+  b.WriteString(")})(")
+  b.WriteString(m.args[0].WriteExpression())
+  b.WriteString(",")
+  if (len(m.args) > 2) {
+    b.WriteString(m.args[2].WriteExpression())
+  } else {
+    b.WriteString("{}")
+  }
+  b.WriteString("))")
+
+  return b.String()
+}
+
+func (m *WebGLPass) ResolveExpressionNames(scope js.Scope) error {
+  return m.Macro.ResolveExpressionNames(scope)
+}
+
+// returns the value expected by run(), and the setter code used by the runtime
+func webGLPassUniformInput(typeName string, ctx context.Context) (values.Value, string, error) {
+  isArray := false
+  if i := strings.Index(typeName, "["); i != -1 {
+    isArray = true
+    typeName = typeName[0:i]
+  }
+
+  switch {
+  case typeName == "sampler2D" && !isArray:
+    return nil, "t", nil
+  case typeName == "samplerCube" && !isArray:
+    return nil, "c", nil
+  case typeName == "float" && !isArray:
+    return prototypes.NewNumber(ctx), "1f", nil
+  case typeName == "int" && !isArray:
+    return prototypes.NewInt(ctx), "1i", nil
+  case typeName == "bool" && !isArray:
+    return prototypes.NewBoolean(ctx), "1i", nil
+  case typeName == "float":
+    return prototypes.NewFloat32Array(ctx), "1fv", nil
+  case typeName == "int" || typeName == "bool":
+    return prototypes.NewInt32Array(ctx), "1iv", nil
+  case typeName == "vec2" || typeName == "vec3" || typeName == "vec4":
+    return prototypes.NewFloat32Array(ctx), typeName[3:] + "fv", nil
+  case typeName == "ivec2" || typeName == "ivec3" || typeName == "ivec4",
+    typeName == "bvec2" || typeName == "bvec3" || typeName == "bvec4":
+    return prototypes.NewInt32Array(ctx), typeName[4:] + "iv", nil
+  case typeName == "mat2" || typeName == "mat3" || typeName == "mat4":
+    return prototypes.NewFloat32Array(ctx), "m" + typeName[3:], nil
+  default:
+    return nil, "", ctx.NewError("Error: uniform of type " + typeName + " can't be set by WebGLPass")
+  }
+}
+
+func (m *WebGLPass) EvalExpression() (values.Value, error) {
+  args, err := m.evalArgs()
+  if err != nil {
+    return nil, err
+  }
+
+  if !prototypes.IsWebGLRenderingContext(args[0]) {
+    errCtx := m.args[0].Context()
+    return nil, errCtx.NewError("Error: expected WebGLRenderingContext, got " + args[0].TypeName())
+  }
+
+  fragmentPath_, ok := args[1].LiteralStringValue()
+  if !ok {
+    errCtx := m.args[1].Context()
+    return nil, errCtx.NewError("Error: expected literal string, got " + args[1].TypeName())
+  }
+  fragmentPath := js.NewWord(fragmentPath_, args[1].Context())
+
+  fragmentConsts := make(map[string]values.Value)
+
+  if len(args) > 2 {
+    if fragmentConsts, err = prototypes.GetLiteralObjectMembers(args[2]); err != nil {
+      return nil, err
+    }
+  }
+
+  if transpileWebGLPassShader == nil {
+    panic("transpileWebGLPassShader not registered")
+  }
+
+  ctx := m.Context()
+  callerPath := ctx.Path()
+
+  var uniformTypes map[string]string
+  m.vertexSource, m.fragmentSource, uniformTypes, err = transpileWebGLPassShader(callerPath, fragmentPath, fragmentConsts)
+  if err != nil {
+    return nil, err
+  }
+
+  samplers := make([]string, 0)
+  uniforms := make(map[string]values.Value)
+  m.setters = make(map[string]string)
+
+  for name, typeName := range uniformTypes {
+    input, setter, err := webGLPassUniformInput(typeName, fragmentPath.Context())
+    if err != nil {
+      return nil, err
+    }
+
+    if input == nil {
+      samplers = append(samplers, name)
+    } else {
+      uniforms[name] = input
+    }
+
+    m.setters[name] = setter
+  }
+
+  sort.Strings(samplers)
+
+  return prototypes.NewShaderWebGLPass(samplers, uniforms, m.Context()), nil
+}
+
+func (m *WebGLPass) ResolveExpressionActivity(usage js.Usage) error {
+  ResolveHeaderActivity(webGLPassHeader, m.Context())
+
+  return m.Macro.ResolveExpressionActivity(usage)
+}
+
+func (m *WebGLPass) UniqueExpressionNames(ns js.Namespace) error {
+  if err := UniqueHeaderNames(webGLPassHeader, ns); err != nil {
+    return err
+  }
+
+  return m.Macro.UniqueExpressionNames(ns)
+}
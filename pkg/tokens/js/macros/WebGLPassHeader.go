@@ -0,0 +1,84 @@
+package macros
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+type WebGLPassHeader struct {
+  HeaderData
+}
+
+func (h *WebGLPassHeader) Dependencies() []Header {
+  return []Header{webGLProgramHeader}
+}
+
+// u maps uniform names to setter codes:
+//  't': sampler2D, 'c': samplerCube, 'mN': matN, otherwise the suffix of the gl.uniform* function
+func (h *WebGLPassHeader) Write() string {
+  b := NewHeaderBuilder()
+
+  b.n()
+
+  b.cccn("function ", h.Name(), "(gl,v,f,u){")
+  b.tcccn("let p=", webGLProgramHeader.Name(), "(gl,v,f);")
+  b.tcn("let b=gl.createBuffer();")
+  b.tcn("gl.bindBuffer(gl.ARRAY_BUFFER,b);")
+  b.tcn("gl.bufferData(gl.ARRAY_BUFFER,new Float32Array([-1,-1,1,-1,-1,1,1,1]),gl.STATIC_DRAW);")
+  b.tcn("let a=gl.getAttribLocation(p,'aPos');")
+  b.tcn("let l={};")
+  b.tcn("for(let k in u){l[k]=gl.getUniformLocation(p,k);}")
+
+  b.tcn("function alloc(t,w,h){")
+  b.ttcn("gl.bindTexture(gl.TEXTURE_2D,t.texture);")
+  b.ttcn("gl.texImage2D(gl.TEXTURE_2D,0,gl.RGBA,w,h,0,gl.RGBA,gl.UNSIGNED_BYTE,null);")
+  b.ttcn("t.width=w;t.height=h;")
+  b.tcn("}")
+
+  b.tcn("return {")
+  b.ttcn("program:p,")
+  b.ttcn("createTarget:function(w,h){")
+  b.tttcn("let t={texture:gl.createTexture(),framebuffer:gl.createFramebuffer(),width:0,height:0};")
+  b.tttcn("t.resize=function(w_,h_){alloc(t,w_,h_);};")
+  b.tttcn("alloc(t,w,h);")
+  b.tttcn("gl.texParameteri(gl.TEXTURE_2D,gl.TEXTURE_MIN_FILTER,gl.LINEAR);")
+  b.tttcn("gl.texParameteri(gl.TEXTURE_2D,gl.TEXTURE_MAG_FILTER,gl.LINEAR);")
+  b.tttcn("gl.texParameteri(gl.TEXTURE_2D,gl.TEXTURE_WRAP_S,gl.CLAMP_TO_EDGE);")
+  b.tttcn("gl.texParameteri(gl.TEXTURE_2D,gl.TEXTURE_WRAP_T,gl.CLAMP_TO_EDGE);")
+  b.tttcn("gl.bindFramebuffer(gl.FRAMEBUFFER,t.framebuffer);")
+  b.tttcn("gl.framebufferTexture2D(gl.FRAMEBUFFER,gl.COLOR_ATTACHMENT0,gl.TEXTURE_2D,t.texture,0);")
+  b.tttcn("gl.bindFramebuffer(gl.FRAMEBUFFER,null);")
+  b.tttcn("return t;")
+  b.ttcn("},")
+
+  b.ttcn("run:function(inputs,output){")
+  b.tttcn("gl.useProgram(p);")
+  b.tttcn("if(output===undefined){gl.bindFramebuffer(gl.FRAMEBUFFER,null);gl.viewport(0,0,gl.drawingBufferWidth,gl.drawingBufferHeight);}")
+  b.tttcn("else{gl.bindFramebuffer(gl.FRAMEBUFFER,output.framebuffer);gl.viewport(0,0,output.width,output.height);}")
+  b.tttcn("let unit=0;")
+  b.tttcn("for(let k in inputs){")
+  b.ttttcn("let x=inputs[k];let s=u[k];")
+  b.ttttcn("if(s=='t'||s=='c'){")
+  b.tttttcn("gl.activeTexture(gl.TEXTURE0+unit);")
+  b.tttttcn("gl.bindTexture(s=='t'?gl.TEXTURE_2D:gl.TEXTURE_CUBE_MAP,(x instanceof WebGLTexture)?x:x.texture);")
+  b.tttttcn("gl.uniform1i(l[k],unit);")
+  b.tttttcn("unit++;")
+  b.ttttcn("}else if(s[0]=='m'){gl['uniformMatrix'+s[1]+'fv'](l[k],false,x);}")
+  b.ttttcn("else{gl['uniform'+s](l[k],x);}")
+  b.tttcn("}")
+  b.tttcn("gl.bindBuffer(gl.ARRAY_BUFFER,b);")
+  b.tttcn("gl.enableVertexAttribArray(a);")
+  b.tttcn("gl.vertexAttribPointer(a,2,gl.FLOAT,false,0,0);")
+  b.tttcn("gl.drawArrays(gl.TRIANGLE_STRIP,0,4);")
+  b.ttcn("},")
+  b.tcn("};")
+  b.c("}")
+  b.n()
+
+  return b.String()
+}
+
+var webGLPassHeader = &WebGLPassHeader{newHeaderData("__newWebGLPass__")}
+
+func ActivateWebGLPassHeader() {
+  ResolveHeaderActivity(webGLPassHeader, context.NewDummyContext())
+}
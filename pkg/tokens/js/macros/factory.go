@@ -75,6 +75,7 @@ var _constructorMacros = map[string]MacroConstructor{
   "RPCClient": NewRPCClient,
   "RPCServer": NewRPCServer,
   "WebGLProgram": NewWebGLProgram,
+  "WebGLPass": NewWebGLPass,
 }
 
 var _statementMacros = map[string]StatementMacroConstructor{
@@ -193,6 +194,8 @@ func RegisterActivateMacroHeadersCallback() bool {
       ActivateCheckTypeHeader()
     case "WebGLProgram":
       ActivateWebGLProgramHeader()
+    case "WebGLPass":
+      ActivateWebGLPassHeader()
 		}
 	}
 
@@ -0,0 +1,119 @@
+package prototypes
+
+import (
+  "sort"
+
+  "github.com/wtsuite/wtsuite/pkg/tokens/js/values"
+
+  "github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+// fullscreen fragment shader pass, created by the WebGLPass macro
+type WebGLPass struct {
+  samplers []string // nil if the shader is unknown (eg. type annotations)
+  uniforms map[string]values.Value // non-sampler uniforms
+  BuiltinPrototype
+}
+
+func NewWebGLPassPrototype() values.Prototype {
+  return &WebGLPass{nil, nil, newBuiltinPrototype("WebGLPass")}
+}
+
+func NewWebGLPass(ctx context.Context) values.Value {
+  return values.NewInstance(NewWebGLPassPrototype(), ctx)
+}
+
+// the inputs of run() are checked against the samplers and uniforms of the shader
+func NewShaderWebGLPass(samplers []string, uniforms map[string]values.Value, ctx context.Context) values.Value {
+  return values.NewInstance(&WebGLPass{samplers, uniforms, newBuiltinPrototype("WebGLPass")}, ctx)
+}
+
+func (p *WebGLPass) Check(other_ values.Interface, ctx context.Context) error {
+  if _, ok := other_.(*WebGLPass); ok {
+    return nil
+  } else {
+    return checkParent(p, other_, ctx)
+  }
+}
+
+func (p *WebGLPass) isSampler(name string) bool {
+  for _, s := range p.samplers {
+    if s == name {
+      return true
+    }
+  }
+
+  return false
+}
+
+func (p *WebGLPass) checkInputs(inputs_ values.Value, ctx context.Context) error {
+  if p.samplers == nil {
+    return nil
+  }
+
+  inputs, err := GetLiteralObjectMembers(inputs_)
+  if err != nil {
+    return err
+  }
+
+  keys := make([]string, 0)
+  for k, _ := range inputs {
+    keys = append(keys, k)
+  }
+  sort.Strings(keys)
+
+  for _, k := range keys {
+    input := inputs[k]
+
+    if p.isSampler(k) {
+      if !IsWebGLRenderTarget(input) && NewWebGLTexture(ctx).Check(input, ctx) != nil {
+        errCtx := input.Context()
+        return errCtx.NewError("Error: sampler " + k + " expects WebGLTexture or WebGLRenderTarget, got " + input.TypeName())
+      }
+    } else if checkVal, ok := p.uniforms[k]; ok {
+      if err := checkVal.Check(input, input.Context()); err != nil {
+        return err
+      }
+    } else {
+      errCtx := input.Context()
+      return errCtx.NewError("Error: shader doesn't have a uniform named " + k)
+    }
+  }
+
+  for _, s := range p.samplers {
+    if _, ok := inputs[s]; !ok {
+      errCtx := inputs_.Context()
+      return errCtx.NewError("Error: input for sampler " + s + " not set")
+    }
+  }
+
+  return nil
+}
+
+func (p *WebGLPass) GetInstanceMember(key string, includePrivate bool, ctx context.Context) (values.Value, error) {
+  i := NewInt(ctx)
+  o := NewObject(nil, ctx)
+  target := NewWebGLRenderTarget(ctx)
+
+  switch key {
+  case "program":
+    return NewWebGLProgram(ctx), nil
+  case "createTarget":
+    return values.NewFunction([]values.Value{i, i, target}, ctx), nil
+  case "run":
+    // without output the pass renders to the canvas
+    return values.NewOverloadedCustomFunction([][]values.Value{
+      []values.Value{o},
+      []values.Value{o, target},
+    }, func(args []values.Value, preferMethod bool, ctx_ context.Context) (values.Value, error) {
+      return nil, p.checkInputs(args[0], ctx_)
+    }, ctx), nil
+  default:
+    return nil, nil
+  }
+}
+
+func (p *WebGLPass) GetClassValue() (*values.Class, error) {
+  ctx := p.Context()
+  return values.NewUnconstructableClass(NewWebGLPassPrototype(), ctx), nil
+}
@@ -0,0 +1,56 @@
+package prototypes
+
+import (
+  "github.com/wtsuite/wtsuite/pkg/tokens/js/values"
+
+  "github.com/wtsuite/wtsuite/pkg/tokens/context"
+)
+
+// texture with attached framebuffer, created by WebGLPass.createTarget
+type WebGLRenderTarget struct {
+  BuiltinPrototype
+}
+
+func NewWebGLRenderTargetPrototype() values.Prototype {
+  return &WebGLRenderTarget{newBuiltinPrototype("WebGLRenderTarget")}
+}
+
+func NewWebGLRenderTarget(ctx context.Context) values.Value {
+  return values.NewInstance(NewWebGLRenderTargetPrototype(), ctx)
+}
+
+func (p *WebGLRenderTarget) Check(other_ values.Interface, ctx context.Context) error {
+  if _, ok := other_.(*WebGLRenderTarget); ok {
+    return nil
+  } else {
+    return checkParent(p, other_, ctx)
+  }
+}
+
+func IsWebGLRenderTarget(v values.Value) bool {
+  ctx := context.NewDummyContext()
+
+  checkVal := NewWebGLRenderTarget(ctx)
+
+  return checkVal.Check(v, ctx) == nil
+}
+
+func (p *WebGLRenderTarget) GetInstanceMember(key string, includePrivate bool, ctx context.Context) (values.Value, error) {
+  i := NewInt(ctx)
+
+  switch key {
+  case "texture":
+    return NewWebGLTexture(ctx), nil
+  case "width", "height":
+    return i, nil
+  case "resize":
+    return values.NewFunction([]values.Value{i, i, nil}, ctx), nil
+  default:
+    return nil, nil
+  }
+}
+
+func (p *WebGLRenderTarget) GetClassValue() (*values.Class, error) {
+  ctx := p.Context()
+  return values.NewUnconstructableClass(NewWebGLRenderTargetPrototype(), ctx), nil
+}
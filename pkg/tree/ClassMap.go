@@ -10,6 +10,7 @@ type ClassMap interface {
 	AppendTag(class string, t VisibleTag)
 
 	HasScriptClass(class string) bool
+	AppendScriptClass(class *tokens.String)
 }
 
 type ClassMapData struct {
@@ -47,3 +48,7 @@ func (m *ClassMapData) AppendTag(class string, t VisibleTag) {
 
 	m.tags[class] = append(m.tags[class], t)
 }
+
+func (m *ClassMapData) AppendScriptClass(class *tokens.String) {
+	m.scriptClasses[class.Value()] = class
+}
@@ -91,6 +91,29 @@ func (t *HTML) CollectIDs(idMap IDMap) error {
 	return body.CollectIDs(idMap)
 }
 
+// style links must be inserted before any other style tag, but not necessarily before any other link tag
+func insertStyleLink(head Tag, linkTags ...Tag) error {
+	iInsert := -1
+	for i, childTag := range head.Children() {
+		if childTag.Name() == "style" {
+			iInsert = i
+		}
+	}
+
+	for _, linkTag := range linkTags {
+		if iInsert < 0 {
+			head.AppendChild(linkTag)
+		} else {
+			if err := head.InsertChild(iInsert, linkTag); err != nil {
+				return err
+			}
+			iInsert += 1
+		}
+	}
+
+	return nil
+}
+
 func (t *HTML) LinkStyle(cssUrl string) error {
 	head, _, err := t.getHeadBody()
 	if err != nil {
@@ -105,24 +128,71 @@ func (t *HTML) LinkStyle(cssUrl string) error {
       return err
     }
 
-    // must be inserted before any other style tag, but not necessarily before any other link tag
-    iInsert := -1
-    for i, childTag := range head.Children() {
-      if childTag.Name() == "style" {
-        iInsert = i
-      }
-    }
-
-    if iInsert < 0 {
-      head.AppendChild(linkTag)
-    } else {
-      head.InsertChild(iInsert, linkTag)
-    }
+    return insertStyleLink(head, linkTag)
   }
 
   return nil
 }
 
+// the sheet is preloaded and only applied once loaded, so it doesn't block the first paint
+// the noscript fallback links it normally
+func (t *HTML) LinkStyleAsync(cssUrl string) error {
+	head, _, err := t.getHeadBody()
+	if err != nil {
+		return err
+	}
+
+	ctx := t.Context()
+	attr := tokens.NewEmptyStringDict(ctx)
+	attr.Set(tokens.NewValueString("rel", ctx), tokens.NewValueString("preload", ctx))
+	attr.Set(tokens.NewValueString("as", ctx), tokens.NewValueString("style", ctx))
+	attr.Set(tokens.NewValueString("href", ctx), tokens.NewValueString(cssUrl, ctx))
+	attr.Set(tokens.NewValueString("onload", ctx), tokens.NewValueString("this.onload=null;this.rel='stylesheet'", ctx))
+
+	preloadTag, err := NewLink(attr, ctx)
+	if err != nil {
+		return err
+	}
+
+	linkTag, err := NewStyleSheetLink(cssUrl, ctx)
+	if err != nil {
+		return err
+	}
+
+	noscriptTag, err := NewGeneric("noscript", tokens.NewEmptyStringDict(ctx), false, ctx)
+	if err != nil {
+		return err
+	}
+
+	noscriptTag.AppendChild(linkTag)
+
+	return insertStyleLink(head, preloadTag, noscriptTag)
+}
+
+// inserted before the first linked sheet, so the linked sheets and the style tags of the page take precedence
+func (t *HTML) IncludeCriticalStyle(css string) error {
+	head, _, err := t.getHeadBody()
+	if err != nil {
+		return err
+	}
+
+	ctx := head.Context()
+	styleTag, err := NewStyle(tokens.NewEmptyStringDict(ctx), css, ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, childTag := range head.Children() {
+		if _, ok := childTag.(*Link); ok {
+			if rel, ok := getStringAttr(childTag, "rel"); ok && (rel == "stylesheet" || rel == "preload") {
+				return head.InsertChild(i, styleTag)
+			}
+		}
+	}
+
+	return insertStyleLink(head, styleTag)
+}
+
 func (t *HTML) LinkScriptBundle(bundleURL string, fNames []string) error {
 	head, body, err := t.getHeadBody()
 	if err != nil {
@@ -152,6 +222,85 @@ func (t *HTML) LinkScriptBundle(bundleURL string, fNames []string) error {
 	return nil
 }
 
+func (t *HTML) LinkAlternate(href string, hreflang string) error {
+	head, _, err := t.getHeadBody()
+	if err != nil {
+		return err
+	}
+
+	linkTag, err := NewAlternateLink(href, hreflang, head.Context())
+	if err != nil {
+		return err
+	}
+
+	head.AppendChild(linkTag)
+
+	return nil
+}
+
+// sets the integrity attribute of the linked script or style sheet with the given url
+func (t *HTML) SetIntegrity(url string, integrity string) error {
+	head, _, err := t.getHeadBody()
+	if err != nil {
+		return err
+	}
+
+	// asynchronously linked sheets also have a noscript fallback
+	found := false
+	var setIntegrity func(children []Tag)
+	setIntegrity = func(children []Tag) {
+		for _, child := range children {
+			switch c := child.(type) {
+			case *SrcScript:
+				if c.Src() == url {
+					c.SetIntegrity(integrity)
+					found = true
+				}
+			case *Link:
+				if href, ok := c.attributes.Get("href"); ok && tokens.IsString(href) {
+					if hrefStr, err := tokens.AssertString(href); err == nil && hrefStr.Value() == url {
+						c.attributes.Set(tokens.NewValueString("integrity", c.Context()), tokens.NewValueString(integrity, c.Context()))
+						found = true
+					}
+				}
+			case *Generic:
+				if c.Name() == "noscript" {
+					setIntegrity(c.Children())
+				}
+			}
+		}
+	}
+
+	setIntegrity(head.Children())
+
+	if !found {
+		errCtx := t.Context()
+		return errCtx.NewError("Error: " + url + " not linked")
+	}
+
+	return nil
+}
+
+// inserted as the first tag of the head, so the policy applies to everything that follows
+func (t *HTML) SetContentSecurityPolicy(policy string) error {
+	head, _, err := t.getHeadBody()
+	if err != nil {
+		return err
+	}
+
+	ctx := head.Context()
+	attr := tokens.NewEmptyStringDict(ctx)
+	attr.Set(tokens.NewValueString("http-equiv", ctx), tokens.NewValueString("Content-Security-Policy", ctx))
+	attr.Set(tokens.NewValueString("content", ctx), tokens.NewValueString(policy, ctx))
+
+	meta, err := NewMeta(attr, ctx)
+	if err != nil {
+		return err
+	}
+
+	return head.InsertChild(0, meta)
+}
+
 func (t *HTML) IncludeStyle(style string) error {
 	head, _, err := t.getHeadBody()
   if err != nil {
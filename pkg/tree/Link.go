@@ -46,6 +46,17 @@ func NewStyleSheetLink(relPath string, ctx context.Context) (Tag, error) {
 	return NewLink(attr, ctx)
 }
 
+// <link rel="alternate" hreflang="fr" href="...">
+func NewAlternateLink(href string, hreflang string, ctx context.Context) (Tag, error) {
+	attr := tokens.NewEmptyStringDict(ctx)
+
+	attr.Set(tokens.NewValueString("rel", ctx), tokens.NewValueString("alternate", ctx))
+	attr.Set(tokens.NewValueString("hreflang", ctx), tokens.NewValueString(hreflang, ctx))
+	attr.Set(tokens.NewValueString("href", ctx), tokens.NewValueString(href, ctx))
+
+	return NewLink(attr, ctx)
+}
+
 func NewLink(attr *tokens.StringDict, ctx context.Context) (Tag, error) {
 	td, err := newTag("link", true, attr, ctx)
 	if err != nil {
@@ -114,6 +114,24 @@ func (t *Root) LinkStyle(cssUrl string) error {
 	return html.LinkStyle(cssUrl)
 }
 
+func (t *Root) LinkStyleAsync(cssUrl string) error {
+	_, html, err := t.GetDocTypeAndHTML()
+	if err != nil {
+		return err
+	}
+
+	return html.LinkStyleAsync(cssUrl)
+}
+
+func (t *Root) IncludeCriticalStyle(css string) error {
+	_, html, err := t.GetDocTypeAndHTML()
+	if err != nil {
+		return err
+	}
+
+	return html.IncludeCriticalStyle(css)
+}
+
 func (t *Root) LinkScriptBundle(bundleURL string, fNames []string) error {
 	_, html, err := t.GetDocTypeAndHTML()
 	if err != nil {
@@ -123,6 +141,33 @@ func (t *Root) LinkScriptBundle(bundleURL string, fNames []string) error {
 	return html.LinkScriptBundle(bundleURL, fNames)
 }
 
+func (t *Root) LinkAlternate(href string, hreflang string) error {
+	_, html, err := t.GetDocTypeAndHTML()
+	if err != nil {
+		return err
+	}
+
+	return html.LinkAlternate(href, hreflang)
+}
+
+func (t *Root) SetIntegrity(url string, integrity string) error {
+	_, html, err := t.GetDocTypeAndHTML()
+	if err != nil {
+		return err
+	}
+
+	return html.SetIntegrity(url, integrity)
+}
+
+func (t *Root) SetContentSecurityPolicy(policy string) error {
+	_, html, err := t.GetDocTypeAndHTML()
+	if err != nil {
+		return err
+	}
+
+	return html.SetContentSecurityPolicy(policy)
+}
+
 func (t *Root) IncludeStyle(styles string) error {
 	_, html, err := t.GetDocTypeAndHTML()
 	if err != nil {
@@ -7,12 +7,21 @@ import (
 )
 
 type SrcScript struct {
-	src string
+	src       string
+	integrity string // subresource integrity, eg. "sha384-...", can be empty
 	LeafTag
 }
 
 func NewSrcScript(src string, ctx context.Context) (*SrcScript, error) {
-	return &SrcScript{src, NewLeafTag(ctx)}, nil
+	return &SrcScript{src, "", NewLeafTag(ctx)}, nil
+}
+
+func (t *SrcScript) Src() string {
+	return t.src
+}
+
+func (t *SrcScript) SetIntegrity(integrity string) {
+	t.integrity = integrity
 }
 
 func (t *SrcScript) Write(indent string, nl, tab string) string {
@@ -21,7 +30,15 @@ func (t *SrcScript) Write(indent string, nl, tab string) string {
 	b.WriteString(indent)
 	b.WriteString("<script type=\"text/javascript\" src=\"")
 	b.WriteString(t.src)
-	b.WriteString("\"></script>")
+	b.WriteString("\"")
+
+	if t.integrity != "" {
+		b.WriteString(" integrity=\"")
+		b.WriteString(t.integrity)
+		b.WriteString("\"")
+	}
+
+	b.WriteString("></script>")
 
 	return b.String()
 }
@@ -1,6 +1,8 @@
 package tree
 
 import (
+	"strings"
+
 	"github.com/wtsuite/wtsuite/pkg/tokens/context"
 	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
 )
@@ -41,3 +43,31 @@ func (t *Title) Content() string {
 
   return ""
 }
+
+// html -> head -> title, empty if the page doesn't have a title
+func HeadTitle(root *Root) string {
+	for _, rootChild := range root.Children() {
+		if rootChild.Name() == "html" {
+			for _, htmlChild := range rootChild.Children() {
+				if htmlChild.Name() == "head" {
+					for _, headChild := range htmlChild.Children() {
+						if titleTag, ok := headChild.(*Title); ok {
+							return titleTag.Content()
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// page titles often end with the site name (eg. "Post | Blog" -> "Post")
+func ShortTitle(title string) string {
+	if strings.Contains(title, "|") {
+		title = strings.Split(title, "|")[0]
+	}
+
+	return strings.TrimSpace(title)
+}
@@ -0,0 +1,389 @@
+package tree
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+	"github.com/wtsuite/wtsuite/pkg/tokens/raw"
+)
+
+// accessibility lint of a built tree, every problem is reported as an error with the context of the offending tag
+// sheetDecls are the declarations of the (simple) style sheet rules that match each tag (see styles.Sheet.MatchDeclarations)
+// inline style attributes override the sheet declarations
+// colors are only checked if they are computable, i.e. hex colors, rgb() or common color names
+func LintA11y(root *Root, sheetDecls map[Tag]map[string]tokens.Token) []error {
+	l := &a11yLinter{
+		errs:       make([]error, 0),
+		sheetDecls: sheetDecls,
+		ids:        make(map[string]Tag),
+		labelFors:  make(map[string]bool),
+		controls:   make([]a11yControl, 0),
+		prevLevel:  0,
+	}
+
+	for _, child := range root.Children() {
+		l.walk(child, []Tag{})
+	}
+
+	for _, c := range l.controls {
+		if c.id != "" && l.labelFors[c.id] {
+			continue
+		}
+
+		errCtx := c.tag.Context()
+		l.errs = append(l.errs, errCtx.NewError("A11y Error: form control without label (hint: use label(for=...), wrap in a label, or set aria-label)"))
+	}
+
+	return l.errs
+}
+
+type a11yControl struct {
+	tag Tag
+	id  string
+}
+
+type a11yLinter struct {
+	errs       []error
+	sheetDecls map[Tag]map[string]tokens.Token
+	ids        map[string]Tag
+	labelFors  map[string]bool
+	controls   []a11yControl // controls that aren't labelled in another way
+	prevLevel  int           // previous heading level
+}
+
+func (l *a11yLinter) addError(t Tag, msg string) {
+	errCtx := t.Context()
+	l.errs = append(l.errs, errCtx.NewError("A11y Error: "+msg))
+}
+
+func (l *a11yLinter) walk(t Tag, ancestors []Tag) {
+	if _, ok := t.(*Text); ok {
+		return
+	}
+
+	if id := t.GetID(); id != "" {
+		if other, ok := l.ids[id]; ok {
+			errCtx := t.Context()
+			err := errCtx.NewError("A11y Error: duplicate id " + id)
+			context.PrependContextString(err, "Info: also defined here", other.Context())
+			l.errs = append(l.errs, err)
+		} else {
+			l.ids[id] = t
+		}
+	}
+
+	switch name := t.Name(); name {
+	case "html":
+		if lang, ok := getStringAttr(t, "lang"); !ok || lang == "" {
+			l.addError(t, "html without lang attribute")
+		}
+	case "img", "area":
+		if _, ok := getStringAttr(t, "alt"); !ok {
+			l.addError(t, name+" without alt attribute (use alt=\"\" for decorative images)")
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(name[1] - '0')
+		if l.prevLevel != 0 && level > l.prevLevel+1 {
+			l.addError(t, "heading level skipped (h"+strconv.Itoa(l.prevLevel)+" followed by "+name+")")
+		}
+
+		l.prevLevel = level
+	case "label":
+		if target, ok := getStringAttr(t, "for"); ok {
+			l.labelFors[target] = true
+		}
+	case "input", "select", "textarea":
+		l.lintControl(t, ancestors)
+	}
+
+	l.lintContrast(t, ancestors)
+
+	ancestors = append(ancestors, t)
+	for _, child := range t.Children() {
+		l.walk(child, ancestors)
+	}
+}
+
+func (l *a11yLinter) lintControl(t Tag, ancestors []Tag) {
+	if t.Name() == "input" {
+		typ, _ := getStringAttr(t, "type")
+		switch typ {
+		case "hidden", "submit", "reset", "button":
+			return
+		case "image":
+			if _, ok := getStringAttr(t, "alt"); !ok {
+				l.addError(t, "image input without alt attribute")
+			}
+			return
+		}
+	}
+
+	for _, name := range []string{"aria-label", "aria-labelledby", "title"} {
+		if v, ok := getStringAttr(t, name); ok && v != "" {
+			return
+		}
+	}
+
+	for _, a := range ancestors {
+		if a.Name() == "label" {
+			return
+		}
+	}
+
+	// labels with for= can come later in the document
+	l.controls = append(l.controls, a11yControl{t, t.GetID()})
+}
+
+// declarations of the inline style attribute, or the matching sheet rules
+func (l *a11yLinter) styleDecl(t Tag, prop string) (tokens.Token, bool) {
+	if attr := t.Attributes(); attr != nil {
+		if style_, ok := attr.Get("style"); ok {
+			switch {
+			case tokens.IsStringDict(style_):
+				style, _ := tokens.AssertStringDict(style_)
+				if v, ok := style.Get(prop); ok {
+					return v, true
+				}
+			case tokens.IsString(style_):
+				style, _ := tokens.AssertString(style_)
+				for _, decl := range strings.Split(style.Value(), ";") {
+					if i := strings.Index(decl, ":"); i != -1 && strings.TrimSpace(decl[0:i]) == prop {
+						return tokens.NewValueString(strings.TrimSpace(decl[i+1:]), style.Context()), true
+					}
+				}
+			}
+		}
+	}
+
+	if decls, ok := l.sheetDecls[t]; ok {
+		if v, ok := decls[prop]; ok {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+func (l *a11yLinter) styleColor(t Tag, props ...string) (*tokens.Color, bool) {
+	for _, prop := range props {
+		if v, ok := l.styleDecl(t, prop); ok {
+			if c, ok := computableColor(v); ok {
+				return c, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (l *a11yLinter) lintContrast(t Tag, ancestors []Tag) {
+	hasText := false
+	for _, child := range t.Children() {
+		if text, ok := child.(*Text); ok && strings.TrimSpace(text.Value()) != "" {
+			hasText = true
+			break
+		}
+	}
+
+	if !hasText {
+		return
+	}
+
+	// nearest explicit colors, defaults are black on white
+	var fg, bg *tokens.Color = nil, nil
+	explicit := false
+	for i := len(ancestors); i >= 0; i-- {
+		tag := t
+		if i < len(ancestors) {
+			tag = ancestors[i]
+		}
+
+		if fg == nil {
+			if c, ok := l.styleColor(tag, "color"); ok {
+				fg = c
+				explicit = true
+			}
+		}
+
+		if bg == nil {
+			if c, ok := l.styleColor(tag, "background-color", "background"); ok {
+				bg = c
+				explicit = true
+			}
+		}
+	}
+
+	if !explicit {
+		return
+	}
+
+	ctx := t.Context()
+	if bg == nil {
+		bg = tokens.NewValueColor(255, 255, 255, 255, ctx)
+	}
+
+	if fg == nil {
+		fg = tokens.NewValueColor(0, 0, 0, 255, ctx)
+	}
+
+	ratio := ColorContrast(fg, bg)
+
+	minRatio := 4.5
+	if size, ok := l.fontSizePx(t, ancestors); ok && size >= 24.0 {
+		minRatio = 3.0
+	}
+
+	if ratio < minRatio {
+		l.addError(t, "low color contrast "+strconv.FormatFloat(ratio, 'f', 2, 64)+":1 (expected at least "+strconv.FormatFloat(minRatio, 'f', 1, 64)+":1)")
+	}
+}
+
+var _pxRegexp = regexp.MustCompile(`^([0-9]*\.?[0-9]+)px$`)
+
+func (l *a11yLinter) fontSizePx(t Tag, ancestors []Tag) (float64, bool) {
+	for i := len(ancestors); i >= 0; i-- {
+		tag := t
+		if i < len(ancestors) {
+			tag = ancestors[i]
+		}
+
+		if v, ok := l.styleDecl(tag, "font-size"); ok {
+			if m := _pxRegexp.FindStringSubmatch(strings.TrimSpace(tokenCSSString(v))); m != nil {
+				f, err := strconv.ParseFloat(m[1], 64)
+				return f, err == nil
+			}
+
+			return 0.0, false
+		}
+	}
+
+	return 0.0, false
+}
+
+func tokenCSSString(t tokens.Token) string {
+	switch v := t.(type) {
+	case *tokens.String:
+		return v.Value()
+	case *tokens.Int, *tokens.Float, *tokens.Color:
+		return v.(tokens.Primitive).Write()
+	default:
+		return ""
+	}
+}
+
+var _rgbRegexp = regexp.MustCompile(`^rgba?\(\s*([0-9]+)\s*,\s*([0-9]+)\s*,\s*([0-9]+)\s*(?:,\s*([0-9]*\.?[0-9]+)\s*)?\)$`)
+
+var _namedColors = map[string][3]int{
+	"black":   {0, 0, 0},
+	"white":   {255, 255, 255},
+	"gray":    {128, 128, 128},
+	"grey":    {128, 128, 128},
+	"silver":  {192, 192, 192},
+	"red":     {255, 0, 0},
+	"maroon":  {128, 0, 0},
+	"yellow":  {255, 255, 0},
+	"olive":   {128, 128, 0},
+	"lime":    {0, 255, 0},
+	"green":   {0, 128, 0},
+	"aqua":    {0, 255, 255},
+	"teal":    {0, 128, 128},
+	"blue":    {0, 0, 255},
+	"navy":    {0, 0, 128},
+	"fuchsia": {255, 0, 255},
+	"purple":  {128, 0, 128},
+	"orange":  {255, 165, 0},
+}
+
+func computableColor(t tokens.Token) (*tokens.Color, bool) {
+	if tokens.IsColor(t) {
+		c, err := tokens.AssertColor(t)
+		return c, err == nil
+	}
+
+	if !tokens.IsString(t) {
+		return nil, false
+	}
+
+	s_, _ := tokens.AssertString(t)
+	s := strings.ToLower(strings.TrimSpace(s_.Value()))
+	ctx := s_.Context()
+
+	switch {
+	case strings.HasPrefix(s, "#"):
+		c, err := raw.NewLiteralColor(s, ctx)
+		if err != nil {
+			return nil, false
+		}
+
+		r, g, b, a := c.Values()
+		return tokens.NewValueColor(r, g, b, a, ctx), true
+	case _rgbRegexp.MatchString(s):
+		m := _rgbRegexp.FindStringSubmatch(s)
+		r, _ := strconv.Atoi(m[1])
+		g, _ := strconv.Atoi(m[2])
+		b, _ := strconv.Atoi(m[3])
+		a := 255
+		if m[4] != "" {
+			af, _ := strconv.ParseFloat(m[4], 64)
+			a = int(math.Round(af * 255))
+		}
+
+		return tokens.NewValueColor(r, g, b, a, ctx), true
+	default:
+		if rgb, ok := _namedColors[s]; ok {
+			return tokens.NewValueColor(rgb[0], rgb[1], rgb[2], 255, ctx), true
+		}
+
+		return nil, false
+	}
+}
+
+// WCAG contrast ratio, the background is blended over white and the foreground over the background
+func ColorContrast(fg *tokens.Color, bg *tokens.Color) float64 {
+	white := []float64{1.0, 1.0, 1.0}
+	bgRGB := blendColor(bg, white)
+	fgRGB := blendColor(fg, bgRGB)
+
+	return contrastRatio(fgRGB, bgRGB)
+}
+
+// alpha blending over an opaque background, result components are in [0, 1]
+func blendColor(c *tokens.Color, bg []float64) []float64 {
+	r, g, b, a := c.Values()
+	alpha := float64(a) / 255.0
+
+	res := make([]float64, 3)
+	for i, x := range []int{r, g, b} {
+		res[i] = alpha*float64(x)/255.0 + (1.0-alpha)*bg[i]
+	}
+
+	return res
+}
+
+// WCAG relative luminance
+func relativeLuminance(rgb []float64) float64 {
+	lin := func(x float64) float64 {
+		if x <= 0.03928 {
+			return x / 12.92
+		}
+
+		return math.Pow((x+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*lin(rgb[0]) + 0.7152*lin(rgb[1]) + 0.0722*lin(rgb[2])
+}
+
+func contrastRatio(a []float64, b []float64) float64 {
+	la := relativeLuminance(a)
+	lb := relativeLuminance(b)
+
+	if la < lb {
+		la, lb = lb, la
+	}
+
+	return (la + 0.05) / (lb + 0.05)
+}
@@ -0,0 +1,356 @@
+package tree
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	tokens "github.com/wtsuite/wtsuite/pkg/tokens/html"
+)
+
+// HTML5 content categories
+const (
+	cmMetadata = 1 << iota
+	cmFlow
+	cmSectioning
+	cmHeading
+	cmPhrasing
+	cmEmbedded
+	cmScriptSupporting // permitted everywhere
+)
+
+type contentModel struct {
+	categories    int      // categories of the element itself
+	content       int      // categories permitted as children
+	children      []string // elements permitted as children in addition to content
+	text          bool     // text is permitted without phrasing content (eg. title, option)
+	transparent   bool     // the content model of the parent applies instead
+	opaque        bool     // children and attributes aren't validated (eg. svg)
+	parents       []string // permitted parents, empty for any parent
+	excludes      []string // elements that can't be descendants
+	noInteractive bool     // interactive content can't be descendants
+	attributes    []string // element specific attributes
+}
+
+var (
+	_cmHeadings  = []string{"h1", "h2", "h3", "h4", "h5", "h6", "hgroup"}
+	_cmSections  = []string{"article", "aside", "nav", "section"}
+	_cmFlowPhr   = cmFlow | cmPhrasing
+	_cmFlowPhrEm = cmFlow | cmPhrasing | cmEmbedded
+	_cmHeaderish = append(append([]string{"header", "footer"}, _cmHeadings...), _cmSections...)
+	_cmMediaAttr = []string{"src", "crossorigin", "preload", "autoplay", "loop", "muted", "controls"}
+	_cmFormAttr  = []string{"form", "formaction", "formenctype", "formmethod", "formnovalidate", "formtarget", "name", "disabled", "popovertarget", "popovertargetaction"}
+	_cmCellAttr  = []string{"colspan", "rowspan", "headers"}
+)
+
+// content models of the elements in the factory table, elements that aren't listed aren't validated
+var _contentModels = map[string]contentModel{
+	"a":          {categories: _cmFlowPhr, transparent: true, noInteractive: true, attributes: []string{"href", "target", "download", "ping", "rel", "hreflang", "type", "referrerpolicy"}},
+	"abbr":       {categories: _cmFlowPhr, content: cmPhrasing},
+	"address":    {categories: cmFlow, content: cmFlow, excludes: append([]string{"address"}, _cmHeaderish...)},
+	"area":       {categories: _cmFlowPhr, attributes: []string{"alt", "coords", "shape", "href", "target", "download", "ping", "rel", "referrerpolicy"}},
+	"article":    {categories: cmFlow | cmSectioning, content: cmFlow},
+	"aside":      {categories: cmFlow | cmSectioning, content: cmFlow},
+	"audio":      {categories: _cmFlowPhrEm, children: []string{"source", "track"}, transparent: true, attributes: _cmMediaAttr},
+	"b":          {categories: _cmFlowPhr, content: cmPhrasing},
+	"base":       {categories: cmMetadata, parents: []string{"head"}, attributes: []string{"href", "target"}},
+	"bdi":        {categories: _cmFlowPhr, content: cmPhrasing},
+	"bdo":        {categories: _cmFlowPhr, content: cmPhrasing},
+	"blockquote": {categories: cmFlow, content: cmFlow, attributes: []string{"cite"}},
+	"body":       {content: cmFlow, parents: []string{"html"}},
+	"br":         {categories: _cmFlowPhr},
+	"button":     {categories: _cmFlowPhr, content: cmPhrasing, noInteractive: true, attributes: append([]string{"type", "value"}, _cmFormAttr...)},
+	"canvas":     {categories: _cmFlowPhrEm, transparent: true, attributes: []string{"width", "height"}},
+	"caption":    {content: cmFlow, parents: []string{"table"}, excludes: []string{"table"}},
+	"cite":       {categories: _cmFlowPhr, content: cmPhrasing},
+	"code":       {categories: _cmFlowPhr, content: cmPhrasing},
+	"col":        {parents: []string{"colgroup", "table"}, attributes: []string{"span"}},
+	"colgroup":   {children: []string{"col"}, parents: []string{"table"}, attributes: []string{"span"}},
+	"data":       {categories: _cmFlowPhr, content: cmPhrasing, attributes: []string{"value"}},
+	"datalist":   {categories: _cmFlowPhr, content: cmPhrasing, children: []string{"option"}},
+	"dd":         {content: cmFlow, parents: []string{"dl", "div"}},
+	"del":        {categories: _cmFlowPhr, transparent: true, attributes: []string{"cite", "datetime"}},
+	"details":    {categories: cmFlow, content: cmFlow, children: []string{"summary"}, attributes: []string{"open", "name"}},
+	"dfn":        {categories: _cmFlowPhr, content: cmPhrasing, excludes: []string{"dfn"}},
+	"dialog":     {categories: cmFlow, content: cmFlow, attributes: []string{"open"}},
+	"div":        {categories: cmFlow, content: cmFlow},
+	"dl":         {categories: cmFlow, children: []string{"dt", "dd", "div"}},
+	"dt":         {content: cmFlow, parents: []string{"dl", "div"}, excludes: _cmHeaderish},
+	"em":         {categories: _cmFlowPhr, content: cmPhrasing},
+	"embed":      {categories: _cmFlowPhrEm, attributes: []string{"src", "type", "width", "height"}},
+	"fieldset":   {categories: cmFlow, content: cmFlow, children: []string{"legend"}, attributes: []string{"disabled", "form", "name"}},
+	"figcaption": {content: cmFlow, parents: []string{"figure"}},
+	"figure":     {categories: cmFlow, content: cmFlow, children: []string{"figcaption"}},
+	"footer":     {categories: cmFlow, content: cmFlow, excludes: []string{"header", "footer"}},
+	"form":       {categories: cmFlow, content: cmFlow, excludes: []string{"form"}, attributes: []string{"accept-charset", "action", "autocomplete", "enctype", "method", "name", "novalidate", "rel", "target"}},
+	"h1":         {categories: cmFlow | cmHeading, content: cmPhrasing},
+	"h2":         {categories: cmFlow | cmHeading, content: cmPhrasing},
+	"h3":         {categories: cmFlow | cmHeading, content: cmPhrasing},
+	"h4":         {categories: cmFlow | cmHeading, content: cmPhrasing},
+	"h5":         {categories: cmFlow | cmHeading, content: cmPhrasing},
+	"h6":         {categories: cmFlow | cmHeading, content: cmPhrasing},
+	"head":       {content: cmMetadata, parents: []string{"html"}},
+	"header":     {categories: cmFlow, content: cmFlow, excludes: []string{"header", "footer"}},
+	"hgroup":     {categories: cmFlow | cmHeading, children: []string{"h1", "h2", "h3", "h4", "h5", "h6", "p"}},
+	"hr":         {categories: cmFlow},
+	"html":       {children: []string{"head", "body"}, attributes: []string{"manifest", "version"}},
+	"i":          {categories: _cmFlowPhr, content: cmPhrasing},
+	"iframe":     {categories: _cmFlowPhrEm, attributes: []string{"src", "srcdoc", "name", "sandbox", "allow", "allowfullscreen", "width", "height", "referrerpolicy", "loading", "frameborder"}},
+	"img":        {categories: _cmFlowPhrEm, attributes: []string{"alt", "src", "srcset", "sizes", "crossorigin", "usemap", "ismap", "width", "height", "referrerpolicy", "decoding", "loading", "fetchpriority"}},
+	"input":      {categories: _cmFlowPhr, attributes: append([]string{"accept", "alt", "autocomplete", "checked", "dirname", "height", "list", "max", "maxlength", "min", "minlength", "multiple", "pattern", "placeholder", "readonly", "required", "size", "src", "step", "type", "value", "width", "capture"}, _cmFormAttr...)},
+	"ins":        {categories: _cmFlowPhr, transparent: true, attributes: []string{"cite", "datetime"}},
+	"kbd":        {categories: _cmFlowPhr, content: cmPhrasing},
+	"label":      {categories: _cmFlowPhr, content: cmPhrasing, excludes: []string{"label"}, attributes: []string{"for", "form"}},
+	"legend":     {content: cmPhrasing | cmHeading, parents: []string{"fieldset"}},
+	"li":         {content: cmFlow, parents: []string{"ul", "ol", "menu"}, attributes: []string{"value"}},
+	"link":       {categories: cmMetadata | _cmFlowPhr, attributes: []string{"href", "crossorigin", "rel", "as", "media", "hreflang", "type", "sizes", "imagesrcset", "imagesizes", "referrerpolicy", "integrity", "color", "disabled", "fetchpriority", "blocking"}},
+	"main":       {categories: cmFlow, content: cmFlow},
+	"map":        {categories: _cmFlowPhr, children: []string{"area"}, transparent: true, attributes: []string{"name"}},
+	"mark":       {categories: _cmFlowPhr, content: cmPhrasing},
+	"meta":       {categories: cmMetadata | _cmFlowPhr, attributes: []string{"name", "http-equiv", "content", "charset", "property", "media"}},
+	"meter":      {categories: _cmFlowPhr, content: cmPhrasing, excludes: []string{"meter"}, attributes: []string{"value", "min", "max", "low", "high", "optimum"}},
+	"nav":        {categories: cmFlow | cmSectioning, content: cmFlow},
+	"noscript":   {categories: cmMetadata | _cmFlowPhr, transparent: true, excludes: []string{"noscript"}},
+	"object":     {categories: _cmFlowPhrEm, children: []string{"param"}, transparent: true, attributes: []string{"data", "type", "name", "form", "width", "height", "usemap"}},
+	"ol":         {categories: cmFlow, children: []string{"li"}, attributes: []string{"reversed", "start", "type"}},
+	"optgroup":   {children: []string{"option"}, parents: []string{"select"}, attributes: []string{"disabled", "label"}},
+	"option":     {text: true, parents: []string{"select", "datalist", "optgroup"}, attributes: []string{"disabled", "label", "selected", "value"}},
+	"output":     {categories: _cmFlowPhr, content: cmPhrasing, attributes: []string{"for", "form", "name"}},
+	"p":          {categories: cmFlow, content: cmPhrasing},
+	"param":      {parents: []string{"object"}, attributes: []string{"name", "value"}},
+	"picture":    {categories: _cmFlowPhrEm, children: []string{"source", "img"}},
+	"pre":        {categories: cmFlow, content: cmPhrasing},
+	"progress":   {categories: _cmFlowPhr, content: cmPhrasing, excludes: []string{"progress"}, attributes: []string{"value", "max"}},
+	"q":          {categories: _cmFlowPhr, content: cmPhrasing, attributes: []string{"cite"}},
+	"rp":         {text: true, parents: []string{"ruby"}},
+	"rt":         {content: cmPhrasing, parents: []string{"ruby"}},
+	"ruby":       {categories: _cmFlowPhr, content: cmPhrasing, children: []string{"rp", "rt"}},
+	"s":          {categories: _cmFlowPhr, content: cmPhrasing},
+	"samp":       {categories: _cmFlowPhr, content: cmPhrasing},
+	"section":    {categories: cmFlow | cmSectioning, content: cmFlow},
+	"select":     {categories: _cmFlowPhr, children: []string{"option", "optgroup", "hr"}, attributes: []string{"autocomplete", "disabled", "form", "multiple", "name", "required", "size"}},
+	"small":      {categories: _cmFlowPhr, content: cmPhrasing},
+	"source":     {parents: []string{"picture", "audio", "video"}, attributes: []string{"type", "src", "srcset", "sizes", "media", "width", "height"}},
+	"span":       {categories: _cmFlowPhr, content: cmPhrasing},
+	"strong":     {categories: _cmFlowPhr, content: cmPhrasing},
+	"style":      {categories: cmMetadata | cmFlow, text: true, attributes: []string{"media", "blocking"}},
+	"sub":        {categories: _cmFlowPhr, content: cmPhrasing},
+	"summary":    {content: cmPhrasing | cmHeading, parents: []string{"details"}},
+	"sup":        {categories: _cmFlowPhr, content: cmPhrasing},
+	"svg":        {categories: _cmFlowPhrEm, opaque: true},
+	"table":      {categories: cmFlow, children: []string{"caption", "colgroup", "thead", "tbody", "tfoot", "tr"}},
+	"tbody":      {children: []string{"tr"}, parents: []string{"table"}},
+	"td":         {content: cmFlow, parents: []string{"tr"}, attributes: _cmCellAttr},
+	"template":   {categories: cmMetadata | cmScriptSupporting, opaque: true, attributes: []string{"shadowrootmode"}},
+	"textarea":   {categories: _cmFlowPhr, text: true, attributes: []string{"autocomplete", "cols", "dirname", "disabled", "form", "maxlength", "minlength", "name", "placeholder", "readonly", "required", "rows", "wrap"}},
+	"tfoot":      {children: []string{"tr"}, parents: []string{"table"}},
+	"th":         {content: cmFlow, parents: []string{"tr"}, excludes: _cmHeaderish, attributes: append([]string{"scope", "abbr"}, _cmCellAttr...)},
+	"thead":      {children: []string{"tr"}, parents: []string{"table"}},
+	"time":       {categories: _cmFlowPhr, content: cmPhrasing, attributes: []string{"datetime"}},
+	"title":      {categories: cmMetadata, text: true, parents: []string{"head"}},
+	"tr":         {children: []string{"td", "th"}, parents: []string{"table", "thead", "tbody", "tfoot"}},
+	"track":      {parents: []string{"audio", "video"}, attributes: []string{"default", "kind", "label", "src", "srclang"}},
+	"u":          {categories: _cmFlowPhr, content: cmPhrasing},
+	"ul":         {categories: cmFlow, children: []string{"li"}},
+	"var":        {categories: _cmFlowPhr, content: cmPhrasing},
+	"video":      {categories: _cmFlowPhrEm, children: []string{"source", "track"}, transparent: true, attributes: append([]string{"poster", "width", "height", "playsinline"}, _cmMediaAttr...)},
+	"wbr":        {categories: _cmFlowPhr},
+}
+
+var _globalAttributes = map[string]bool{
+	"accesskey": true, "autocapitalize": true, "autofocus": true, "class": true, "contenteditable": true,
+	"dir": true, "draggable": true, "enterkeyhint": true, "hidden": true, "id": true, "inert": true,
+	"inputmode": true, "is": true, "itemid": true, "itemprop": true, "itemref": true, "itemscope": true,
+	"itemtype": true, "lang": true, "nonce": true, "part": true, "popover": true, "role": true, "slot": true,
+	"spellcheck": true, "style": true, "tabindex": true, "title": true, "translate": true, "xmlns": true,
+	"prefix": true, "property": true, "resource": true, "typeof": true, "vocab": true, // RDFa
+}
+
+// the permitted children of the element being validated
+type cmPermitted struct {
+	parent   string
+	content  int
+	children []string
+	text     bool
+}
+
+func (p cmPermitted) permits(name string, m contentModel) bool {
+	if m.categories&(p.content|cmScriptSupporting) != 0 {
+		return true
+	}
+
+	for _, c := range p.children {
+		if c == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m contentModel) permitted(name string) cmPermitted {
+	return cmPermitted{name, m.content, m.children, m.text || m.content&(cmFlow|cmPhrasing) != 0}
+}
+
+type cmViolation struct {
+	ctx     context.Context
+	msg     string
+	warning bool // unknown attributes are always warnings
+}
+
+type cmValidator struct {
+	violations []cmViolation
+}
+
+func (v *cmValidator) add(t Tag, msg string) {
+	v.violations = append(v.violations, cmViolation{t.Context(), msg, false})
+}
+
+func (v *cmValidator) warn(t Tag, msg string) {
+	v.violations = append(v.violations, cmViolation{t.Context(), msg, true})
+}
+
+func isInteractive(t Tag) bool {
+	switch t.Name() {
+	case "a", "button", "details", "embed", "iframe", "label", "select", "textarea":
+		return true
+	case "input":
+		typ, _ := getStringAttr(t, "type")
+		return typ != "hidden"
+	case "audio", "video":
+		_, ok := getStringAttr(t, "controls")
+		return ok
+	case "img":
+		_, ok := getStringAttr(t, "usemap")
+		return ok
+	default:
+		return false
+	}
+}
+
+func isPermittedAttribute(key string, m contentModel) bool {
+	if _globalAttributes[key] {
+		return true
+	}
+
+	if strings.HasPrefix(key, "data-") || strings.HasPrefix(key, "aria-") || strings.HasPrefix(key, "on") {
+		return true
+	}
+
+	// tags with href are converted to <a>
+	if AUTO_LINK && key == "href" {
+		return true
+	}
+
+	for _, a := range m.attributes {
+		if a == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (v *cmValidator) validateAttributes(t Tag, m contentModel) {
+	attr := t.Attributes()
+	if attr == nil {
+		return
+	}
+
+	attr.Loop(func(key *tokens.String, val tokens.Token, last bool) error {
+		if !tokens.IsNull(val) && !tokens.IsFalseBool(val) && !isPermittedAttribute(key.Value(), m) {
+			v.warn(t, "attribute "+key.Value()+" not permitted on <"+t.Name()+">")
+		}
+
+		return nil
+	})
+}
+
+func (v *cmValidator) validateChildren(t Tag, p cmPermitted, ancestors []Tag) {
+	for _, child := range t.Children() {
+		if text, ok := child.(*Text); ok {
+			if !p.text && strings.TrimSpace(text.Value()) != "" {
+				v.add(child, "text not permitted in <"+p.parent+">")
+			}
+
+			continue
+		}
+
+		name := child.Name()
+		if name == "" || strings.HasPrefix(name, "!") || strings.HasPrefix(name, "?") {
+			continue
+		}
+
+		m, ok := _contentModels[name]
+		if !ok {
+			v.validateChildren(child, cmPermitted{name, cmFlow | cmPhrasing, nil, true}, append(ancestors, child))
+			continue
+		}
+
+		if len(m.parents) > 0 && !stringInList(t.Name(), m.parents) {
+			v.add(child, "<"+name+"> must be a child of <"+strings.Join(m.parents, ">, <")+">")
+		} else if !p.permits(name, m) {
+			v.add(child, "<"+name+"> not permitted in <"+p.parent+">")
+		}
+
+		for _, a := range ancestors {
+			am := _contentModels[a.Name()]
+			if stringInList(name, am.excludes) || (am.noInteractive && isInteractive(child)) {
+				v.add(child, "<"+name+"> can't be a descendant of <"+a.Name()+">")
+				break
+			}
+		}
+
+		if m.opaque {
+			continue
+		}
+
+		v.validateAttributes(child, m)
+
+		childP := m.permitted(name)
+		if name == "div" && t.Name() == "dl" {
+			// groups of dt and dd
+			childP = cmPermitted{name, 0, []string{"dt", "dd"}, false}
+		} else if m.transparent {
+			childP = cmPermitted{p.parent, p.content, append(append([]string{}, p.children...), m.children...), p.text}
+		}
+
+		v.validateChildren(child, childP, append(ancestors, child))
+	}
+}
+
+func stringInList(s string, lst []string) bool {
+	for _, x := range lst {
+		if x == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validates the elements, their children and their attributes against the HTML5 content models
+// violations are errors, unless CONTENT_MODEL_WARNINGS is set, in which case they are printed as warnings
+// unknown attributes are always printed as warnings
+func (t *Root) ValidateContentModel() error {
+	v := &cmValidator{make([]cmViolation, 0)}
+
+	v.validateChildren(t, cmPermitted{"root", 0, []string{"html"}, false}, []Tag{})
+
+	for _, violation := range v.violations {
+		if CONTENT_MODEL_WARNINGS || violation.warning {
+			fmt.Fprintf(os.Stderr, "%s\n", violation.ctx.NewError("HTML Warning: "+violation.msg).Error())
+		}
+	}
+
+	if !CONTENT_MODEL_WARNINGS {
+		for _, violation := range v.violations {
+			if !violation.warning {
+				return violation.ctx.NewError("HTML Error: " + violation.msg)
+			}
+		}
+	}
+
+	return nil
+}
@@ -375,3 +375,4 @@ func (b *FileBundle) Finalize() error {
 }
 
 var _shaderTranspilerRegistered = macros.RegisterTranspileWebGLShaders(shaders.TranspileWebGLShaders)
+var _passTranspilerRegistered = macros.RegisterTranspileWebGLPassShader(shaders.TranspileWebGLPassShader)
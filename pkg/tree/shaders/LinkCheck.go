@@ -0,0 +1,127 @@
+package shaders
+
+import (
+  "sort"
+  "strconv"
+
+	"github.com/wtsuite/wtsuite/pkg/tokens/context"
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl"
+)
+
+// varyings must match in name, type and (explicit) precision, uniforms declared in both stages must have the same type
+// all problems are reported in a single error
+func CheckLink(vertex *ShaderBundle, fragment *ShaderBundle) error {
+  var result *context.ContextError = nil
+
+  appendError := func(err *context.ContextError) {
+    if result == nil {
+      result = err
+    } else {
+      result.AppendError(err)
+    }
+  }
+
+  vertexVaryings := make(map[string]*glsl.Varying)
+  if err := vertex.CollectVaryings(vertexVaryings); err != nil {
+    return err
+  }
+
+  fragmentVaryings := make(map[string]*glsl.Varying)
+  if err := fragment.CollectVaryings(fragmentVaryings); err != nil {
+    return err
+  }
+
+  for _, k := range sortedVaryingNames(vertexVaryings) {
+    v := vertexVaryings[k]
+    f, ok := fragmentVaryings[k]
+    if !ok {
+      errCtx := v.Context()
+      appendError(errCtx.NewError("Error: varying " + k + " not found in fragment shader"))
+      continue
+    }
+
+    if v.TypeName() != f.TypeName() {
+      errCtx := f.Context()
+      err := errCtx.NewError("Error: varying " + k + " has type " + f.TypeName() + 
+        " in fragment shader, but " + v.TypeName() + " in vertex shader")
+      err.AppendContextString("Info: declared in vertex shader here", v.Context())
+      appendError(err)
+    } else if v.Precision() != glsl.DEFAULTP && f.Precision() != glsl.DEFAULTP && v.Precision() != f.Precision() {
+      errCtx := f.Context()
+      err := errCtx.NewError("Error: varying " + k + " has precision " + glsl.PrecisionTypeToString(f.Precision()) + 
+        " in fragment shader, but " + glsl.PrecisionTypeToString(v.Precision()) + " in vertex shader")
+      err.AppendContextString("Info: declared in vertex shader here", v.Context())
+      appendError(err)
+    }
+  }
+
+  for _, k := range sortedVaryingNames(fragmentVaryings) {
+    if _, ok := vertexVaryings[k]; !ok {
+      f := fragmentVaryings[k]
+      errCtx := f.Context()
+      appendError(errCtx.NewError("Error: varying " + k + " not found in vertex shader"))
+    }
+  }
+
+  vertexUniforms := make(map[string]*glsl.Uniform)
+  if err := vertex.CollectUniforms(vertexUniforms); err != nil {
+    return err
+  }
+
+  fragmentUniforms := make(map[string]*glsl.Uniform)
+  if err := fragment.CollectUniforms(fragmentUniforms); err != nil {
+    return err
+  }
+
+  for _, k := range sortedUniformNames(vertexUniforms) {
+    v := vertexUniforms[k]
+    f, ok := fragmentUniforms[k]
+    if !ok {
+      continue
+    }
+
+    if v.TypeName() != f.TypeName() || v.Length() != f.Length() {
+      errCtx := f.Context()
+      err := errCtx.NewError("Error: uniform " + k + " declared as " + uniformTypeName(f) + 
+        " in fragment shader, but as " + uniformTypeName(v) + " in vertex shader")
+      err.AppendContextString("Info: declared in vertex shader here", v.Context())
+      appendError(err)
+    }
+  }
+
+  if result != nil {
+    return result
+  }
+
+  return nil
+}
+
+func uniformTypeName(u *glsl.Uniform) string {
+  if u.Length() > 0 {
+    return u.TypeName() + "[" + strconv.Itoa(u.Length()) + "]"
+  } else {
+    return u.TypeName()
+  }
+}
+
+func sortedVaryingNames(varyings map[string]*glsl.Varying) []string {
+  keys := make([]string, 0)
+  for k, _ := range varyings {
+    keys = append(keys, k)
+  }
+
+  sort.Strings(keys)
+
+  return keys
+}
+
+func sortedUniformNames(uniforms map[string]*glsl.Uniform) []string {
+  keys := make([]string, 0)
+  for k, _ := range uniforms {
+    keys = append(keys, k)
+  }
+
+  sort.Strings(keys)
+
+  return keys
+}
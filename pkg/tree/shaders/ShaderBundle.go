@@ -312,7 +312,37 @@ func (b *ShaderBundle) Finalize() error {
   return nil
 }
 
-func (b *ShaderBundle) CollectVaryings(varyings map[string]string) error {
+// unlike Finalize, no main function is required and the tree is left unaltered
+func (b *ShaderBundle) FinalizeForInterpreter() error {
+  if err := b.ResolveDependencies(); err != nil {
+    return err
+  }
+
+  if err := b.ResolveNames(); err != nil {
+    return err
+  }
+
+  if err := b.EvalTypes(); err != nil {
+    return err
+  }
+
+  return nil
+}
+
+// globals are initialized in dependency order
+func (b *ShaderBundle) NewStack() (*glsl.Stack, error) {
+  stack := glsl.NewStack()
+
+  for _, s := range b.shaders {
+    if err := s.InterpretGlobals(stack); err != nil {
+      return nil, err
+    }
+  }
+
+  return stack, nil
+}
+
+func (b *ShaderBundle) CollectVaryings(varyings map[string]*glsl.Varying) error {
   for _, s := range b.shaders {
     if err := s.CollectVaryings(varyings); err != nil {
       return err
@@ -322,6 +352,16 @@ func (b *ShaderBundle) CollectVaryings(varyings map[string]string) error {
   return nil
 }
 
+func (b *ShaderBundle) CollectUniforms(uniforms map[string]*glsl.Uniform) error {
+  for _, s := range b.shaders {
+    if err := s.CollectUniforms(uniforms); err != nil {
+      return err
+    }
+  }
+
+  return nil
+}
+
 func (b *ShaderBundle) FindExportedConst(name string) *glsl.Const {
   for _, s := range b.shaders {
     if cSt := s.FindExportedConst(name); cSt != nil {
@@ -375,8 +415,44 @@ func (b *ShaderBundle) InjectConsts(rtName string, consts map[string]jsv.Value,
   return nil
 }
 
-// second return value is the map of all the varying types
-func transpileWebGLShader(callerPath string, shaderPath_ *js.Word, rtName string, consts map[string]jsv.Value) (string, map[string]string, error) {
+// paths of all the shader files, only complete after ResolveDependencies
+func (b *ShaderBundle) Paths() []string {
+  res := make([]string, len(b.shaders))
+  for i, s := range b.shaders {
+    res[i] = s.Path()
+  }
+
+  return res
+}
+
+// plain glsl source of an entry shader file, without any const injection (used by the template shader directive)
+// the paths of all the imported shader files (including the entry file) are also returned
+func TranspileShader(shaderPath string, target string) (string, []string, error) {
+  glsl.TARGET = target
+
+  bundle := NewShaderBundle()
+
+  entryShader, err := NewInitShaderFile(shaderPath)
+  if err != nil {
+    return "", nil, err
+  }
+
+  bundle.Append(entryShader)
+
+  if err := bundle.Finalize(); err != nil {
+    return "", nil, err
+  }
+
+  source, err := bundle.Write(patterns.NL, patterns.TAB)
+  if err != nil {
+    return "", nil, err
+  }
+
+  return source, bundle.Paths(), nil
+}
+
+// second return value is the finalized bundle, so varyings and uniforms can be checked against the other stage
+func transpileWebGLShader(callerPath string, shaderPath_ *js.Word, rtName string, consts map[string]jsv.Value) (string, *ShaderBundle, error) {
   errCtx := shaderPath_.Context()
 
   shaderPath, err := files.Search(callerPath, shaderPath_.Value())
@@ -403,12 +479,6 @@ func transpileWebGLShader(callerPath string, shaderPath_ *js.Word, rtName string
     }
   }
 
-  varyings := make(map[string]string)
-
-  if err := bundle.CollectVaryings(varyings); err != nil {
-    return "", nil, err
-  }
-
   shaderSource, err := bundle.Write(patterns.NL, patterns.TAB)
   if err != nil {
     return "", nil, err
@@ -419,50 +489,28 @@ func transpileWebGLShader(callerPath string, shaderPath_ *js.Word, rtName string
   b.WriteString(shaderSource)
   b.WriteString("`")
 
-  return b.String(), varyings, nil
+  return b.String(), bundle, nil
 }
 
 func TranspileWebGLShaders(callerPath string, vertexPath *js.Word, vertexConsts map[string]jsv.Value,
   fragmentPath *js.Word, fragmentConsts map[string]jsv.Value) (string, string, error) {
 
   glsl.TARGET = "vertex"
-  vertexSource, vertexVaryings, err := transpileWebGLShader(callerPath, vertexPath, "v", vertexConsts)
+  vertexSource, vertexBundle, err := transpileWebGLShader(callerPath, vertexPath, "v", vertexConsts)
   if err != nil {
     return "", "", err
   }
 
   glsl.TARGET = "fragment"
-  fragmentSource, fragmentVaryings, err := transpileWebGLShader(callerPath, fragmentPath, "f", fragmentConsts)
+  fragmentSource, fragmentBundle, err := transpileWebGLShader(callerPath, fragmentPath, "f", fragmentConsts)
   if err != nil {
     return "", "", err
   }
 
-  errCtx := context.MergeContexts(vertexPath.Context(), fragmentPath.Context())
-
-  for k, typeName := range vertexVaryings {
-    fragTypeName, ok := fragmentVaryings[k] 
-    if !ok {
-      return "", "", errCtx.NewError("Error: varying " + k + " not found in fragment shader")
-    }
-
-    if fragTypeName != typeName {
-      return "", "", errCtx.NewError("Error: varying " + k + " has different type in in fragment shader")
-    }
-  }
-
-  for k, typeName := range fragmentVaryings {
-    vertexTypeName, ok := vertexVaryings[k] 
-    if !ok {
-      return "", "", errCtx.NewError("Error: varying " + k + " not found in vertex shader")
-    }
-
-    if vertexTypeName != typeName {
-      return "", "", errCtx.NewError("Error: varying " + k + " has different type in in vertex shader")
-    }
-  }
-
-  if len(vertexVaryings) != len(fragmentVaryings) {
-    return "", "", errCtx.NewError("Error: varyings differ")
+  if err := CheckLink(vertexBundle, fragmentBundle); err != nil {
+    errCtx := context.MergeContexts(vertexPath.Context(), fragmentPath.Context())
+    context.AppendContextString(err, "Info: linking shaders here", errCtx)
+    return "", "", err
   }
 
   return vertexSource, fragmentSource, nil
@@ -20,8 +20,11 @@ type ShaderFile interface {
   UniqueEntryPointNames(ns glsl.Namespace) error
   UniqueNames(ns glsl.Namespace) error
   CollectVersion(version *glsl.Word) (*glsl.Word, error)
-  CollectVaryings(varyings map[string]string) error
+  CollectVaryings(varyings map[string]*glsl.Varying) error
+  CollectUniforms(uniforms map[string]*glsl.Uniform) error
   FindExportedConst(name string) *glsl.Const
+  FindExportedFunction(name string) *glsl.Function
+  InterpretGlobals(stack *glsl.Stack) error
 
 	Module() glsl.Module
 	Path() string
@@ -103,10 +106,22 @@ func (s *ShaderFileData) CollectVersion(version *glsl.Word) (*glsl.Word, error)
   return s.module.CollectVersion(version)
 }
 
-func (s *ShaderFileData) CollectVaryings(varyings map[string]string) error {
+func (s *ShaderFileData) CollectVaryings(varyings map[string]*glsl.Varying) error {
   return s.module.CollectVaryings(varyings)
 }
 
+func (s *ShaderFileData) CollectUniforms(uniforms map[string]*glsl.Uniform) error {
+  return s.module.CollectUniforms(uniforms)
+}
+
 func (s *ShaderFileData) FindExportedConst(name string) *glsl.Const {
   return nil
 }
+
+func (s *ShaderFileData) FindExportedFunction(name string) *glsl.Function {
+  return s.module.FindExportedFunction(name)
+}
+
+func (s *ShaderFileData) InterpretGlobals(stack *glsl.Stack) error {
+  return s.module.InterpretGlobals(stack)
+}
@@ -0,0 +1,63 @@
+package shaders
+
+import (
+	"github.com/wtsuite/wtsuite/pkg/tokens/glsl"
+	"github.com/wtsuite/wtsuite/pkg/tokens/js"
+	jsv "github.com/wtsuite/wtsuite/pkg/tokens/js/values"
+)
+
+const (
+  WEBGL_PASS_VARYING = "vUV"
+)
+
+// fullscreen quad, drawn as a triangle strip, with texture coordinates in [0,1]
+const webGLPassVertexSource = "`attribute vec2 aPos;\nvarying vec2 " + WEBGL_PASS_VARYING + ";\nvoid main(){\n" +
+  WEBGL_PASS_VARYING + "=aPos*0.5+0.5;\ngl_Position=vec4(aPos,0.0,1.0);\n}\n`"
+
+// the fragment shader can only use the varying provided by the generated vertex stage
+func checkWebGLPassVaryings(fragment *ShaderBundle) error {
+  varyings := make(map[string]*glsl.Varying)
+  if err := fragment.CollectVaryings(varyings); err != nil {
+    return err
+  }
+
+  for _, k := range sortedVaryingNames(varyings) {
+    v := varyings[k]
+
+    if k != WEBGL_PASS_VARYING {
+      errCtx := v.Context()
+      return errCtx.NewError("Error: varying " + k + " not provided by WebGLPass (hint: use " + WEBGL_PASS_VARYING + ")")
+    } else if v.TypeName() != "vec2" {
+      errCtx := v.Context()
+      return errCtx.NewError("Error: varying " + k + " must be vec2, got " + v.TypeName())
+    }
+  }
+
+  return nil
+}
+
+func TranspileWebGLPassShader(callerPath string, fragmentPath *js.Word,
+  fragmentConsts map[string]jsv.Value) (string, string, map[string]string, error) {
+
+  glsl.TARGET = "fragment"
+  fragmentSource, fragmentBundle, err := transpileWebGLShader(callerPath, fragmentPath, "f", fragmentConsts)
+  if err != nil {
+    return "", "", nil, err
+  }
+
+  if err := checkWebGLPassVaryings(fragmentBundle); err != nil {
+    return "", "", nil, err
+  }
+
+  uniforms := make(map[string]*glsl.Uniform)
+  if err := fragmentBundle.CollectUniforms(uniforms); err != nil {
+    return "", "", nil, err
+  }
+
+  uniformTypes := make(map[string]string)
+  for name, u := range uniforms {
+    uniformTypes[name] = uniformTypeName(u)
+  }
+
+  return webGLPassVertexSource, fragmentSource, uniformTypes, nil
+}
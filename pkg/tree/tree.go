@@ -10,9 +10,30 @@ var (
 
 	AUTO_LINK = false // optimally convert tags containing 'href' attribute to <a>
 
+	CONTENT_MODEL_WARNINGS = false // content model violations are warnings instead of errors
+
 	VERBOSITY = 0
 )
 
+// the second return value is false for missing and null attributes, non-string values are returned as empty strings
+func getStringAttr(t Tag, name string) (string, bool) {
+	attr := t.Attributes()
+	if attr == nil {
+		return "", false
+	}
+
+	v_, ok := attr.Get(name)
+	if !ok || tokens.IsNull(v_) {
+		return "", false
+	}
+
+	if v, err := tokens.AssertString(v_); err == nil {
+		return v.Value(), true
+	}
+
+	return "", true
+}
+
 func buildPermissive(parent Tag, children []*tokens.Tag) error {
   for _, child := range children {
     if child.IsText() {
@@ -7,6 +7,7 @@ import (
 var (
 	_uid_    = 0
 	_uclass_ = 0
+	_uscope_ = 0
 )
 
 func NewUniqueID() string {
@@ -20,3 +21,10 @@ func NewUniqueClass() string {
 	_uclass_++
 	return res
 }
+
+// attribute of the elements emitted by a template with scoped styles
+func NewUniqueScopeAttr() string {
+	res := "data-s" + strconv.Itoa(_uscope_)
+	_uscope_++
+	return res
+}